@@ -0,0 +1,452 @@
+package signup
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/operationspark/service-signup/greenlight"
+	"github.com/operationspark/service-signup/logging"
+	"github.com/operationspark/service-signup/mongodb"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type (
+	// adminStore reads Greenlight signups, sessions, and join codes, and the signup records saved by this service. Implemented by mongodb.MongodbService.
+	adminStore interface {
+		// SearchSignups decodes a page of matching Greenlight signups into dst and returns the total number of matches.
+		SearchSignups(ctx context.Context, s mongodb.SignupSearch, dst any) (int64, error)
+		GetGreenlightSignup(ctx context.Context, id string, dst any) error
+		GetSession(ctx context.Context, id string, dst any) error
+		GetUserJoinCode(ctx context.Context, id string, dst any) error
+		FindSignupRecord(ctx context.Context, greenlightID string, dst any) error
+		// ListSignupRecords decodes a page of the signup records with the given status into dst and returns the total number of records with the status.
+		ListSignupRecords(ctx context.Context, status string, skip, limit int64, dst any) (int64, error)
+	}
+
+	// quarantineReviewer registers or rejects the signups held by the signup guard. Implemented by SignupService.
+	quarantineReviewer interface {
+		release(ctx context.Context, id string, logger *slog.Logger) (Signup, error)
+		reject(ctx context.Context, id string) (Signup, error)
+	}
+
+	// adminServer lets admissions staff look up signups without opening the Greenlight database.
+	adminServer struct {
+		// Key sent as a Bearer token to use the admin API.
+		apiKey string
+		store  adminStore
+		// Optional. Roster exports respond 404 when nil.
+		roster *rosterExporter
+		// Optional. Imports respond 404 when nil.
+		importer *signupImporter
+		// Optional. Releasing and rejecting quarantined signups respond 404 when nil.
+		reviewer quarantineReviewer
+		// Optional. Template previews respond 404 when nil.
+		preview http.HandlerFunc
+		logger  *slog.Logger
+		mux     *http.ServeMux
+	}
+
+	adminServerOptions struct {
+		// Key sent as a Bearer token to use the admin API. Every request is unauthorized when empty.
+		apiKey   string
+		store    adminStore
+		roster   *rosterExporter
+		importer *signupImporter
+		reviewer quarantineReviewer
+		preview  http.HandlerFunc
+		logger   *slog.Logger
+	}
+
+	// adminSignup is a signup in the Greenlight "signups" collection.
+	adminSignup struct {
+		ID          string    `json:"id" bson:"_id"`
+		SessionID   string    `json:"sessionId" bson:"sessionId"`
+		NameFirst   string    `json:"nameFirst" bson:"nameFirst"`
+		NameLast    string    `json:"nameLast" bson:"nameLast"`
+		Cell        string    `json:"cell" bson:"cell"`
+		Email       string    `json:"email" bson:"email"`
+		CreatedAt   time.Time `json:"createdAt" bson:"createdAt"`
+		ZoomJoinURL string    `json:"zoomJoinUrl" bson:"zoomJoinUrl"`
+	}
+
+	adminSignupList struct {
+		// Signups matching the search, across every page.
+		Total   int64         `json:"total"`
+		Page    int           `json:"page"`
+		Limit   int           `json:"limit"`
+		Signups []adminSignup `json:"signups"`
+	}
+
+	adminSession struct {
+		ID           string    `json:"id"`
+		Name         string    `json:"name"`
+		Cohort       string    `json:"cohort"`
+		LocationType string    `json:"locationType"`
+		ProgramID    string    `json:"programId"`
+		Start        time.Time `json:"start"`
+		JoinCode     string    `json:"joinCode"`
+	}
+
+	adminJoinCode struct {
+		ID        string    `json:"id"`
+		ExpiresAt time.Time `json:"expiresAt"`
+		// Set when the person used the code to join the session in Greenlight.
+		UsedAt string `json:"usedAt,omitempty"`
+		UserID string `json:"userId,omitempty"`
+	}
+
+	// adminQuarantinedSignup is a signup the signup guard held for review.
+	adminQuarantinedSignup struct {
+		RecordID  string    `json:"recordId" bson:"_id"`
+		SessionID string    `json:"sessionId" bson:"sessionId"`
+		ProgramID string    `json:"programId" bson:"programId"`
+		NameFirst string    `json:"nameFirst" bson:"nameFirst"`
+		NameLast  string    `json:"nameLast" bson:"nameLast"`
+		Cell      string    `json:"cell" bson:"cell"`
+		Email     string    `json:"email" bson:"email"`
+		Reason    string    `json:"reason" bson:"quarantineReason"`
+		CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+	}
+
+	adminQuarantineList struct {
+		// Quarantined signups, across every page.
+		Total   int64                    `json:"total"`
+		Page    int                      `json:"page"`
+		Limit   int                      `json:"limit"`
+		Signups []adminQuarantinedSignup `json:"signups"`
+	}
+
+	// adminReview is the result of releasing or rejecting a quarantined signup.
+	adminReview struct {
+		RecordID string `json:"recordId"`
+		Status   string `json:"status"`
+		// Set for released signups.
+		GreenlightID string `json:"greenlightId,omitempty"`
+		ShortLink    string `json:"shortLink,omitempty"`
+	}
+
+	// adminSignupDetail is a Greenlight signup with its session, join code, and what happened when it was registered.
+	// Session, JoinCode, and the registration fields are empty when they can't be found. Ex: signups made before signup records were saved.
+	adminSignupDetail struct {
+		Signup   adminSignup    `json:"signup"`
+		Session  *adminSession  `json:"session"`
+		JoinCode *adminJoinCode `json:"joinCode"`
+		// Signup record status. Ex: "active", "canceled".
+		Status         string `json:"status,omitempty"`
+		RecordID       string `json:"recordId,omitempty"`
+		ZoomJoinURL    string `json:"zoomJoinUrl,omitempty"`
+		ShortLink      string `json:"shortLink,omitempty"`
+		ConversationID string `json:"conversationId,omitempty"`
+		// Set when the welcome email hard bounced.
+		EmailInvalid bool          `json:"emailInvalid,omitempty"`
+		Tasks        []taskOutcome `json:"tasks"`
+	}
+)
+
+// Admin search pagination.
+const (
+	defaultAdminPageSize = 25
+	maxAdminPageSize     = 100
+)
+
+func NewAdminServer(o adminServerOptions) *adminServer {
+	s := &adminServer{
+		apiKey:   o.apiKey,
+		store:    o.store,
+		roster:   o.roster,
+		importer: o.importer,
+		reviewer: o.reviewer,
+		preview:  o.preview,
+		logger:   o.logger.With("service", "admin"),
+		mux:      http.NewServeMux(),
+	}
+	s.mux.HandleFunc("GET /admin/signups", s.handleSearch)
+	s.mux.HandleFunc("GET /admin/signups/{id}", s.handleDetail)
+	s.mux.HandleFunc("GET /admin/rosters", s.handleRoster)
+	s.mux.HandleFunc("POST /admin/imports", s.handleImport)
+	s.mux.HandleFunc("GET /admin/quarantine", s.handleQuarantine)
+	s.mux.HandleFunc("POST /admin/quarantine/{id}/release", s.handleRelease)
+	s.mux.HandleFunc("POST /admin/quarantine/{id}/reject", s.handleReject)
+	s.mux.HandleFunc("POST /admin/templates/preview", s.handlePreview)
+	return s
+}
+
+// ServeHTTP serves the admin API. Requests must send the API key as a Bearer token.
+//
+//	GET /admin/signups?q=henri&sessionId=abc123&from=2024-01-01&to=2024-01-31&page=2&limit=25
+//	GET /admin/signups/{greenlightSignupID}
+//	GET /admin/rosters?sessionId=abc123&format=csv
+//	GET /admin/rosters?from=2024-01-01&to=2024-01-31&format=json
+//	POST /admin/imports?skip=slack&dryRun=true (CSV body)
+//	GET /admin/quarantine?page=2&limit=25
+//	POST /admin/quarantine/{recordID}/release
+//	POST /admin/quarantine/{recordID}/reject
+//	POST /admin/templates/preview (JSON body)
+func (s *adminServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if s.apiKey == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.apiKey)) != 1 {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// HandlePreview renders an SMS template with sample data. Template overrides and their data are internal, so previews need the admin key.
+func (s *adminServer) handlePreview(w http.ResponseWriter, r *http.Request) {
+	if s.preview == nil {
+		http.NotFound(w, r)
+		return
+	}
+	s.preview(w, r)
+}
+
+// HandleSearch lists Greenlight signups, newest first.
+// "q" matches names and emails (and phone numbers, ignoring formatting). "from" and "to" are inclusive dates (YYYY-MM-DD) in Central Time.
+func (s *adminServer) handleSearch(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	search, page, limit, err := parseAdminSearch(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	list := adminSignupList{Page: page, Limit: limit, Signups: []adminSignup{}}
+	list.Total, err = s.store.SearchSignups(r.Context(), search, &list.Signups)
+	if err != nil {
+		s.serverError(w, r, fmt.Errorf("searchSignups: %w", err))
+		return
+	}
+	s.writeJSON(w, r, list)
+}
+
+// HandleDetail shows one Greenlight signup with its session, join code, and the outcome of each registration task.
+func (s *adminServer) handleDetail(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := r.PathValue("id")
+
+	detail := adminSignupDetail{Tasks: []taskOutcome{}}
+	err := s.store.GetGreenlightSignup(ctx, id, &detail.Signup)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		s.serverError(w, r, fmt.Errorf("getGreenlightSignup: %w", err))
+		return
+	}
+
+	var record signupRecord
+	if err := optional(s.store.FindSignupRecord(ctx, id, &record)); err != nil {
+		s.serverError(w, r, fmt.Errorf("findSignupRecord: %w", err))
+		return
+	}
+	if record.ID != "" {
+		detail.Status = string(record.Status)
+		detail.RecordID = record.ID
+		detail.ZoomJoinURL = record.ZoomJoinURL
+		detail.ShortLink = record.ShortLink
+		detail.ConversationID = record.ConversationID
+		detail.EmailInvalid = record.EmailInvalid
+		if record.Tasks != nil {
+			detail.Tasks = record.Tasks
+		}
+	}
+
+	if detail.Signup.SessionID != "" {
+		var session greenlight.Session
+		if err := optional(s.store.GetSession(ctx, detail.Signup.SessionID, &session)); err != nil {
+			s.serverError(w, r, fmt.Errorf("getSession: %w", err))
+			return
+		}
+		if session.ID != "" {
+			detail.Session = &adminSession{
+				ID:           session.ID,
+				Name:         session.Name,
+				Cohort:       session.Cohort,
+				LocationType: session.LocationType,
+				ProgramID:    session.ProgramID,
+				Start:        session.Times.Start.DateTime,
+				JoinCode:     session.JoinCode,
+			}
+		}
+	}
+
+	if record.UserJoinCode != "" {
+		var code greenlight.UserJoinCode
+		err := optional(s.store.GetUserJoinCode(ctx, record.UserJoinCode, &code))
+		if err != nil {
+			s.serverError(w, r, fmt.Errorf("getUserJoinCode: %w", err))
+			return
+		}
+		if code.SessionID != "" {
+			detail.JoinCode = &adminJoinCode{
+				ID:        record.UserJoinCode,
+				ExpiresAt: code.ExpiresAt,
+				UsedAt:    code.UsedAt,
+				UserID:    code.UserID,
+			}
+		}
+	}
+
+	s.writeJSON(w, r, detail)
+}
+
+// HandleQuarantine lists the signups held by the signup guard, oldest first.
+func (s *adminServer) handleQuarantine(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	page, err := positiveParam(q, "page", 1)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, err := positiveParam(q, "limit", defaultAdminPageSize)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit = min(limit, maxAdminPageSize)
+
+	list := adminQuarantineList{Page: page, Limit: limit, Signups: []adminQuarantinedSignup{}}
+	skip := int64((page - 1) * limit)
+	list.Total, err = s.store.ListSignupRecords(r.Context(), string(signupStatusQuarantined), skip, int64(limit), &list.Signups)
+	if err != nil {
+		s.serverError(w, r, fmt.Errorf("listSignupRecords: %w", err))
+		return
+	}
+	s.writeJSON(w, r, list)
+}
+
+// HandleRelease registers a quarantined signup as if it had passed the signup guard.
+func (s *adminServer) handleRelease(w http.ResponseWriter, r *http.Request) {
+	if s.reviewer == nil {
+		http.NotFound(w, r)
+		return
+	}
+	id := r.PathValue("id")
+	su, err := s.reviewer.release(r.Context(), id, s.logger)
+	if s.reviewError(w, r, err) {
+		return
+	}
+	review := adminReview{RecordID: id, Status: string(signupStatusReleased), ShortLink: su.ShortLink}
+	if su.id != nil {
+		review.GreenlightID = *su.id
+	}
+	s.writeJSON(w, r, review)
+}
+
+// HandleReject marks a quarantined signup as spam.
+func (s *adminServer) handleReject(w http.ResponseWriter, r *http.Request) {
+	if s.reviewer == nil {
+		http.NotFound(w, r)
+		return
+	}
+	id := r.PathValue("id")
+	_, err := s.reviewer.reject(r.Context(), id)
+	if s.reviewError(w, r, err) {
+		return
+	}
+	s.writeJSON(w, r, adminReview{RecordID: id, Status: string(signupStatusSpam)})
+}
+
+// ReviewError writes the response for a failed release or rejection and reports whether there was an error.
+func (s *adminServer) reviewError(w http.ResponseWriter, r *http.Request, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, mongo.ErrNoDocuments):
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+	case errors.Is(err, errNotQuarantined):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		s.serverError(w, r, err)
+	}
+	return true
+}
+
+// ParseAdminSearch parses the search query parameters and returns the search with the page number and size.
+func parseAdminSearch(q url.Values) (mongodb.SignupSearch, int, int, error) {
+	search := mongodb.SignupSearch{
+		Text:      q.Get("q"),
+		SessionID: q.Get("sessionId"),
+	}
+
+	tz, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		return search, 0, 0, fmt.Errorf("loadLocation: %w", err)
+	}
+	const layout = "2006-01-02"
+	if raw := q.Get("from"); raw != "" {
+		if search.From, err = time.ParseInLocation(layout, raw, tz); err != nil {
+			return search, 0, 0, fmt.Errorf("invalid 'from' date: %q", raw)
+		}
+	}
+	if raw := q.Get("to"); raw != "" {
+		to, err := time.ParseInLocation(layout, raw, tz)
+		if err != nil {
+			return search, 0, 0, fmt.Errorf("invalid 'to' date: %q", raw)
+		}
+		search.To = to.AddDate(0, 0, 1)
+	}
+	if !search.From.IsZero() && !search.To.IsZero() && !search.From.Before(search.To) {
+		return search, 0, 0, errors.New("'from' must be on or before 'to'")
+	}
+
+	page, err := positiveParam(q, "page", 1)
+	if err != nil {
+		return search, 0, 0, err
+	}
+	limit, err := positiveParam(q, "limit", defaultAdminPageSize)
+	if err != nil {
+		return search, 0, 0, err
+	}
+	limit = min(limit, maxAdminPageSize)
+
+	search.Skip = int64((page - 1) * limit)
+	search.Limit = int64(limit)
+	return search, page, limit, nil
+}
+
+func positiveParam(q url.Values, key string, def int) (int, error) {
+	raw := q.Get(key)
+	if raw == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid '%s': %q", key, raw)
+	}
+	return n, nil
+}
+
+// Optional ignores "not found" errors for documents the detail view can do without.
+func optional(err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	return err
+}
+
+func (s *adminServer) writeJSON(w http.ResponseWriter, r *http.Request, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		s.logError(r.Context(), fmt.Errorf("encode: %w", err))
+	}
+}
+
+func (s *adminServer) serverError(w http.ResponseWriter, r *http.Request, err error) {
+	s.logError(r.Context(), err)
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
+func (s *adminServer) logError(ctx context.Context, err error) {
+	logging.Error(ctx, s.logger, err)
+}
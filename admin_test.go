@@ -0,0 +1,247 @@
+package signup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/operationspark/service-signup/greenlight"
+	"github.com/operationspark/service-signup/mongodb"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mockAdminStore struct {
+	search    mongodb.SignupSearch
+	signups   map[string]adminSignup
+	sessions  map[string]greenlight.Session
+	locations map[string]greenlight.Location
+	codes     map[string]greenlight.UserJoinCode
+	records   map[string]signupRecord
+}
+
+func (m *mockAdminStore) SearchSignups(ctx context.Context, s mongodb.SignupSearch, dst any) (int64, error) {
+	m.search = s
+	list := dst.(*[]adminSignup)
+	for _, su := range m.signups {
+		*list = append(*list, su)
+	}
+	return int64(len(m.signups)), nil
+}
+
+func (m *mockAdminStore) GetGreenlightSignup(ctx context.Context, id string, dst any) error {
+	return mockFind(m.signups, id, dst.(*adminSignup))
+}
+
+func (m *mockAdminStore) GetSession(ctx context.Context, id string, dst any) error {
+	return mockFind(m.sessions, id, dst.(*greenlight.Session))
+}
+
+func (m *mockAdminStore) GetLocation(ctx context.Context, id string, dst any) error {
+	return mockFind(m.locations, id, dst.(*greenlight.Location))
+}
+
+func (m *mockAdminStore) GetUserJoinCode(ctx context.Context, id string, dst any) error {
+	return mockFind(m.codes, id, dst.(*greenlight.UserJoinCode))
+}
+
+func (m *mockAdminStore) FindSignupRecord(ctx context.Context, greenlightID string, dst any) error {
+	return mockFind(m.records, greenlightID, dst.(*signupRecord))
+}
+
+func (m *mockAdminStore) ListSignupRecords(ctx context.Context, status string, skip, limit int64, dst any) (int64, error) {
+	list := dst.(*[]adminQuarantinedSignup)
+	for id, r := range m.records {
+		if string(r.Status) == status {
+			*list = append(*list, adminQuarantinedSignup{RecordID: id, Email: r.Email, Reason: r.QuarantineReason})
+		}
+	}
+	return int64(len(*list)), nil
+}
+
+func mockFind[T any](docs map[string]T, id string, dst *T) error {
+	doc, ok := docs[id]
+	if !ok {
+		return fmt.Errorf("findOne: %w", mongo.ErrNoDocuments)
+	}
+	*dst = doc
+	return nil
+}
+
+func TestAdminServer(t *testing.T) {
+	start := mustMakeTime(t, time.RFC822, "16 Nov 22 18:00 UTC")
+	session := greenlight.Session{ID: "session-1", Cohort: "is-nov-16-22-12pm", LocationType: "HYBRID", JoinCode: "ABC123"}
+	session.Times.Start.DateTime = start
+
+	store := &mockAdminStore{
+		signups: map[string]adminSignup{
+			"gl-1": {ID: "gl-1", SessionID: "session-1", NameFirst: "Henri", NameLast: "Testaroni", Cell: "555-123-4567", Email: "henri@email.com"},
+			"gl-2": {ID: "gl-2", NameFirst: "Halle", NameLast: "Bot"},
+		},
+		sessions: map[string]greenlight.Session{"session-1": session},
+		codes: map[string]greenlight.UserJoinCode{
+			"65a000000000000000000001": {SessionID: "session-1", ExpiresAt: start.Add(8 * time.Hour)},
+		},
+		records: map[string]signupRecord{
+			"gl-1": {
+				ID:             "record-1",
+				Status:         signupStatusActive,
+				UserJoinCode:   "65a000000000000000000001",
+				ZoomJoinURL:    "https://us06web.zoom.us/w/123?tk=abc",
+				ShortLink:      "https://ospk.org/abc1234567",
+				ConversationID: "CH123",
+				Tasks: []taskOutcome{
+					{Task: taskZoom, Status: taskSucceeded},
+					{Task: taskSMS, Status: taskFailed, Error: "invalid number"},
+				},
+			},
+		},
+	}
+	s := NewAdminServer(adminServerOptions{apiKey: "admin-key", store: store, logger: slog.Default()})
+
+	get := func(t *testing.T, target, key string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Authorization", "Bearer "+key)
+		res := httptest.NewRecorder()
+		s.ServeHTTP(res, req)
+		return res
+	}
+
+	t.Run("searches signups", func(t *testing.T) {
+		res := get(t, "/admin/signups?q=henri&sessionId=session-1&from=2022-11-01&to=2022-11-30&page=3&limit=10", "admin-key")
+
+		require.Equal(t, http.StatusOK, res.Code)
+		var got adminSignupList
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+		require.Equal(t, int64(2), got.Total)
+		require.Equal(t, 3, got.Page)
+		require.Len(t, got.Signups, 2)
+
+		require.Equal(t, "henri", store.search.Text)
+		require.Equal(t, "session-1", store.search.SessionID)
+		require.Equal(t, "2022-11-01", store.search.From.Format("2006-01-02"))
+		require.Equal(t, "2022-12-01", store.search.To.Format("2006-01-02"))
+		require.Equal(t, int64(20), store.search.Skip)
+		require.Equal(t, int64(10), store.search.Limit)
+	})
+
+	t.Run("rejects invalid search parameters", func(t *testing.T) {
+		for _, query := range []string{"from=yesterday", "page=0", "limit=ten", "from=2022-12-01&to=2022-11-01"} {
+			res := get(t, "/admin/signups?"+query, "admin-key")
+			require.Equal(t, http.StatusBadRequest, res.Code, query)
+		}
+	})
+
+	t.Run("shows the signup with its session, join code, and task outcomes", func(t *testing.T) {
+		res := get(t, "/admin/signups/gl-1", "admin-key")
+
+		require.Equal(t, http.StatusOK, res.Code)
+		var got adminSignupDetail
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+		require.Equal(t, "Henri", got.Signup.NameFirst)
+		require.Equal(t, "ABC123", got.Session.JoinCode)
+		require.True(t, got.Session.Start.Equal(start))
+		require.True(t, got.JoinCode.ExpiresAt.Equal(start.Add(8*time.Hour)))
+		require.Equal(t, "active", got.Status)
+		require.Equal(t, "https://us06web.zoom.us/w/123?tk=abc", got.ZoomJoinURL)
+		require.Equal(t, "https://ospk.org/abc1234567", got.ShortLink)
+		require.Equal(t, "CH123", got.ConversationID)
+		require.Equal(t, []taskOutcome{
+			{Task: taskZoom, Status: taskSucceeded},
+			{Task: taskSMS, Status: taskFailed, Error: "invalid number"},
+		}, got.Tasks)
+	})
+
+	t.Run("shows signups without a record", func(t *testing.T) {
+		res := get(t, "/admin/signups/gl-2", "admin-key")
+
+		require.Equal(t, http.StatusOK, res.Code)
+		var got adminSignupDetail
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+		require.Equal(t, "Halle", got.Signup.NameFirst)
+		require.Nil(t, got.Session)
+		require.Nil(t, got.JoinCode)
+		require.Empty(t, got.Tasks)
+	})
+
+	t.Run("responds 404 for unknown signups", func(t *testing.T) {
+		res := get(t, "/admin/signups/missing", "admin-key")
+		require.Equal(t, http.StatusNotFound, res.Code)
+	})
+
+	t.Run("lists quarantined signups", func(t *testing.T) {
+		store.records["record-2"] = signupRecord{ID: "record-2", Status: signupStatusQuarantined, Email: "halle@yopmail.com", QuarantineReason: "disposable email domain"}
+		defer delete(store.records, "record-2")
+
+		res := get(t, "/admin/quarantine", "admin-key")
+
+		require.Equal(t, http.StatusOK, res.Code)
+		var got adminQuarantineList
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+		require.Equal(t, int64(1), got.Total)
+		require.Equal(t, "record-2", got.Signups[0].RecordID)
+		require.Equal(t, "disposable email domain", got.Signups[0].Reason)
+	})
+
+	t.Run("releases and rejects quarantined signups", func(t *testing.T) {
+		records := &mockSignupStore{records: map[string]signupRecord{
+			"held-1": {ID: "held-1", Status: signupStatusQuarantined, NameFirst: "Halle", Email: "halle@yopmail.com"},
+			"held-2": {ID: "held-2", Status: signupStatusQuarantined, NameFirst: "Bot", Email: "bot@yopmail.com"},
+		}}
+		var registered []string
+		svc, err := newSignupService(signupServiceOptions{
+			tasks: []mutationTask{&MockMailgunService{
+				WelcomeFunc: func(ctx context.Context, su Signup) error {
+					registered = append(registered, su.Email)
+					return nil
+				},
+			}},
+			zoomService: &MockZoomService{},
+			programs:    infoSessionPrograms(nil),
+			gldbService: &MockGreenlightDBService{},
+			store:       records,
+		})
+		require.NoError(t, err)
+		s := NewAdminServer(adminServerOptions{apiKey: "admin-key", store: store, reviewer: svc, logger: slog.Default()})
+
+		post := func(target string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPost, target, nil)
+			req.Header.Set("Authorization", "Bearer admin-key")
+			res := httptest.NewRecorder()
+			s.ServeHTTP(res, req)
+			return res
+		}
+
+		res := post("/admin/quarantine/held-1/release")
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+		require.Equal(t, []string{"halle@yopmail.com"}, registered)
+		require.Equal(t, signupStatusReleased, records.records["held-1"].Status)
+
+		res = post("/admin/quarantine/held-1/release")
+		require.Equal(t, http.StatusConflict, res.Code, "released signups can't be released again")
+		require.Len(t, registered, 1)
+
+		res = post("/admin/quarantine/held-2/reject")
+		require.Equal(t, http.StatusOK, res.Code)
+		require.Equal(t, signupStatusSpam, records.records["held-2"].Status)
+		require.Len(t, registered, 1)
+	})
+
+	t.Run("requires the API key", func(t *testing.T) {
+		res := get(t, "/admin/signups", "wrong-key")
+		require.Equal(t, http.StatusUnauthorized, res.Code)
+
+		disabled := NewAdminServer(adminServerOptions{store: store, logger: slog.Default()})
+		req := httptest.NewRequest(http.MethodGet, "/admin/signups", nil)
+		req.Header.Set("Authorization", "Bearer ")
+		rec := httptest.NewRecorder()
+		disabled.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
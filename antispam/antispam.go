@@ -0,0 +1,114 @@
+// Package antispam provides checks for keeping bots and spam out of the signup form: CAPTCHA verification, rate limits, and disposable email detection.
+package antispam
+
+import (
+	"bufio"
+	_ "embed"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimiter allows a number of events per key (Ex: IP address or email) in a rolling window.
+// Counts are kept in memory, so each server instance limits separately.
+type RateLimiter struct {
+	mu        sync.Mutex
+	limit     rate.Limit
+	burst     int
+	window    time.Duration
+	limiters  map[string]*keyLimiter
+	lastSweep time.Time
+}
+
+type keyLimiter struct {
+	lim      *rate.Limiter
+	lastSeen time.Time
+}
+
+//go:embed disposable_domains.txt
+var disposableDomainsList string
+
+var disposableDomains = parseDomains(disposableDomainsList)
+
+// NewRateLimiter creates a RateLimiter that allows n events per key every window.
+// A RateLimiter with n < 1 allows everything.
+func NewRateLimiter(n int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:     rate.Every(window / time.Duration(max(n, 1))),
+		burst:     n,
+		window:    window,
+		limiters:  map[string]*keyLimiter{},
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow reports whether another event for the key is allowed now. A nil RateLimiter allows everything.
+func (l *RateLimiter) Allow(key string) bool {
+	if l == nil || l.burst < 1 {
+		return true
+	}
+	return l.allowAt(key, time.Now())
+}
+
+func (l *RateLimiter) allowAt(key string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	kl, ok := l.limiters[key]
+	if !ok {
+		kl = &keyLimiter{lim: rate.NewLimiter(l.limit, l.burst)}
+		l.limiters[key] = kl
+	}
+	kl.lastSeen = now
+	return kl.lim.AllowN(now, 1)
+}
+
+// Sweep forgets keys not seen for a full window. Their token buckets have refilled, so they would start over anyway.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.window {
+		return
+	}
+	for key, kl := range l.limiters {
+		if now.Sub(kl.lastSeen) >= l.window {
+			delete(l.limiters, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// IsDisposableEmail checks if the email address uses a known disposable (throwaway) email domain, or a subdomain of one.
+func IsDisposableEmail(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(strings.TrimSpace(email[at+1:]))
+	for domain != "" {
+		if disposableDomains[domain] {
+			return true
+		}
+		dot := strings.Index(domain, ".")
+		if dot < 0 {
+			break
+		}
+		domain = domain[dot+1:]
+	}
+	return false
+}
+
+func parseDomains(list string) map[string]bool {
+	domains := map[string]bool{}
+	s := bufio.NewScanner(strings.NewReader(list))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		domains[strings.ToLower(line)] = true
+	}
+	return domains
+}
@@ -0,0 +1,62 @@
+package antispam
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	t.Run("limits each key separately", func(t *testing.T) {
+		l := NewRateLimiter(2, time.Hour)
+		now := time.Now()
+
+		require.True(t, l.allowAt("1.2.3.4", now))
+		require.True(t, l.allowAt("1.2.3.4", now))
+		require.False(t, l.allowAt("1.2.3.4", now))
+		require.True(t, l.allowAt("5.6.7.8", now))
+
+		// One event is allowed again after half the window.
+		require.True(t, l.allowAt("1.2.3.4", now.Add(31*time.Minute)))
+		require.False(t, l.allowAt("1.2.3.4", now.Add(31*time.Minute)))
+	})
+
+	t.Run("forgets idle keys", func(t *testing.T) {
+		l := NewRateLimiter(1, time.Minute)
+		now := time.Now()
+
+		require.True(t, l.allowAt("a", now))
+		require.True(t, l.allowAt("b", now.Add(2*time.Minute)))
+		require.Len(t, l.limiters, 1)
+	})
+
+	t.Run("disabled limiters allow everything", func(t *testing.T) {
+		var nilLimiter *RateLimiter
+		require.True(t, nilLimiter.Allow("a"))
+
+		l := NewRateLimiter(0, time.Hour)
+		for i := 0; i < 10; i++ {
+			require.True(t, l.Allow("a"))
+		}
+	})
+}
+
+func TestIsDisposableEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  bool
+	}{
+		{"henri@mailinator.com", true},
+		{"henri@YopMail.com", true},
+		{"henri@inbox.guerrillamail.com", true},
+		{"henri@gmail.com", false},
+		{"henri@notmailinator.com", false},
+		{"not-an-email", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			require.Equal(t, tt.want, IsDisposableEmail(tt.email))
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package antispam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSiteVerifier(t *testing.T) {
+	newServer := func(t *testing.T, resp map[string]any) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, r.ParseForm())
+			require.Equal(t, "captcha-secret", r.PostForm.Get("secret"))
+			require.Equal(t, "user-token", r.PostForm.Get("response"))
+			require.Equal(t, "1.2.3.4", r.PostForm.Get("remoteip"))
+			_ = json.NewEncoder(w).Encode(resp)
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+
+	t.Run("passes valid tokens", func(t *testing.T) {
+		srv := newServer(t, map[string]any{"success": true})
+		v := NewTurnstile("captcha-secret").WithVerifyURL(srv.URL)
+
+		require.NoError(t, v.Verify(context.Background(), "user-token", "1.2.3.4"))
+	})
+
+	t.Run("fails invalid tokens", func(t *testing.T) {
+		srv := newServer(t, map[string]any{"success": false, "error-codes": []string{"timeout-or-duplicate"}})
+		v := NewTurnstile("captcha-secret").WithVerifyURL(srv.URL)
+
+		err := v.Verify(context.Background(), "user-token", "1.2.3.4")
+		require.ErrorIs(t, err, ErrInvalidToken)
+		require.ErrorContains(t, err, "timeout-or-duplicate")
+	})
+
+	t.Run("fails low reCAPTCHA scores", func(t *testing.T) {
+		srv := newServer(t, map[string]any{"success": true, "score": 0.2})
+		v := NewRecaptcha("captcha-secret", 0.5).WithVerifyURL(srv.URL)
+
+		require.ErrorIs(t, v.Verify(context.Background(), "user-token", "1.2.3.4"), ErrInvalidToken)
+	})
+
+	t.Run("fails missing tokens without calling the API", func(t *testing.T) {
+		v := NewTurnstile("captcha-secret").WithVerifyURL("http://localhost:0")
+
+		require.ErrorIs(t, v.Verify(context.Background(), "", "1.2.3.4"), ErrInvalidToken)
+	})
+}
+
+func TestFake(t *testing.T) {
+	require.NoError(t, Fake{}.Verify(context.Background(), "any-token", ""))
+	require.ErrorIs(t, Fake{}.Verify(context.Background(), FakeInvalidToken, ""), ErrInvalidToken)
+	require.ErrorIs(t, Fake{}.Verify(context.Background(), "", ""), ErrInvalidToken)
+}
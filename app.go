@@ -0,0 +1,152 @@
+package signup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	sentryhttp "github.com/getsentry/sentry-go/http"
+	"github.com/operationspark/service-signup/email"
+	"github.com/operationspark/service-signup/metrics"
+	"github.com/operationspark/service-signup/sms"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// App is the signup service and the connections it holds open. Every server shares one MongoDB client.
+// The standalone server (cmd/server) drains and closes the app on shutdown.
+type App struct {
+	handler         http.Handler
+	health          *healthServer
+	slackActions    *slackActionServer
+	mongoClient     *mongo.Client
+	shutdownTracing func(context.Context) error
+	logger          *slog.Logger
+}
+
+// NewApp connects to MongoDB and creates every server configured from cfg.
+func NewApp(cfg Config) (*App, error) {
+	err := sentry.Init(sentry.ClientOptions{
+		Dsn:              cfg.Sentry.DSN,
+		EnableTracing:    true,
+		TracesSampleRate: cfg.Sentry.SampleRate,
+		Environment:      cfg.Sentry.Env,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sentry init: %w", err)
+	}
+
+	shutdownTracing, err := setupTracing(context.Background(), cfg.Tracing.Exporter)
+	if err != nil {
+		return nil, fmt.Errorf("setupTracing: %w", err)
+	}
+	// Count integration responses for the /metrics endpoint.
+	if _, ok := http.DefaultTransport.(metrics.Transport); !ok {
+		http.DefaultTransport = metrics.Transport{Base: http.DefaultTransport}
+	}
+
+	// Logs written with a request context include the request ID and trace IDs.
+	logger := slog.New(contextLogHandler{slog.NewJSONHandler(os.Stderr, nil)})
+	logger = logger.With("git_hash", getGitRev())
+
+	// Tests (CI) build the server without a database.
+	mongoClient, dbName, err := getMongoClient(cfg)
+	if err != nil {
+		if !cfg.CI {
+			return nil, fmt.Errorf("could not connect to MongoDB: %w", err)
+		}
+		mongoClient = nil
+	}
+
+	// Every server sends SMS messages from the same number(s), so they share a rate limiter.
+	smsLimiter := sms.NewLimiter(cfg.SMS.MessagesPerSecond, 1)
+	smsTemplates := newTemplateRegistry(cfg, mongoClient, dbName)
+
+	emailSender := newEmailSender(cfg)
+
+	signupServer, err := NewSignupServer(cfg, logger, mongoClient, dbName, smsLimiter, smsTemplates, emailSender)
+	if err != nil {
+		if mongoClient != nil {
+			_ = mongoClient.Disconnect(context.Background())
+		}
+		return nil, errors.Join(err, shutdownTracing(context.Background()))
+	}
+	health := NewHealthServer(cfg, mongoClient, emailSender)
+
+	mux := http.NewServeMux()
+	sentryHandler := sentryhttp.New(sentryhttp.Options{})
+	mux.HandleFunc("/", sentryHandler.HandleFunc(signupServer.HandleSignUp))
+	mux.HandleFunc("/notify", sentryHandler.HandleFunc(NewNotifyServer(cfg, logger, mongoClient, dbName, smsLimiter, smsTemplates).ServeHTTP))
+	mux.HandleFunc("/webhooks/mailgun", sentryHandler.HandleFunc(NewEmailWebhookServer(cfg, logger, mongoClient, dbName, smsLimiter, smsTemplates).ServeHTTP))
+	mux.HandleFunc("/healthz", health.HandleLiveness)
+	mux.HandleFunc("/readyz", health.HandleReadiness)
+	// Metrics reveal signup volumes, so they are only public in local development.
+	if cfg.MetricsToken != "" || cfg.Dev {
+		mux.Handle("/metrics", metrics.Handler(cfg.MetricsToken))
+	}
+	mux.HandleFunc("/reports/campaigns", sentryHandler.HandleFunc(NewReportServer(cfg, logger, mongoClient, dbName).ServeHTTP))
+	mux.HandleFunc("/admin/", sentryHandler.HandleFunc(NewAdminServerFromConfig(cfg, logger, mongoClient, dbName, signupServer.service, signupServer.HandlePreview).ServeHTTP))
+	var slackActions *slackActionServer
+	if actioner, ok := signupServer.service.(signupActioner); ok {
+		slackActions = NewSlackActionServer(cfg.Slack.SigningSecret, actioner, logger)
+		mux.HandleFunc("/slack/actions", sentryHandler.HandleFunc(slackActions.ServeHTTP))
+	}
+	if capture, ok := emailSender.(*email.CaptureSender); ok {
+		mux.Handle("/dev/emails", capture)
+	}
+
+	return &App{
+		handler: withTracing(newCORS(corsOptions{
+			allowedOrigins:   cfg.CORS.AllowedOrigins,
+			allowedMethods:   cfg.CORS.AllowedMethods,
+			allowedHeaders:   cfg.CORS.AllowedHeaders,
+			allowCredentials: cfg.CORS.AllowCredentials,
+			maxAge:           time.Duration(cfg.CORS.MaxAge) * time.Second,
+		}, mux)),
+		health:          health,
+		slackActions:    slackActions,
+		mongoClient:     mongoClient,
+		shutdownTracing: shutdownTracing,
+		logger:          logger,
+	}, nil
+}
+
+func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.handler.ServeHTTP(w, r)
+}
+
+// Drain makes /readyz fail so load balancers stop sending new requests while in-flight requests finish.
+func (a *App) Drain() {
+	a.health.drain()
+}
+
+// Close waits for Slack actions still running, disconnects from MongoDB, and flushes buffered spans and Sentry events. Call it after the HTTP server has stopped so no request is using the connections.
+func (a *App) Close(ctx context.Context) error {
+	var errs []error
+	if a.slackActions != nil {
+		if err := a.slackActions.wait(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("slack actions: %w", err))
+		}
+	}
+	if a.mongoClient != nil {
+		if err := a.mongoClient.Disconnect(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("mongo disconnect: %w", err))
+		}
+	}
+	if err := a.shutdownTracing(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("shutdownTracing: %w", err))
+	}
+
+	timeout := 2 * time.Second
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	if !sentry.Flush(timeout) {
+		errs = append(errs, errors.New("sentry flush: timed out"))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,48 @@
+package signup
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApp(t *testing.T) {
+	t.Setenv("CI", "true")
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+
+	app, err := NewApp(cfg)
+	require.NoError(t, err)
+
+	res := httptest.NewRecorder()
+	app.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	require.Equal(t, http.StatusOK, res.Code)
+
+	t.Run("does not serve metrics without a token", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		app.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		require.NotContains(t, res.Body.String(), "go_goroutines")
+	})
+
+	t.Run("fails readiness checks while draining", func(t *testing.T) {
+		app.Drain()
+
+		res := httptest.NewRecorder()
+		app.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		require.Equal(t, http.StatusServiceUnavailable, res.Code)
+		require.JSONEq(t, `{"status": "draining"}`, res.Body.String())
+
+		res = httptest.NewRecorder()
+		app.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		require.Equal(t, http.StatusOK, res.Code, "the process is still alive")
+	})
+
+	t.Run("closes without a database", func(t *testing.T) {
+		require.NoError(t, app.Close(context.Background()))
+	})
+}
@@ -0,0 +1,164 @@
+package signup
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/operationspark/service-signup/logging"
+)
+
+type (
+	// campaignStats is the number of signups, and how many of those attended, for one UTM source/medium/campaign.
+	campaignStats struct {
+		Source   string `json:"source" bson:"source"`
+		Medium   string `json:"medium" bson:"medium"`
+		Campaign string `json:"campaign" bson:"campaign"`
+		Signups  int    `json:"signups" bson:"signups"`
+		// Signups whose Greenlight join code was used to join the session.
+		Attended int `json:"attended" bson:"attended"`
+		// Attended / Signups.
+		ConversionRate float64 `json:"conversionRate" bson:"-"`
+	}
+
+	// campaignReporter aggregates saved signup records by campaign. Implemented by mongodb.MongodbService.
+	campaignReporter interface {
+		// CampaignReport decodes the campaign stats for signups created in [from, to) into dst.
+		CampaignReport(ctx context.Context, from, to time.Time, dst any) error
+	}
+
+	// campaignReportServer serves signup conversion counts by UTM campaign for marketing.
+	campaignReportServer struct {
+		// Key sent as a Bearer token to read reports.
+		apiKey string
+		store  campaignReporter
+		logger *slog.Logger
+	}
+
+	campaignReport struct {
+		From      time.Time       `json:"from"`
+		To        time.Time       `json:"to"`
+		Campaigns []campaignStats `json:"campaigns"`
+	}
+)
+
+// Reports cover this many days when the "from" date is not set.
+const defaultReportDays = 30
+
+// ParseLandingPageUTM fills any UTM fields not sent with the signup from the landing page URL's query parameters.
+func (su *Signup) parseLandingPageUTM() {
+	if su.LandingPage == "" {
+		return
+	}
+	u, err := url.Parse(su.LandingPage)
+	if err != nil {
+		return
+	}
+	q := u.Query()
+	for param, field := range map[string]*string{
+		"utm_source":   &su.UTMSource,
+		"utm_medium":   &su.UTMMedium,
+		"utm_campaign": &su.UTMCampaign,
+		"utm_term":     &su.UTMTerm,
+		"utm_content":  &su.UTMContent,
+	} {
+		if *field == "" {
+			*field = q.Get(param)
+		}
+	}
+}
+
+func NewCampaignReportServer(apiKey string, store campaignReporter, logger *slog.Logger) *campaignReportServer {
+	return &campaignReportServer{
+		apiKey: apiKey,
+		store:  store,
+		logger: logger.With("service", "campaign-report"),
+	}
+}
+
+// ServeHTTP responds with signup and attendance counts by campaign.
+// The optional "from" and "to" query parameters are inclusive dates (YYYY-MM-DD) in Central Time. Defaults to the last 30 days.
+//
+//	GET /reports/campaigns?from=2024-01-01&to=2024-01-31
+func (s *campaignReportServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if s.apiKey == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.apiKey)) != 1 {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	tz, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		s.logError(r.Context(), fmt.Errorf("loadLocation: %w", err))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	from, to, err := reportPeriod(r.URL.Query(), time.Now().In(tz))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report := campaignReport{From: from, To: to, Campaigns: []campaignStats{}}
+	if err := s.store.CampaignReport(r.Context(), from, to, &report.Campaigns); err != nil {
+		s.logError(r.Context(), fmt.Errorf("campaignReport: %w", err))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	for i, c := range report.Campaigns {
+		if c.Signups > 0 {
+			report.Campaigns[i].ConversionRate = float64(c.Attended) / float64(c.Signups)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		s.logError(r.Context(), fmt.Errorf("encode: %w", err))
+	}
+}
+
+// ReportPeriod parses the inclusive "from" and "to" dates into a [from, to) time range in now's location.
+func reportPeriod(q url.Values, now time.Time) (time.Time, time.Time, error) {
+	const layout = "2006-01-02"
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	to := today.AddDate(0, 0, 1)
+	if raw := q.Get("to"); raw != "" {
+		d, err := time.ParseInLocation(layout, raw, now.Location())
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid 'to' date: %q", raw)
+		}
+		to = d.AddDate(0, 0, 1)
+	}
+
+	from := to.AddDate(0, 0, -defaultReportDays)
+	if raw := q.Get("from"); raw != "" {
+		d, err := time.ParseInLocation(layout, raw, now.Location())
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid 'from' date: %q", raw)
+		}
+		from = d
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, errors.New("'from' must be on or before 'to'")
+	}
+	return from, to, nil
+}
+
+func (s *campaignReportServer) logError(ctx context.Context, err error) {
+	logging.Error(ctx, s.logger, err)
+}
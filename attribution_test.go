@@ -0,0 +1,102 @@
+package signup
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockCampaignReporter struct {
+	from, to time.Time
+	stats    []campaignStats
+}
+
+func (m *mockCampaignReporter) CampaignReport(ctx context.Context, from, to time.Time, dst any) error {
+	m.from, m.to = from, to
+	*(dst.(*[]campaignStats)) = m.stats
+	return nil
+}
+
+func TestParseLandingPageUTM(t *testing.T) {
+	su := Signup{
+		UTMSource:   "newsletter",
+		LandingPage: "https://www.operationspark.org/programs?utm_source=facebook&utm_medium=paid-social&utm_campaign=spring",
+	}
+	su.parseLandingPageUTM()
+
+	// Values sent with the signup win.
+	require.Equal(t, "newsletter", su.UTMSource)
+	require.Equal(t, "paid-social", su.UTMMedium)
+	require.Equal(t, "spring", su.UTMCampaign)
+	require.Equal(t, "", su.UTMTerm)
+}
+
+func TestReportPeriod(t *testing.T) {
+	tz, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+	now := time.Date(2024, time.March, 15, 13, 30, 0, 0, tz)
+
+	t.Run("defaults to the last 30 days", func(t *testing.T) {
+		from, to, err := reportPeriod(url.Values{}, now)
+		require.NoError(t, err)
+		require.Equal(t, time.Date(2024, time.March, 16, 0, 0, 0, 0, tz), to)
+		require.Equal(t, time.Date(2024, time.February, 15, 0, 0, 0, 0, tz), from)
+	})
+
+	t.Run("includes the 'to' date", func(t *testing.T) {
+		from, to, err := reportPeriod(url.Values{"from": {"2024-01-01"}, "to": {"2024-01-31"}}, now)
+		require.NoError(t, err)
+		require.Equal(t, time.Date(2024, time.January, 1, 0, 0, 0, 0, tz), from)
+		require.Equal(t, time.Date(2024, time.February, 1, 0, 0, 0, 0, tz), to)
+	})
+
+	t.Run("rejects invalid dates", func(t *testing.T) {
+		_, _, err := reportPeriod(url.Values{"from": {"01/01/2024"}}, now)
+		require.ErrorContains(t, err, "invalid 'from' date")
+
+		_, _, err = reportPeriod(url.Values{"from": {"2024-02-01"}, "to": {"2024-01-01"}}, now)
+		require.Error(t, err)
+	})
+}
+
+func TestCampaignReportServer(t *testing.T) {
+	store := &mockCampaignReporter{stats: []campaignStats{
+		{Source: "facebook", Medium: "paid-social", Campaign: "spring", Signups: 8, Attended: 6},
+		{Signups: 2},
+	}}
+	s := NewCampaignReportServer("report-key", store, slog.Default())
+
+	t.Run("responds with conversion rates by campaign", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/reports/campaigns?from=2024-01-01&to=2024-01-31", nil)
+		req.Header.Set("Authorization", "Bearer report-key")
+		res := httptest.NewRecorder()
+
+		s.ServeHTTP(res, req)
+
+		require.Equal(t, http.StatusOK, res.Code)
+		var got campaignReport
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+		require.Len(t, got.Campaigns, 2)
+		require.Equal(t, "spring", got.Campaigns[0].Campaign)
+		require.Equal(t, 0.75, got.Campaigns[0].ConversionRate)
+		require.Equal(t, "2024-01-01", store.from.Format("2006-01-02"))
+		require.Equal(t, "2024-02-01", store.to.Format("2006-01-02"))
+	})
+
+	t.Run("requires the API key", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/reports/campaigns", nil)
+		req.Header.Set("Authorization", "Bearer wrong-key")
+		res := httptest.NewRecorder()
+
+		s.ServeHTTP(res, req)
+
+		require.Equal(t, http.StatusUnauthorized, res.Code)
+	})
+}
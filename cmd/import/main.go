@@ -0,0 +1,49 @@
+// Command import registers signups collected offline (Ex: paper forms at in-person events) from a CSV file and prints a JSON report with the result of each row.
+//
+//	go run ./cmd/import -file signups.csv -skip slack -dry-run
+//	go run ./cmd/import -file signups.csv -skip slack > report.json
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+
+	signup "github.com/operationspark/service-signup"
+)
+
+func main() {
+	file := flag.String("file", "", "CSV file to import. Default: stdin")
+	skip := flag.String("skip", "", `comma-separated signup tasks to skip. Ex: "slack,snapMail"`)
+	concurrency := flag.Int("concurrency", 4, "signups to register at once")
+	dryRun := flag.Bool("dry-run", false, "validate the rows without registering them")
+	flag.Parse()
+
+	cfg, err := signup.LoadConfig(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		log.Fatalf("config:\n%v", err)
+	}
+
+	in := os.Stdin
+	if *file != "" {
+		in, err = os.Open(*file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer in.Close()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	o := signup.ImportOptions{Concurrency: *concurrency, DryRun: *dryRun}
+	if *skip != "" {
+		o.Skip = strings.Split(*skip, ",")
+	}
+	if err := signup.ImportSignups(ctx, cfg, in, os.Stdout, o); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,36 @@
+// Command roster prints the roster of a session, or of the sessions in a date range, as CSV or JSON.
+//
+//	go run ./cmd/roster -session abc123
+//	go run ./cmd/roster -from 2024-01-01 -to 2024-01-31 -format json > roster.json
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+
+	signup "github.com/operationspark/service-signup"
+)
+
+func main() {
+	sessionID := flag.String("session", "", "Greenlight session ID")
+	from := flag.String("from", "", "first session date (YYYY-MM-DD, Central Time)")
+	to := flag.String("to", "", "last session date (YYYY-MM-DD, Central Time). Default: -from")
+	format := flag.String("format", "csv", `output format: "csv" or "json"`)
+	flag.Parse()
+
+	cfg, err := signup.LoadConfig(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		log.Fatalf("config:\n%v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	q := signup.RosterQuery{SessionID: *sessionID, From: *from, To: *to}
+	if err := signup.ExportRoster(ctx, cfg, os.Stdout, q, *format); err != nil {
+		log.Fatal(err)
+	}
+}
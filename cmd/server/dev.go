@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/operationspark/service-signup/fakes"
+)
+
+// StartFakes starts the fakes server on a free local port.
+func startFakes() (*fakes.Server, error) {
+	f := fakes.New(nil)
+	if err := f.Start("127.0.0.1:0"); err != nil {
+		return nil, err
+	}
+	log.Printf("dev mode: fake integrations listening at %s\n", f.URL)
+	return f, nil
+}
+
+// DevHandler serves the outbox page next to the app.
+func devHandler(app http.Handler, f *fakes.Server) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /dev/outbox", f.Outbox)
+	mux.Handle("/", app)
+	return mux
+}
+
+func closeFakes(ctx context.Context, f *fakes.Server) {
+	if f == nil {
+		return
+	}
+	if err := f.Close(ctx); err != nil {
+		log.Printf("close fakes: %v\n", err)
+	}
+}
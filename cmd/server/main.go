@@ -1,28 +1,134 @@
+// Command server runs the signup service as a standalone HTTP server.
+//
+// On SIGINT or SIGTERM, the server fails readiness checks, stops accepting connections, waits for in-flight requests (signups, reminder runs) to finish, then disconnects from MongoDB and flushes telemetry.
+//
+// With -dev, every external integration is replaced by in-process fakes (see package fakes) and the requests they receive are listed at /dev/outbox.
 package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
-	"github.com/GoogleCloudPlatform/functions-framework-go/funcframework"
 	signup "github.com/operationspark/service-signup"
+	"github.com/operationspark/service-signup/fakes"
 )
 
 func main() {
-	ctx := context.Background()
-	err := funcframework.RegisterHTTPFunctionContext(ctx, "/", signup.NewServer().ServeHTTP)
+	printConfig := flag.Bool("print-config", false, "print the loaded config with secrets redacted and exit")
+	dev := flag.Bool("dev", false, "replace every integration with in-process fakes (same as DEV_MODE=true)")
+	flag.Parse()
+
+	if *dev {
+		os.Setenv("DEV_MODE", "true")
+	}
+	cfg, err := signup.LoadConfig(os.Getenv("CONFIG_FILE"))
+	if *printConfig {
+		fmt.Println(cfg)
+		if err != nil {
+			log.Fatalf("config:\n%v", err)
+		}
+		return
+	}
+	if err != nil {
+		log.Fatalf("config:\n%v", err)
+	}
+
+	var fakeAPIs *fakes.Server
+	if cfg.Dev {
+		fakeAPIs, err = startFakes()
+		if err != nil {
+			log.Fatalf("startFakes: %v", err)
+		}
+		cfg = cfg.WithFakes(fakeAPIs.URL)
+	}
+
+	if err := run(cfg, fakeAPIs); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Run serves the app until SIGINT or SIGTERM. In dev mode, fakeAPIs is the fakes server the config points to, and its outbox is served at /dev/outbox.
+func run(cfg signup.Config, fakeAPIs *fakes.Server) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	defer closeFakes(context.Background(), fakeAPIs)
+
+	app, err := signup.NewApp(cfg)
 	if err != nil {
-		log.Fatalf("funcframework.RegisterHTTPFunctionContext: %v\n", err)
+		return fmt.Errorf("newApp: %w", err)
 	}
 
-	// Use PORT environment variable, or default to 8080.
-	port := "8080"
-	if envPort := os.Getenv("PORT"); envPort != "" {
-		port = envPort
+	var handler http.Handler = app
+	if fakeAPIs != nil {
+		handler = devHandler(app, fakeAPIs)
+		log.Printf("dev mode: outbox at http://localhost:%s/dev/outbox\n", cfg.Port)
 	}
-	log.Printf("server starting on port: %s\n", port)
-	if err := funcframework.Start(port); err != nil {
-		log.Fatalf("funcframework.Start: %v\n", err)
+
+	srv := &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		log.Printf("server starting on port: %s\n", cfg.Port)
+		errc <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		// The server failed to start (Ex: the port is in use).
+		closeErr := app.Close(context.Background())
+		return errors.Join(fmt.Errorf("listenAndServe: %w", err), closeErr)
+	case <-ctx.Done():
+	}
+	// A second signal kills the process immediately.
+	stop()
+
+	log.Println("shutting down")
+	timeout := time.Duration(cfg.ShutdownTimeoutSeconds) * time.Second
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	app.Drain()
+	// Keep serving while load balancers notice /readyz failing.
+	if drain := drainDelay(cfg); drain > 0 {
+		log.Printf("draining for %s\n", drain)
+		select {
+		case <-time.After(drain):
+		case <-shutdownCtx.Done():
+		}
+	}
+	// Shutdown waits for in-flight requests to return.
+	shutdownErr := srv.Shutdown(shutdownCtx)
+	if shutdownErr != nil {
+		shutdownErr = fmt.Errorf("shutdown: %w", shutdownErr)
+	}
+	// Connections are closed even if requests did not finish in time.
+	closeErr := app.Close(shutdownCtx)
+	if err := errors.Join(shutdownErr, closeErr); err != nil {
+		return err
+	}
+	log.Println("server stopped")
+	return nil
+}
+
+// DrainDelay returns how long to keep serving after /readyz starts failing. Dev mode doesn't wait because nothing is routing traffic to it, and the delay is cut to half the shutdown timeout so in-flight requests have time to finish.
+func drainDelay(cfg signup.Config) time.Duration {
+	if cfg.Dev {
+		return 0
 	}
+	return min(
+		time.Duration(cfg.ShutdownDrainSeconds)*time.Second,
+		time.Duration(cfg.ShutdownTimeoutSeconds)*time.Second/2,
+	)
 }
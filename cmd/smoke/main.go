@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"html"
 	"io"
 	"net/http"
 	"os"
@@ -16,6 +17,7 @@ import (
 	signup "github.com/operationspark/service-signup"
 	"github.com/operationspark/service-signup/gcloud"
 	"github.com/operationspark/service-signup/greenlight"
+	"github.com/operationspark/service-signup/notify"
 	"github.com/twilio/twilio-go"
 	twiAPI "github.com/twilio/twilio-go/rest/api/v2010"
 )
@@ -71,7 +73,7 @@ func (s *smoke) fetchInfoSessions() error {
 	type response struct {
 		Sessions []openSession `json:"sessions"`
 	}
-	resp, err := http.Get(s.glAPIurl + "/sessions/open?programId=5sTmB97DzcqCwEZFR&limit=4")
+	resp, err := http.Get(s.glAPIurl + "/sessions/open?programId=" + notify.InfoSessionProgramID + "&limit=4")
 	if err != nil {
 		return fmt.Errorf("GET: %w", err)
 	}
@@ -189,6 +191,8 @@ func checkInfoPageContent(body io.Reader, wantStrings ...string) error {
 	if err != nil {
 		return fmt.Errorf("readAll: %w", err)
 	}
+	// Renderers escape characters in URLs. Ex: "+" -> "&#43;"
+	b = []byte(html.UnescapeString(string(b)))
 
 	for _, targetVal := range wantStrings {
 		found := bytes.Contains(b, []byte(targetVal))
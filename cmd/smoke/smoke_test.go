@@ -149,6 +149,12 @@ func TestCheckInfoPageContent(t *testing.T) {
 		)
 		require.Errorf(t, err, `target "Today" is missing from body and should produce an error`)
 	})
+
+	t.Run("finds targets with escaped HTML characters", func(t *testing.T) {
+		html := `<html><body><a href="https://www.google.com/maps/place/514&#43;Franklin&#43;Ave">Map</a></body></html>`
+		err := checkInfoPageContent(strings.NewReader(html), "https://www.google.com/maps/place/514+Franklin+Ave")
+		require.NoError(t, err)
+	})
 }
 
 // CheckTestsEnabled checks if the 'SMOKE_LIVE' env var is explicitly set to "true". If so, returns true, otherwise returns false. We only want these tests to run after a successful deployment.
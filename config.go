@@ -0,0 +1,588 @@
+package signup
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/operationspark/service-signup/notify"
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// Config holds every setting the service reads at startup.
+	// Values are loaded from the field defaults, then the optional config file, then environment variables. Struct tags describe each field:
+	//   - env: environment variable that overrides the field.
+	//   - default: value used when neither the file nor the environment sets one.
+	//   - required: must not be empty unless running in CI or dev mode.
+	//   - secret: hidden when the config is printed.
+	Config struct {
+		// "staging" uses the development versions of Mailgun templates.
+		AppEnv string `json:"appEnv" env:"APP_ENV"`
+		// Port for the standalone server (cmd/server).
+		Port string `json:"port" env:"PORT" default:"8080"`
+		// Seconds the standalone server waits for in-flight requests after SIGTERM. Cloud Run allows 10 seconds before killing the container.
+		ShutdownTimeoutSeconds int `json:"shutdownTimeoutSeconds" env:"SHUTDOWN_TIMEOUT_SECONDS" default:"10"`
+		// Seconds the standalone server keeps serving after SIGTERM with /readyz failing, so load balancers stop sending requests before the listener closes. Counts toward the shutdown timeout, and is cut to half of it so in-flight requests have time to finish.
+		ShutdownDrainSeconds int `json:"shutdownDrainSeconds" env:"SHUTDOWN_DRAIN_SECONDS" default:"5"`
+		// Set by CI runners. Skips required values and MongoDB connections so tests can build the server.
+		CI bool `json:"ci" env:"CI"`
+		// Local development mode (cmd/server -dev). Skips required values because integrations are replaced by fakes.
+		Dev bool `json:"dev" env:"DEV_MODE"`
+
+		MongoURI string `json:"mongoURI" env:"MONGO_URI" required:"true" secret:"true"`
+
+		Sentry     SentryConfig     `json:"sentry"`
+		Tracing    TracingConfig    `json:"tracing"`
+		Greenlight GreenlightConfig `json:"greenlight"`
+		Email      EmailConfig      `json:"email"`
+		Mailgun    MailgunConfig    `json:"mailgun"`
+		Zoom       ZoomConfig       `json:"zoom"`
+		Twilio     TwilioConfig     `json:"twilio"`
+		Messaging  MessagingConfig  `json:"messaging"`
+		Slack      SlackConfig      `json:"slack"`
+		SnapMail   SnapMailConfig   `json:"snapMail"`
+		SMS        SMSConfig        `json:"sms"`
+		Guard      GuardConfig      `json:"guard"`
+		CORS       CORSConfig       `json:"cors"`
+		Tasks      TasksConfig      `json:"tasks"`
+		// Greenlight programs people can sign up for, set in the config file. The Info Session program is always configured; an entry with its ID overrides its defaults.
+		Programs []ProgramConfig `json:"programs"`
+
+		// Operation Spark Message Template Renderer service base URL.
+		RendererURL string `json:"rendererURL" env:"OS_RENDERING_SERVICE_URL" required:"true"`
+		// Operation Spark URL shortener API endpoint. Defaults to https://ospk.org/api/urls.
+		ShortenerURL string `json:"shortenerURL" env:"URL_SHORTENER_URL"`
+		// Operation Spark URL shortener API key. The messaging service accepts the same key.
+		ShortenerAPIKey string `json:"shortenerAPIKey" env:"URL_SHORTENER_API_KEY" required:"true" secret:"true"`
+		// Bearer token for /reports/campaigns.
+		ReportsAPIKey string `json:"reportsAPIKey" env:"REPORTS_API_KEY" secret:"true"`
+		// Bearer token for the /admin API. Empty disables the admin API.
+		AdminAPIKey string `json:"adminAPIKey" env:"ADMIN_API_KEY" secret:"true"`
+		// Bearer token for /metrics. Empty disables /metrics, except in dev mode where anyone can scrape them.
+		MetricsToken string `json:"metricsToken" env:"METRICS_TOKEN" secret:"true"`
+		// Seconds /readyz reuses its dependency checks. 0 checks on every request.
+		ReadinessCacheSeconds int `json:"readinessCacheSeconds" env:"READINESS_CACHE_SECONDS" default:"30"`
+	}
+
+	SentryConfig struct {
+		DSN        string  `json:"dsn" env:"SENTRY_DSN" secret:"true"`
+		SampleRate float64 `json:"sampleRate" env:"SENTRY_SAMPLE_RATE" default:"1.0"`
+		Env        string  `json:"env" env:"SENTRY_ENV" default:"development"`
+	}
+
+	TracingConfig struct {
+		// "otlp" | "stdout" | "none". The OTLP exporter reads the standard OTEL_EXPORTER_OTLP_* env vars itself.
+		Exporter string `json:"exporter" env:"OTEL_TRACES_EXPORTER"`
+	}
+
+	GreenlightConfig struct {
+		APIKey     string `json:"apiKey" env:"GREENLIGHT_API_KEY" required:"true" secret:"true"`
+		WebhookURL string `json:"webhookURL" env:"GREENLIGHT_WEBHOOK_URL" required:"true"`
+		// Base URL for links to Greenlight in SMS and Slack messages. Defaults to production Greenlight.
+		Host string `json:"host" env:"GREENLIGHT_HOST"`
+	}
+
+	EmailConfig struct {
+		// "mailgun" | "smtp" | "capture"
+		Provider   string     `json:"provider" env:"EMAIL_PROVIDER" default:"mailgun"`
+		CaptureDir string     `json:"captureDir" env:"EMAIL_CAPTURE_DIR"`
+		SMTP       SMTPConfig `json:"smtp"`
+	}
+
+	SMTPConfig struct {
+		Host     string `json:"host" env:"SMTP_HOST"`
+		Port     int    `json:"port" env:"SMTP_PORT"`
+		Username string `json:"username" env:"SMTP_USERNAME"`
+		Password string `json:"password" env:"SMTP_PASSWORD" secret:"true"`
+	}
+
+	MailgunConfig struct {
+		Domain            string `json:"domain" env:"MAIL_DOMAIN" required:"true"`
+		APIKey            string `json:"apiKey" env:"MAILGUN_API_KEY" required:"true" secret:"true"`
+		WebhookSigningKey string `json:"webhookSigningKey" env:"MAILGUN_WEBHOOK_SIGNING_KEY" secret:"true"`
+		// Overrides the Mailgun API base. Ex: "https://api.eu.mailgun.net/v3".
+		APIBase string `json:"apiBase" env:"MAILGUN_API_BASE"`
+	}
+
+	ZoomConfig struct {
+		AccountID    string `json:"accountID" env:"ZOOM_ACCOUNT_ID" required:"true"`
+		ClientID     string `json:"clientID" env:"ZOOM_CLIENT_ID" required:"true"`
+		ClientSecret string `json:"clientSecret" env:"ZOOM_CLIENT_SECRET" required:"true" secret:"true"`
+		// Noon and 5pm (Central Time) meeting IDs.
+		Meeting12 string `json:"meeting12" env:"ZOOM_MEETING_12" required:"true"`
+		Meeting17 string `json:"meeting17" env:"ZOOM_MEETING_17" required:"true"`
+		// Override the Zoom API and OAuth base URLs. Defaults to "https://api.zoom.us/v2" and "https://zoom.us/oauth".
+		APIBase   string `json:"apiBase" env:"ZOOM_API_BASE"`
+		OAuthBase string `json:"oauthBase" env:"ZOOM_OAUTH_BASE"`
+	}
+
+	TwilioConfig struct {
+		AccountSID       string `json:"accountSID" env:"TWILIO_ACCOUNT_SID" required:"true"`
+		AuthToken        string `json:"authToken" env:"TWILIO_AUTH_TOKEN" required:"true" secret:"true"`
+		PhoneNumber      string `json:"phoneNumber" env:"TWILIO_PHONE_NUMBER" required:"true"`
+		ConversationsSID string `json:"conversationsSID" env:"TWILIO_CONVERSATIONS_SID" required:"true"`
+		// Sends Twilio API requests to another server. Ex: "http://localhost:8081/twilio" sends "https://conversations.twilio.com/v1/..." requests to "http://localhost:8081/twilio/conversations/v1/...".
+		APIBase string `json:"apiBase" env:"TWILIO_API_BASE"`
+	}
+
+	MessagingConfig struct {
+		// Operation Spark Messenger service base URL.
+		ServiceURL    string `json:"serviceURL" env:"OS_MESSAGING_SERVICE_URL" required:"true"`
+		SigningSecret string `json:"signingSecret" env:"OS_MESSAGING_SIGNING_SECRET" required:"true" secret:"true"`
+	}
+
+	SlackConfig struct {
+		WebhookURL string `json:"webhookURL" env:"SLACK_WEBHOOK_URL" required:"true" secret:"true"`
+		// Signup digests channel. Defaults to WebhookURL.
+		DigestWebhookURL string `json:"digestWebhookURL" env:"SLACK_DIGEST_WEBHOOK_URL" secret:"true"`
+		// Verifies /slack/actions requests.
+		SigningSecret string `json:"signingSecret" env:"SLACK_SIGNING_SECRET" secret:"true"`
+	}
+
+	SnapMailConfig struct {
+		URL           string `json:"url" env:"SNAP_MAIL_URL"`
+		SigningSecret string `json:"signingSecret" env:"SIGNING_SECRET" secret:"true"`
+	}
+
+	SMSConfig struct {
+		// Outbound messages per second per sending number.
+		MessagesPerSecond float64 `json:"messagesPerSecond" env:"SMS_MESSAGES_PER_SECOND" default:"1"`
+		// Quiet hours (0-23) in Central Time (sms.DefaultTZName). Signups don't record the person's time zone.
+		QuietHoursStart int `json:"quietHoursStart" env:"SMS_QUIET_HOURS_START" default:"21"`
+		QuietHoursEnd   int `json:"quietHoursEnd" env:"SMS_QUIET_HOURS_END" default:"8"`
+		// Maximum segments a rendered message can use. 0 uses the templates package default.
+		SegmentBudget   int    `json:"segmentBudget" env:"SMS_SEGMENT_BUDGET"`
+		TemplatesDir    string `json:"templatesDir" env:"SMS_TEMPLATES_DIR"`
+		TemplatesFromDB bool   `json:"templatesFromDB" env:"SMS_TEMPLATES_FROM_DB"`
+	}
+
+	GuardConfig struct {
+		// "recaptcha" | "turnstile" | "fake". Empty disables CAPTCHA verification.
+		CaptchaProvider  string  `json:"captchaProvider" env:"CAPTCHA_PROVIDER"`
+		CaptchaSecretKey string  `json:"captchaSecretKey" env:"CAPTCHA_SECRET_KEY" secret:"true"`
+		CaptchaMinScore  float64 `json:"captchaMinScore" env:"CAPTCHA_MIN_SCORE" default:"0.5"`
+		// Signups allowed per hour. 0 disables the limit.
+		LimitPerIP    int `json:"limitPerIP" env:"SIGNUP_LIMIT_PER_IP" default:"10"`
+		LimitPerEmail int `json:"limitPerEmail" env:"SIGNUP_LIMIT_PER_EMAIL" default:"3"`
+		// Save suspicious signups for review instead of rejecting them.
+		Quarantine bool `json:"quarantine" env:"SIGNUP_QUARANTINE"`
+	}
+
+	CORSConfig struct {
+		// Env values are comma- or space-separated lists.
+		AllowedOrigins   []string `json:"allowedOrigins" env:"CORS_ALLOWED_ORIGINS"`
+		AllowedMethods   []string `json:"allowedMethods" env:"CORS_ALLOWED_METHODS"`
+		AllowedHeaders   []string `json:"allowedHeaders" env:"CORS_ALLOWED_HEADERS"`
+		AllowCredentials bool     `json:"allowCredentials" env:"CORS_ALLOW_CREDENTIALS"`
+		// Seconds browsers can cache preflight responses.
+		MaxAge int `json:"maxAge" env:"CORS_MAX_AGE" default:"600"`
+	}
+
+	// TasksConfig changes which tasks run when someone signs up. Task keys: zoom, joinCode, shortLink, greenlight, welcomeEmail, slack, sms, snapMail, conversationLink.
+	TasksConfig struct {
+		// Env values are comma- or space-separated lists of task keys.
+		Disabled []string `json:"disabled" env:"SIGNUP_TASKS_DISABLED"`
+		// Tasks that fail the signup when they fail.
+		Required []string `json:"required" env:"SIGNUP_TASKS_REQUIRED"`
+		// Tasks that are logged and skipped when they fail.
+		Optional []string `json:"optional" env:"SIGNUP_TASKS_OPTIONAL"`
+		// Seconds each task attempt can take. 0 disables the timeout.
+		TimeoutSeconds int `json:"timeoutSeconds" env:"SIGNUP_TASK_TIMEOUT_SECONDS" default:"10"`
+		// Per-task timeouts as "key=seconds" pairs. Ex: "zoom=5 sms=15".
+		Timeouts []string `json:"timeouts" env:"SIGNUP_TASK_TIMEOUTS"`
+		// Extra attempts for tasks that are safe to repeat (zoom, shortLink).
+		Retries int `json:"retries" env:"SIGNUP_TASK_RETRIES" default:"2"`
+		// Milliseconds before the first retry. Doubles for each retry, with jitter.
+		RetryBackoffMillis int `json:"retryBackoffMillis" env:"SIGNUP_TASK_RETRY_BACKOFF_MS" default:"200"`
+		// Consecutive failures that open an integration's circuit. Tasks calling an open circuit fail fast, or are skipped if not required. 0 disables circuit breakers.
+		BreakerFailures int `json:"breakerFailures" env:"SIGNUP_BREAKER_FAILURES" default:"5"`
+		// Seconds an open circuit waits before letting a trial request through.
+		BreakerCooldownSeconds int `json:"breakerCooldownSeconds" env:"SIGNUP_BREAKER_COOLDOWN_SECONDS" default:"30"`
+	}
+
+	// ProgramConfig sets how signups for one Greenlight program are confirmed and reminded. Empty values use the Info Session defaults.
+	ProgramConfig struct {
+		// Greenlight program ID.
+		ID string `json:"id"`
+		// Mailgun welcome email template. Hybrid sessions use the "-hybrid" version. Ex: "info-session-signup".
+		WelcomeTemplate string `json:"welcomeTemplate"`
+		// Message Template Renderer templates for the pages linked from the confirmation and reminder SMS messages. Ex: "InfoSession", "Reminder".
+		RendererTemplate         string `json:"rendererTemplate"`
+		ReminderRendererTemplate string `json:"reminderRendererTemplate"`
+		// Zoom meetings as "hour=meetingID" pairs, where hour is the Central Time session start hour. Ex: "12=81100000012". Without meetings, the program's signups are not registered for Zoom.
+		ZoomMeetings []string `json:"zoomMeetings"`
+		// SMS templates for the signup confirmation and session reminders. Ex: "signup-confirmation", "reminder".
+		ConfirmationSMSTemplate string `json:"confirmationSMSTemplate"`
+		ReminderSMSTemplate     string `json:"reminderSMSTemplate"`
+		// Reminder periods before a session to text participants. Ex: "2 days", "1 hour". Empty sends every scheduled reminder.
+		Reminders []string `json:"reminders"`
+	}
+)
+
+const redacted = "[REDACTED]"
+
+// LoadConfig loads the config from the defaults, the JSON or YAML file at path (if not empty), and the environment, then validates it.
+// Every invalid value is reported in the returned error, not just the first.
+func LoadConfig(path string) (Config, error) {
+	var c Config
+	var errs []error
+
+	walkConfig(reflect.ValueOf(&c).Elem(), func(f reflect.Value, sf reflect.StructField) {
+		if def, ok := sf.Tag.Lookup("default"); ok {
+			if err := setField(f, def); err != nil {
+				errs = append(errs, fmt.Errorf("%s: default: %w", sf.Name, err))
+			}
+		}
+	})
+
+	if path != "" {
+		if err := c.loadFile(path); err != nil {
+			return c, fmt.Errorf("loadFile: %w", err)
+		}
+	}
+
+	walkConfig(reflect.ValueOf(&c).Elem(), func(f reflect.Value, sf reflect.StructField) {
+		key := sf.Tag.Get("env")
+		raw := os.Getenv(key)
+		if key == "" || raw == "" {
+			return
+		}
+		if err := setField(f, raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	})
+
+	if err := c.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	return c, errors.Join(errs...)
+}
+
+// LoadFile decodes a JSON or YAML config file over the current values. Unknown keys are errors so typos are not silently ignored.
+func (c *Config) loadFile(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("readFile: %w", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+	case ".yaml", ".yml":
+		// Convert to JSON so one set of struct tags describes both formats.
+		var doc map[string]any
+		if err := yaml.Unmarshal(b, &doc); err != nil {
+			return fmt.Errorf("yaml.Unmarshal: %w", err)
+		}
+		if b, err = json.Marshal(doc); err != nil {
+			return fmt.Errorf("marshal: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported config file type %q", ext)
+	}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+	if err := d.Decode(c); err != nil {
+		return fmt.Errorf("decode %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate returns every problem with the config joined into one error.
+// Required values are not checked in CI or dev mode so the server can be built without credentials.
+func (c Config) Validate() error {
+	var errs []error
+	invalid := func(key string, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	if !c.CI && !c.Dev {
+		walkConfig(reflect.ValueOf(&c).Elem(), func(f reflect.Value, sf reflect.StructField) {
+			if sf.Tag.Get("required") == "true" && f.IsZero() {
+				errs = append(errs, fmt.Errorf("%s is required", sf.Tag.Get("env")))
+			}
+		})
+	}
+
+	if c.MongoURI != "" {
+		if u, err := url.Parse(c.MongoURI); err != nil || !strings.HasPrefix(u.Scheme, "mongodb") {
+			invalid("MONGO_URI", "must be a mongodb:// or mongodb+srv:// URI")
+		}
+	}
+	if c.Sentry.SampleRate < 0 || c.Sentry.SampleRate > 1 {
+		invalid("SENTRY_SAMPLE_RATE", "must be between 0 and 1, got %v", c.Sentry.SampleRate)
+	}
+	if !oneOf(c.Tracing.Exporter, "", "none", "otlp", "stdout") {
+		invalid("OTEL_TRACES_EXPORTER", "unknown exporter %q", c.Tracing.Exporter)
+	}
+	if !oneOf(c.Email.Provider, "", "mailgun", "smtp", "capture") {
+		invalid("EMAIL_PROVIDER", "unknown provider %q", c.Email.Provider)
+	}
+	if c.Email.Provider == "smtp" && c.Email.SMTP.Host == "" {
+		invalid("SMTP_HOST", "is required when EMAIL_PROVIDER is \"smtp\"")
+	}
+	if !oneOf(c.Guard.CaptchaProvider, "", "recaptcha", "turnstile", "fake") {
+		invalid("CAPTCHA_PROVIDER", "unknown provider %q", c.Guard.CaptchaProvider)
+	}
+	if oneOf(c.Guard.CaptchaProvider, "recaptcha", "turnstile") && c.Guard.CaptchaSecretKey == "" {
+		invalid("CAPTCHA_SECRET_KEY", "is required when CAPTCHA_PROVIDER is %q", c.Guard.CaptchaProvider)
+	}
+	if c.SMS.MessagesPerSecond <= 0 {
+		invalid("SMS_MESSAGES_PER_SECOND", "must be positive, got %v", c.SMS.MessagesPerSecond)
+	}
+	for key, hour := range map[string]int{
+		"SMS_QUIET_HOURS_START": c.SMS.QuietHoursStart,
+		"SMS_QUIET_HOURS_END":   c.SMS.QuietHoursEnd,
+	} {
+		if hour < 0 || hour > 23 {
+			invalid(key, "invalid hour %d", hour)
+		}
+	}
+	for key, n := range map[string]int{
+		"SMS_SEGMENT_BUDGET":              c.SMS.SegmentBudget,
+		"SIGNUP_LIMIT_PER_IP":             c.Guard.LimitPerIP,
+		"SIGNUP_LIMIT_PER_EMAIL":          c.Guard.LimitPerEmail,
+		"CORS_MAX_AGE":                    c.CORS.MaxAge,
+		"READINESS_CACHE_SECONDS":         c.ReadinessCacheSeconds,
+		"SIGNUP_TASK_TIMEOUT_SECONDS":     c.Tasks.TimeoutSeconds,
+		"SIGNUP_TASK_RETRIES":             c.Tasks.Retries,
+		"SIGNUP_TASK_RETRY_BACKOFF_MS":    c.Tasks.RetryBackoffMillis,
+		"SIGNUP_BREAKER_FAILURES":         c.Tasks.BreakerFailures,
+		"SIGNUP_BREAKER_COOLDOWN_SECONDS": c.Tasks.BreakerCooldownSeconds,
+		"SMTP_PORT":                       c.Email.SMTP.Port,
+		"SHUTDOWN_TIMEOUT_SECONDS":        c.ShutdownTimeoutSeconds,
+		"SHUTDOWN_DRAIN_SECONDS":          c.ShutdownDrainSeconds,
+	} {
+		if n < 0 {
+			invalid(key, "must not be negative, got %d", n)
+		}
+	}
+	for key, tasks := range map[string][]string{
+		"SIGNUP_TASKS_DISABLED": c.Tasks.Disabled,
+		"SIGNUP_TASKS_REQUIRED": c.Tasks.Required,
+		"SIGNUP_TASKS_OPTIONAL": c.Tasks.Optional,
+	} {
+		for _, task := range tasks {
+			if !oneOf(task, signupTaskKeys...) {
+				invalid(key, "unknown task %q", task)
+			}
+		}
+	}
+	for _, task := range c.Tasks.Required {
+		if slices.Contains(c.Tasks.Optional, task) {
+			invalid("SIGNUP_TASKS_OPTIONAL", "task %q is also in SIGNUP_TASKS_REQUIRED", task)
+		}
+	}
+	timeouts, err := parseTaskTimeouts(c.Tasks.Timeouts)
+	if err != nil {
+		invalid("SIGNUP_TASK_TIMEOUTS", "%v", err)
+	}
+	for task := range timeouts {
+		if !oneOf(task, signupTaskKeys...) {
+			invalid("SIGNUP_TASK_TIMEOUTS", "unknown task %q", task)
+		}
+	}
+	seen := map[string]bool{}
+	for i, p := range c.Programs {
+		key := fmt.Sprintf("programs[%d]", i)
+		if p.ID == "" {
+			invalid(key+".id", "is required")
+		} else if seen[p.ID] {
+			invalid(key+".id", "duplicate program %q", p.ID)
+		}
+		seen[p.ID] = true
+		for field, tmpl := range map[string]struct {
+			name osRendererTemplate
+			want []osRendererTemplate
+		}{
+			".rendererTemplate":         {osRendererTemplate(p.RendererTemplate), []osRendererTemplate{InfoSessionTemplate}},
+			".reminderRendererTemplate": {osRendererTemplate(p.ReminderRendererTemplate), []osRendererTemplate{InfoSessionTemplate, ReminderTemplate}},
+		} {
+			// Built-in templates must show the kind of page the field links to.
+			if _, ok := rendererTemplates[tmpl.name]; ok && !slices.Contains(tmpl.want, tmpl.name) {
+				invalid(key+field, "template %q must be one of %v", tmpl.name, tmpl.want)
+			}
+		}
+		if _, err := parseZoomMeetings(p.ZoomMeetings); err != nil {
+			invalid(key+".zoomMeetings", "%v", err)
+		}
+		for _, r := range p.Reminders {
+			if _, err := notify.Period(r).Parse(); err != nil {
+				invalid(key+".reminders", "%v", err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Redacted returns a copy of the config with secret values hidden. Passwords in URIs are hidden, but the rest of the URI is kept to help debugging.
+func (c Config) Redacted() Config {
+	walkConfig(reflect.ValueOf(&c).Elem(), func(f reflect.Value, sf reflect.StructField) {
+		if sf.Tag.Get("secret") != "true" || f.Kind() != reflect.String || f.String() == "" {
+			return
+		}
+		if u, err := url.Parse(f.String()); err == nil && u.User != nil {
+			if _, ok := u.User.Password(); ok {
+				f.SetString(u.Redacted())
+				return
+			}
+		}
+		f.SetString(redacted)
+	})
+	return c
+}
+
+// String returns the redacted config as indented JSON, so the config is safe to print or log.
+func (c Config) String() string {
+	b, err := json.MarshalIndent(c.Redacted(), "", "  ")
+	if err != nil {
+		return fmt.Sprintf("config: %v", err)
+	}
+	return string(b)
+}
+
+// DigestWebhookURL returns the Slack webhook for signup digests, which go to their own channel when configured.
+func (c Config) digestWebhookURL() string {
+	if c.Slack.DigestWebhookURL != "" {
+		return c.Slack.DigestWebhookURL
+	}
+	return c.Slack.WebhookURL
+}
+
+// WithFakes returns a copy of the config with every integration pointed at the fakes server (see package fakes) at fakesURL. Missing credentials are filled with placeholders so the services can start.
+// Only MongoDB is real. It defaults to a local instance.
+func (c Config) WithFakes(fakesURL string) Config {
+	c.Dev = true
+	if c.AppEnv == "" {
+		c.AppEnv = "development"
+	}
+	if c.MongoURI == "" {
+		c.MongoURI = "mongodb://localhost:27017/greenlight"
+	}
+
+	c.Greenlight.WebhookURL = fakesURL + "/greenlight/signup"
+	c.Greenlight.APIKey = orDefault(c.Greenlight.APIKey, "dev-greenlight-key")
+	c.Greenlight.Host = orDefault(c.Greenlight.Host, "http://localhost:3000")
+
+	c.Email.Provider = "mailgun"
+	c.Mailgun.APIBase = fakesURL + "/mailgun/v3"
+	c.Mailgun.Domain = orDefault(c.Mailgun.Domain, "mail.dev.local")
+	c.Mailgun.APIKey = orDefault(c.Mailgun.APIKey, "dev-mailgun-key")
+
+	c.Zoom.APIBase = fakesURL + "/zoom/v2"
+	c.Zoom.OAuthBase = fakesURL + "/zoom/oauth"
+	c.Zoom.AccountID = orDefault(c.Zoom.AccountID, "dev-zoom-account")
+	c.Zoom.ClientID = orDefault(c.Zoom.ClientID, "dev-zoom-client")
+	c.Zoom.ClientSecret = orDefault(c.Zoom.ClientSecret, "dev-zoom-secret")
+	c.Zoom.Meeting12 = orDefault(c.Zoom.Meeting12, "81100000012")
+	c.Zoom.Meeting17 = orDefault(c.Zoom.Meeting17, "81100000017")
+
+	c.Twilio.APIBase = fakesURL + "/twilio"
+	c.Twilio.AccountSID = orDefault(c.Twilio.AccountSID, "ACdev00000000000000000000000000000")
+	c.Twilio.AuthToken = orDefault(c.Twilio.AuthToken, "dev-twilio-token")
+	c.Twilio.PhoneNumber = orDefault(c.Twilio.PhoneNumber, "+15005550006")
+	c.Twilio.ConversationsSID = orDefault(c.Twilio.ConversationsSID, "ISdev00000000000000000000000000000")
+
+	c.Messaging.ServiceURL = fakesURL + "/messenger"
+	c.Messaging.SigningSecret = orDefault(c.Messaging.SigningSecret, "dev-messaging-secret")
+
+	c.Slack.WebhookURL = fakesURL + "/slack/webhook"
+	c.Slack.DigestWebhookURL = fakesURL + "/slack/digest"
+
+	c.SnapMail.URL = fakesURL + "/snapmail/signup"
+
+	c.RendererURL = fakesURL + "/renderer"
+	c.ShortenerURL = fakesURL + "/shortener/api/urls"
+	c.ShortenerAPIKey = orDefault(c.ShortenerAPIKey, "dev-shortener-key")
+	return c
+}
+
+func orDefault(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+// WalkConfig calls fn for every leaf field in the struct, descending into nested config structs.
+func walkConfig(v reflect.Value, fn func(f reflect.Value, sf reflect.StructField)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f, sf := v.Field(i), t.Field(i)
+		if f.Kind() == reflect.Struct {
+			walkConfig(f, fn)
+			continue
+		}
+		fn(f, sf)
+	}
+}
+
+// SetField parses the raw string into the field's type.
+func setField(f reflect.Value, raw string) error {
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid bool %q", raw)
+		}
+		f.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		f.SetInt(int64(n))
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		f.SetFloat(n)
+	case reflect.Slice:
+		f.Set(reflect.ValueOf(splitList(raw)))
+	default:
+		return fmt.Errorf("unsupported config type %s", f.Type())
+	}
+	return nil
+}
+
+func oneOf(s string, options ...string) bool {
+	for _, o := range options {
+		if s == o {
+			return true
+		}
+	}
+	return false
+}
+
+// PipelineOptions converts the tasks config to signup pipeline options. Timeouts are assumed to be valid (see Validate).
+func (t TasksConfig) pipelineOptions() pipelineOptions {
+	timeouts, _ := parseTaskTimeouts(t.Timeouts)
+	return pipelineOptions{
+		disabled: t.Disabled,
+		required: t.Required,
+		optional: t.Optional,
+		policy: taskPolicy{
+			timeout: time.Duration(t.TimeoutSeconds) * time.Second,
+			retries: t.Retries,
+			backoff: time.Duration(t.RetryBackoffMillis) * time.Millisecond,
+		},
+		timeouts: timeouts,
+		breaker: breakerOptions{
+			failures: t.BreakerFailures,
+			cooldown: time.Duration(t.BreakerCooldownSeconds) * time.Second,
+		},
+	}
+}
@@ -16,6 +16,7 @@ type Service struct {
 	client           http.Client
 	messengerAPIBase string
 	signingSecret    []byte
+	httpError        func(vendor string, resp *http.Response) error
 }
 
 type Option func(*Service)
@@ -23,6 +24,9 @@ type Option func(*Service)
 func NewService(opts ...Option) *Service {
 	s := &Service{
 		client: http.Client{},
+		httpError: func(vendor string, resp *http.Response) error {
+			return fmt.Errorf("%s: %s", vendor, resp.Status)
+		},
 	}
 	for _, o := range opts {
 		o(s)
@@ -47,6 +51,13 @@ func WithSigningSecret(token string) Option {
 	}
 }
 
+// WithHTTPError sets how error responses from the Messenger API are turned into errors, so callers can classify them like the other integrations' errors.
+func WithHTTPError(f func(vendor string, resp *http.Response) error) Option {
+	return func(s *Service) {
+		s.httpError = f
+	}
+}
+
 func (s Service) Run(ctx context.Context, conversationID, signupID string) error {
 	if ctx.Err() != nil {
 		return ctx.Err()
@@ -95,7 +106,7 @@ func (s Service) linkConversation(ctx context.Context, conversationID string, si
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("messenger API link request: %s", resp.Status)
+		return fmt.Errorf("messenger API link request: %w", s.httpError("messenger", resp))
 	}
 	return nil
 }
@@ -2,6 +2,7 @@ package conversations_test
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -75,4 +76,27 @@ func TestLinkConversation(t *testing.T) {
 			require.NoError(t, err)
 		})
 	}
+
+	t.Run("returns error responses through the HTTP error func", func(t *testing.T) {
+		mockMessengerSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer mockMessengerSrv.Close()
+
+		errUnavailable := errors.New("unavailable")
+		var gotVendor string
+		var gotStatus int
+		svc := convos.NewService(
+			convos.WithMessengerAPIBase(mockMessengerSrv.URL),
+			convos.WithHTTPError(func(vendor string, resp *http.Response) error {
+				gotVendor, gotStatus = vendor, resp.StatusCode
+				return errUnavailable
+			}),
+		)
+
+		err := svc.Run(context.Background(), "123", "123")
+		require.ErrorIs(t, err, errUnavailable)
+		require.Equal(t, "messenger", gotVendor)
+		require.Equal(t, http.StatusServiceUnavailable, gotStatus)
+	})
 }
@@ -0,0 +1,158 @@
+package signup
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// corsOptions configures Cross-Origin Resource Sharing so browsers can call the service directly from the website.
+	// https://developer.mozilla.org/en-US/docs/Web/HTTP/CORS
+	corsOptions struct {
+		// Origins allowed to make requests. Ex: "https://www.operationspark.org".
+		// A "*" subdomain matches any subdomain (Ex: "https://*.operationspark.org"), and "*" alone allows any origin.
+		allowedOrigins []string
+		// Defaults to GET, POST, and OPTIONS.
+		allowedMethods []string
+		// Request headers allowed in addition to the CORS-safelisted headers. Defaults to Content-Type and Authorization.
+		allowedHeaders []string
+		// Allow cookies and Authorization headers on cross-origin requests.
+		allowCredentials bool
+		// How long browsers can cache preflight responses. Not sent if zero.
+		maxAge time.Duration
+	}
+
+	corsHandler struct {
+		opts    corsOptions
+		methods map[string]bool
+		headers map[string]bool
+		next    http.Handler
+	}
+)
+
+// NewCORS wraps the handler with CORS headers and answers preflight (OPTIONS) requests for every path.
+// Requests from origins that are not allowed get no CORS headers, so browsers block them. Preflights from those origins are rejected.
+func newCORS(o corsOptions, next http.Handler) http.Handler {
+	if len(o.allowedMethods) == 0 {
+		o.allowedMethods = []string{http.MethodGet, http.MethodPost, http.MethodOptions}
+	}
+	if len(o.allowedHeaders) == 0 {
+		o.allowedHeaders = []string{"Content-Type", "Authorization"}
+	}
+
+	c := &corsHandler{
+		opts:    o,
+		methods: map[string]bool{},
+		headers: map[string]bool{},
+		next:    next,
+	}
+	for _, m := range o.allowedMethods {
+		c.methods[strings.ToUpper(m)] = true
+	}
+	for _, h := range o.allowedHeaders {
+		c.headers[http.CanonicalHeaderKey(h)] = true
+	}
+	return c
+}
+
+func (c *corsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	origin := r.Header.Get("Origin")
+	isPreflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+	// Not a cross-origin request.
+	if origin == "" {
+		c.next.ServeHTTP(w, r)
+		return
+	}
+
+	// Responses differ by origin, so caches must not share them.
+	w.Header().Add("Vary", "Origin")
+	if isPreflight {
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+	}
+
+	if !c.originAllowed(origin) {
+		if isPreflight {
+			http.Error(w, "origin not allowed", http.StatusForbidden)
+			return
+		}
+		c.next.ServeHTTP(w, r)
+		return
+	}
+
+	if isPreflight {
+		c.preflight(w, r, origin)
+		return
+	}
+
+	c.setOrigin(w, origin)
+	c.next.ServeHTTP(w, r)
+}
+
+func (c *corsHandler) preflight(w http.ResponseWriter, r *http.Request, origin string) {
+	method := strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))
+	if !c.methods[method] {
+		http.Error(w, "method not allowed", http.StatusForbidden)
+		return
+	}
+
+	for _, h := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+		h = strings.TrimSpace(h)
+		if h != "" && !c.headers[http.CanonicalHeaderKey(h)] {
+			http.Error(w, "header not allowed: "+h, http.StatusForbidden)
+			return
+		}
+	}
+
+	c.setOrigin(w, origin)
+	w.Header().Set("Access-Control-Allow-Methods", strings.Join(c.opts.allowedMethods, ", "))
+	w.Header().Set("Access-Control-Allow-Headers", strings.Join(c.opts.allowedHeaders, ", "))
+	if c.opts.maxAge > 0 {
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(c.opts.maxAge.Seconds())))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *corsHandler) setOrigin(w http.ResponseWriter, origin string) {
+	// Browsers reject "*" on requests with credentials, so the origin is echoed back instead.
+	if c.allowsAnyOrigin() && !c.opts.allowCredentials {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	} else {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+	}
+	if c.opts.allowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+	// Lets the website read the request ID to report errors.
+	w.Header().Set("Access-Control-Expose-Headers", requestIDHeader)
+}
+
+func (c *corsHandler) allowsAnyOrigin() bool {
+	for _, o := range c.opts.allowedOrigins {
+		if o == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *corsHandler) originAllowed(origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, allowed := range c.opts.allowedOrigins {
+		allowed = strings.ToLower(allowed)
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+		// Ex: "https://*.operationspark.org" matches "https://www.operationspark.org".
+		if prefix, suffix, ok := strings.Cut(allowed, "*."); ok &&
+			strings.HasPrefix(origin, prefix) &&
+			strings.HasSuffix(origin, "."+suffix) &&
+			len(origin) > len(prefix)+len(suffix)+1 {
+			return true
+		}
+	}
+	return false
+}
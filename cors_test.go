@@ -0,0 +1,126 @@
+package signup
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCORS(t *testing.T) {
+	called := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	handler := newCORS(corsOptions{
+		allowedOrigins: []string{"https://www.operationspark.org", "https://*.opspark.org"},
+		maxAge:         10 * time.Minute,
+	}, next)
+
+	preflight := func(origin, method, headers string) *http.Request {
+		req := httptest.NewRequest(http.MethodOptions, "/", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", method)
+		if headers != "" {
+			req.Header.Set("Access-Control-Request-Headers", headers)
+		}
+		return req
+	}
+
+	t.Run("answers preflights from allowed origins", func(t *testing.T) {
+		called = false
+		res := httptest.NewRecorder()
+
+		handler.ServeHTTP(res, preflight("https://www.operationspark.org", "POST", "content-type"))
+
+		require.Equal(t, http.StatusNoContent, res.Code)
+		require.False(t, called, "preflights should not reach the handler")
+		require.Equal(t, "https://www.operationspark.org", res.Header().Get("Access-Control-Allow-Origin"))
+		require.Equal(t, "GET, POST, OPTIONS", res.Header().Get("Access-Control-Allow-Methods"))
+		require.Equal(t, "Content-Type, Authorization", res.Header().Get("Access-Control-Allow-Headers"))
+		require.Equal(t, "600", res.Header().Get("Access-Control-Max-Age"))
+		require.Contains(t, res.Header().Values("Vary"), "Origin")
+	})
+
+	t.Run("answers preflights for any path", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		req := preflight("https://app.opspark.org", "GET", "")
+		req.URL.Path = "/reports/campaigns"
+
+		handler.ServeHTTP(res, req)
+
+		require.Equal(t, http.StatusNoContent, res.Code)
+		require.Equal(t, "https://app.opspark.org", res.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("adds CORS headers to requests from allowed origins", func(t *testing.T) {
+		called = false
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("Origin", "https://www.operationspark.org")
+
+		handler.ServeHTTP(res, req)
+
+		require.True(t, called)
+		require.Equal(t, http.StatusCreated, res.Code)
+		require.Equal(t, "https://www.operationspark.org", res.Header().Get("Access-Control-Allow-Origin"))
+		require.Empty(t, res.Header().Get("Access-Control-Allow-Credentials"))
+	})
+
+	t.Run("rejects preflights from other origins", func(t *testing.T) {
+		for _, origin := range []string{"https://evil.example", "https://opspark.org.evil.example", "http://www.operationspark.org"} {
+			res := httptest.NewRecorder()
+
+			handler.ServeHTTP(res, preflight(origin, "POST", ""))
+
+			require.Equal(t, http.StatusForbidden, res.Code, origin)
+			require.Empty(t, res.Header().Get("Access-Control-Allow-Origin"), origin)
+		}
+	})
+
+	t.Run("does not add CORS headers for other origins", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("Origin", "https://evil.example")
+
+		handler.ServeHTTP(res, req)
+
+		require.Empty(t, res.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("rejects preflights for methods and headers not allowed", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, preflight("https://www.operationspark.org", "DELETE", ""))
+		require.Equal(t, http.StatusForbidden, res.Code)
+
+		res = httptest.NewRecorder()
+		handler.ServeHTTP(res, preflight("https://www.operationspark.org", "POST", "X-Custom-Header"))
+		require.Equal(t, http.StatusForbidden, res.Code)
+	})
+
+	t.Run("echoes the origin when credentials are allowed", func(t *testing.T) {
+		h := newCORS(corsOptions{allowedOrigins: []string{"*"}, allowCredentials: true}, next)
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("Origin", "https://anywhere.example")
+
+		h.ServeHTTP(res, req)
+
+		require.Equal(t, "https://anywhere.example", res.Header().Get("Access-Control-Allow-Origin"))
+		require.Equal(t, "true", res.Header().Get("Access-Control-Allow-Credentials"))
+	})
+
+	t.Run("passes same-origin requests through", func(t *testing.T) {
+		called = false
+		res := httptest.NewRecorder()
+
+		handler.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/", nil))
+
+		require.True(t, called)
+		require.Empty(t, res.Header().Get("Vary"))
+	})
+}
@@ -0,0 +1,148 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type (
+	// CaptureSender saves emails to a directory instead of sending them, and serves them over HTTP for local development.
+	// Each email is saved as [messageID].eml, [messageID].html, and [messageID].json.
+	CaptureSender struct {
+		dir string
+	}
+
+	// CapturedEmail is the metadata saved for each captured email.
+	CapturedEmail struct {
+		MessageID string    `json:"messageId"`
+		From      string    `json:"from"`
+		To        string    `json:"to"`
+		Subject   string    `json:"subject"`
+		Template  string    `json:"template"`
+		SentAt    time.Time `json:"sentAt"`
+	}
+)
+
+var captureIndex = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Captured Emails</title></head>
+<body>
+<h1>Captured Emails</h1>
+{{if not .}}<p>No emails yet. Sign up to send one.</p>{{end}}
+<ul>
+{{range .}}<li><a href="?id={{.MessageID}}">{{.Subject}}</a> to {{.To}} ({{.Template}}) {{.SentAt.Format "Jan 02 15:04:05"}}</li>
+{{end}}</ul>
+</body>
+</html>
+`))
+
+// NewCaptureSender saves emails to the given directory, creating it if needed.
+func NewCaptureSender(dir string) (*CaptureSender, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("mkdirAll: %w", err)
+	}
+	return &CaptureSender{dir: dir}, nil
+}
+
+func (c *CaptureSender) Send(ctx context.Context, msg Message) (string, error) {
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+
+	html, err := RenderHTML(msg)
+	if err != nil {
+		return "", fmt.Errorf("renderHTML: %w", err)
+	}
+
+	id, err := NewMessageID(msg.From)
+	if err != nil {
+		return "", fmt.Errorf("newMessageID: %w", err)
+	}
+
+	eml, err := buildMIME(msg, id, html)
+	if err != nil {
+		return "", fmt.Errorf("buildMIME: %w", err)
+	}
+
+	meta, err := json.MarshalIndent(CapturedEmail{
+		MessageID: id,
+		From:      msg.From,
+		To:        msg.To,
+		Subject:   msg.Subject,
+		Template:  msg.Template,
+		SentAt:    time.Now(),
+	}, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal: %w", err)
+	}
+
+	for ext, data := range map[string][]byte{".eml": eml, ".html": []byte(html), ".json": meta} {
+		if err := os.WriteFile(filepath.Join(c.dir, id+ext), data, 0o644); err != nil {
+			return "", fmt.Errorf("writeFile: %w", err)
+		}
+	}
+	return id, nil
+}
+
+// List returns the captured emails, newest first.
+func (c *CaptureSender) List() ([]CapturedEmail, error) {
+	paths, err := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("glob: %w", err)
+	}
+
+	emails := make([]CapturedEmail, 0, len(paths))
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("readFile: %w", err)
+		}
+		var e CapturedEmail
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("unmarshal %q: %w", p, err)
+		}
+		emails = append(emails, e)
+	}
+
+	sort.Slice(emails, func(i, j int) bool { return emails[i].SentAt.After(emails[j].SentAt) })
+	return emails, nil
+}
+
+// ServeHTTP lists the captured emails, or renders a single email with the "id" query parameter.
+func (c *CaptureSender) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id != "" {
+		// Message IDs are generated by NewMessageID, so anything with a path separator is not ours.
+		if strings.ContainsAny(id, `/\`) {
+			http.NotFound(w, r)
+			return
+		}
+		html, err := os.ReadFile(filepath.Join(c.dir, id+".html"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(html)
+		return
+	}
+
+	emails, err := c.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := captureIndex.Execute(w, emails); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
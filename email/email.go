@@ -0,0 +1,75 @@
+// Package email sends participant emails through a pluggable backend (Mailgun, SMTP, or local capture) and tracks their delivery.
+package email
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+type (
+	// Status is the latest known delivery state of an email.
+	Status string
+
+	// Delivery is an email sent to someone who signed up, stored with enough of the signup to follow up if the email can not be delivered.
+	Delivery struct {
+		// Provider message ID without angle brackets. Ex: "20221031170000.1.ABC123@mail.operationspark.org"
+		MessageID string `bson:"messageId"`
+		Email     string `bson:"email"`
+		NameFirst string `bson:"nameFirst"`
+		NameLast  string `bson:"nameLast"`
+		Cell      string `bson:"cell"`
+		SMSOptIn  bool   `bson:"smsOptIn"`
+		Language  string `bson:"language"`
+		SessionID string `bson:"sessionId"`
+		// Greenlight signup ID. Empty for emails sent before it was recorded.
+		SignupID string `bson:"signupId,omitempty"`
+		// Info session start time. Zero if the person did not pick a session.
+		StartDateTime time.Time `bson:"startDateTime"`
+		// Info session details short link.
+		ShortLink string `bson:"shortLink"`
+		Status    Status `bson:"status"`
+		// True once the email address has hard bounced.
+		EmailInvalid bool      `bson:"emailInvalid"`
+		CreatedAt    time.Time `bson:"createdAt"`
+		UpdatedAt    time.Time `bson:"updatedAt"`
+	}
+
+	// Event is a delivery event reported by the email provider.
+	Event struct {
+		MessageID string
+		Status    Status
+		Recipient string
+		// Provider's reason for a failure. Ex: "bounce", "suppress-bounce"
+		Reason    string
+		Timestamp time.Time
+	}
+
+	// Store saves deliveries and their events.
+	Store interface {
+		SaveDelivery(ctx context.Context, d Delivery) error
+		// RecordEvent updates the delivery's status and returns the updated delivery. Returns ErrDeliveryNotFound for unknown message IDs.
+		RecordEvent(ctx context.Context, e Event) (Delivery, error)
+		// MarkEmailInvalid flags every delivery to the delivery's email address, and the delivery's signup, as undeliverable.
+		MarkEmailInvalid(ctx context.Context, d Delivery) error
+	}
+)
+
+const (
+	StatusSent       Status = "sent"
+	StatusDelivered  Status = "delivered"
+	StatusOpened     Status = "opened"
+	StatusClicked    Status = "clicked"
+	StatusFailed     Status = "failed"  // Temporary failure. The provider retries.
+	StatusBounced    Status = "bounced" // Permanent failure. The address can not receive email.
+	StatusComplained Status = "complained"
+)
+
+// ErrDeliveryNotFound is returned when an event refers to a message that was not sent by this service.
+var ErrDeliveryNotFound = errors.New("delivery not found")
+
+// NormalizeMessageID strips the angle brackets the provider adds to message IDs when sending. Webhook events report the ID without them.
+func NormalizeMessageID(id string) string {
+	return strings.Trim(strings.TrimSpace(id), "<>")
+}
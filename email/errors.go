@@ -0,0 +1,62 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/operationspark/service-signup/logging"
+)
+
+type errorResponse struct {
+	Error any `json:"error"`
+}
+
+// logError logs the error to the server's logger and Sentry if it's enabled.
+func (s *WebhookServer) logError(ctx context.Context, err error) {
+	logging.Error(ctx, s.logger, err)
+}
+
+// logRequestError logs the error with the request method and URL.
+// It also logs the error to Sentry if it's enabled.
+func (s *WebhookServer) logRequestError(ctx context.Context, r *http.Request, err error) {
+	logging.Error(ctx, s.logger, err,
+		slog.String("method", r.Method),
+		slog.String("url", r.URL.String()))
+}
+
+// errorResponse writes an error response to the client. The msg is sent as the error message in the response body,
+// so it should be a human-readable message and not leak any sensitive information.
+func (s *WebhookServer) errorResponse(w http.ResponseWriter, r *http.Request, status int, msg any) {
+	err := s.writeJSON(w, status, errorResponse{Error: msg})
+	if err != nil {
+		s.logRequestError(r.Context(), r, err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// serverErrorResponse logs the error and sends a generic 500 Internal Server Error response to the client.
+func (s *WebhookServer) serverErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	s.logRequestError(r.Context(), r, err)
+	s.errorResponse(w, r, http.StatusInternalServerError, "internal server error")
+}
+
+// badRequestResponse logs the error and sends a 400 Bad Request response to the client.
+func (s *WebhookServer) badRequestResponse(w http.ResponseWriter, r *http.Request, msg string) {
+	s.logRequestError(r.Context(), r, fmt.Errorf("bad request: %s", msg))
+	s.errorResponse(w, r, http.StatusBadRequest, msg)
+}
+
+// unauthorizedResponse logs the error and sends a 401 Unauthorized response to the client.
+func (s *WebhookServer) unauthorizedResponse(w http.ResponseWriter, r *http.Request, err error) {
+	s.logRequestError(r.Context(), r, err)
+	s.errorResponse(w, r, http.StatusUnauthorized, "invalid signature")
+}
+
+func (s *WebhookServer) writeJSON(w http.ResponseWriter, status int, data any) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(data)
+}
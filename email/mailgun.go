@@ -0,0 +1,60 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mailgun/mailgun-go/v4"
+)
+
+// MailgunSender sends emails with Mailgun hosted templates.
+type MailgunSender struct {
+	client *mailgun.MailgunImpl
+}
+
+// NewMailgunSender creates a Mailgun sender. The apiBase overrides the Mailgun API URL if not empty.
+func NewMailgunSender(domain, apiKey, apiBase string) *MailgunSender {
+	client := mailgun.NewMailgun(domain, apiKey)
+	if len(apiBase) > 0 {
+		client.SetAPIBase(apiBase)
+	}
+	return &MailgunSender{client: client}
+}
+
+// Send sends the message with a 10 second timeout.
+func (m *MailgunSender) Send(ctx context.Context, msg Message) (string, error) {
+	// Empty body because we're using a template
+	message := m.client.NewMessage(msg.From, msg.Subject, "", msg.To)
+	message.SetTemplate(msg.Template)
+	if len(msg.TemplateVersion) > 0 {
+		message.SetTemplateVersion(msg.TemplateVersion)
+	}
+	for k, v := range msg.Variables {
+		err := message.AddTemplateVariable(k, v)
+		if err != nil {
+			return "", fmt.Errorf("add template variable: %w ", err)
+		}
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	_, id, err := m.client.Send(ctxWithTimeout, message)
+	if err != nil {
+		return "", fmt.Errorf("send: %w", err)
+	}
+	return NormalizeMessageID(id), nil
+}
+
+// CheckDomain returns an error if the sending domain does not exist or is not active in Mailgun.
+func (m *MailgunSender) CheckDomain(ctx context.Context) error {
+	resp, err := m.client.GetDomain(ctx, m.client.Domain())
+	if err != nil {
+		return fmt.Errorf("getDomain: %w", err)
+	}
+	if resp.Domain.State != "active" {
+		return fmt.Errorf("domain %q is %s", resp.Domain.Name, resp.Domain.State)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"path"
+	"strings"
+	"time"
+)
+
+type (
+	// Message is a templated email. Mailgun renders its hosted template with the same name. The SMTP and capture backends render our own copy from the templates/ directory.
+	Message struct {
+		From    string
+		To      string
+		Subject string
+		// Template name. Translated templates are suffixed with the language code. Ex: "info-session-signup-es"
+		Template string
+		// Mailgun template version. If not set, the active version is used. Ignored by other backends.
+		TemplateVersion string
+		Variables       map[string]any
+	}
+
+	// Sender sends an email and returns its message ID without angle brackets.
+	Sender interface {
+		Send(ctx context.Context, msg Message) (string, error)
+	}
+)
+
+//go:embed templates/*.html
+var embeddedTemplates embed.FS
+
+// Parsed once. Each file is a template named after the file without the extension.
+var htmlTemplates = template.Must(parseTemplates(embeddedTemplates))
+
+func parseTemplates(fsys fs.FS) (*template.Template, error) {
+	paths, err := fs.Glob(fsys, "templates/*.html")
+	if err != nil {
+		return nil, fmt.Errorf("glob: %w", err)
+	}
+
+	root := template.New("")
+	for _, p := range paths {
+		body, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return nil, fmt.Errorf("readFile %q: %w", p, err)
+		}
+		name := strings.TrimSuffix(path.Base(p), ".html")
+		if _, err := root.New(name).Parse(string(body)); err != nil {
+			return nil, fmt.Errorf("parse %q: %w", p, err)
+		}
+	}
+	return root, nil
+}
+
+// RenderHTML renders the message's template. Translated templates that don't exist fall back to the untranslated template. Ex: "info-session-signup-hybrid-es" -> "info-session-signup-hybrid"
+func RenderHTML(msg Message) (string, error) {
+	t := htmlTemplates.Lookup(msg.Template)
+	if t == nil {
+		if i := strings.LastIndex(msg.Template, "-"); i > 0 {
+			t = htmlTemplates.Lookup(msg.Template[:i])
+		}
+	}
+	if t == nil {
+		return "", fmt.Errorf("email template %q not found", msg.Template)
+	}
+
+	var b bytes.Buffer
+	if err := t.Execute(&b, msg.Variables); err != nil {
+		return "", fmt.Errorf("execute %q: %w", msg.Template, err)
+	}
+	return b.String(), nil
+}
+
+// NewMessageID creates a unique RFC 5322 message ID for the sender's domain, without angle brackets.
+func NewMessageID(from string) (string, error) {
+	domain := "localhost"
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if i := strings.LastIndex(addr.Address, "@"); i >= 0 {
+			domain = addr.Address[i+1:]
+		}
+	}
+
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("rand: %w", err)
+	}
+	return fmt.Sprintf("%s.%s@%s", time.Now().UTC().Format("20060102150405"), hex.EncodeToString(b), domain), nil
+}
+
+// BuildMIME creates a single-part HTML email ready to send over SMTP or save as an .eml file.
+func buildMIME(msg Message, messageID, html string) ([]byte, error) {
+	var b bytes.Buffer
+	headers := []struct{ key, val string }{
+		{"From", msg.From},
+		{"To", msg.To},
+		{"Subject", mime.QEncoding.Encode("utf-8", msg.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", "<" + messageID + ">"},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", `text/html; charset="utf-8"`},
+		{"Content-Transfer-Encoding", "quoted-printable"},
+	}
+	for _, h := range headers {
+		fmt.Fprintf(&b, "%s: %s\r\n", h.key, h.val)
+	}
+	b.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(&b)
+	if _, err := qp.Write([]byte(html)); err != nil {
+		return nil, fmt.Errorf("write body: %w", err)
+	}
+	if err := qp.Close(); err != nil {
+		return nil, fmt.Errorf("close body: %w", err)
+	}
+	return b.Bytes(), nil
+}
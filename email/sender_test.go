@@ -0,0 +1,121 @@
+package email
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testMessage(template string) Message {
+	return Message{
+		From:     "Operation Spark <admissions@mail.operationspark.org>",
+		To:       "henri@email.com",
+		Subject:  "¡Te damos la bienvenida a Operation Spark!",
+		Template: template,
+		Variables: map[string]any{
+			"firstName":   "Henri",
+			"sessionDate": "Monday, Oct 31",
+			"sessionTime": "12:00 PM CDT",
+			"zoomURL":     "https://us06web.zoom.us/j/123",
+			"joinCode":    "tlav",
+		},
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	t.Run("renders every welcome template", func(t *testing.T) {
+		for _, name := range []string{
+			"info-session-signup",
+			"info-session-signup-es",
+			"info-session-signup-hybrid",
+			"info-session-signup-hybrid-es",
+		} {
+			got, err := RenderHTML(testMessage(name))
+			require.NoError(t, err, name)
+			require.Contains(t, got, "Henri", name)
+			require.Contains(t, got, "https://us06web.zoom.us/j/123", name)
+			require.Contains(t, got, "tlav", name)
+		}
+	})
+
+	t.Run("falls back to the untranslated template", func(t *testing.T) {
+		got, err := RenderHTML(testMessage("info-session-signup-fr"))
+		require.NoError(t, err)
+		require.Contains(t, got, "Hi Henri")
+	})
+
+	t.Run("escapes variables", func(t *testing.T) {
+		msg := testMessage("info-session-signup")
+		msg.Variables["firstName"] = "<script>alert(1)</script>"
+		got, err := RenderHTML(msg)
+		require.NoError(t, err)
+		require.NotContains(t, got, "<script>")
+	})
+
+	t.Run("fails for unknown templates", func(t *testing.T) {
+		_, err := RenderHTML(testMessage("unknown"))
+		require.Error(t, err)
+	})
+}
+
+func TestSMTPSender(t *testing.T) {
+	s := NewSMTPSender(SMTPOptions{Host: "smtp.example.com", Username: "user", Password: "pass"})
+
+	var gotAddr, gotFrom string
+	var gotTo []string
+	var gotBody []byte
+	s.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotFrom, gotTo, gotBody = addr, from, to, msg
+		return nil
+	}
+
+	id, err := s.Send(context.Background(), testMessage("info-session-signup"))
+	require.NoError(t, err)
+	require.True(t, strings.HasSuffix(id, "@mail.operationspark.org"), id)
+
+	require.Equal(t, "smtp.example.com:587", gotAddr)
+	require.Equal(t, "admissions@mail.operationspark.org", gotFrom)
+	require.Equal(t, []string{"henri@email.com"}, gotTo)
+	require.Contains(t, string(gotBody), "Message-ID: <"+id+">")
+	require.Contains(t, string(gotBody), "Subject: =?utf-8?q?")
+	require.Contains(t, string(gotBody), "Hi Henri")
+}
+
+func TestCaptureSender(t *testing.T) {
+	c, err := NewCaptureSender(t.TempDir())
+	require.NoError(t, err)
+
+	id, err := c.Send(context.Background(), testMessage("info-session-signup-es"))
+	require.NoError(t, err)
+
+	emails, err := c.List()
+	require.NoError(t, err)
+	require.Len(t, emails, 1)
+	require.Equal(t, id, emails[0].MessageID)
+	require.Equal(t, "henri@email.com", emails[0].To)
+
+	t.Run("lists captured emails", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		c.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/dev/emails", nil))
+		require.Equal(t, http.StatusOK, res.Code)
+		require.Contains(t, res.Body.String(), "¡Te damos la bienvenida a Operation Spark!")
+	})
+
+	t.Run("serves a captured email", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		c.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/dev/emails?id="+id, nil))
+		require.Equal(t, http.StatusOK, res.Code)
+		require.Contains(t, res.Body.String(), "Hola Henri")
+	})
+
+	t.Run("does not serve files outside the capture directory", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		c.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/dev/emails?id=../secrets", nil))
+		require.Equal(t, http.StatusNotFound, res.Code)
+	})
+}
@@ -0,0 +1,81 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+)
+
+type (
+	SMTPOptions struct {
+		Host string
+		// Defaults to 587.
+		Port     int
+		Username string
+		Password string
+	}
+
+	// SMTPSender renders our own HTML templates and sends them through any SMTP server.
+	SMTPSender struct {
+		addr string
+		auth smtp.Auth
+		// smtp.SendMail. Replaced in tests.
+		sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+	}
+)
+
+func NewSMTPSender(o SMTPOptions) *SMTPSender {
+	port := o.Port
+	if port == 0 {
+		port = 587
+	}
+
+	var auth smtp.Auth
+	if o.Username != "" {
+		auth = smtp.PlainAuth("", o.Username, o.Password, o.Host)
+	}
+
+	return &SMTPSender{
+		addr:     net.JoinHostPort(o.Host, strconv.Itoa(port)),
+		auth:     auth,
+		sendMail: smtp.SendMail,
+	}
+}
+
+func (s *SMTPSender) Send(ctx context.Context, msg Message) (string, error) {
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+
+	html, err := RenderHTML(msg)
+	if err != nil {
+		return "", fmt.Errorf("renderHTML: %w", err)
+	}
+
+	id, err := NewMessageID(msg.From)
+	if err != nil {
+		return "", fmt.Errorf("newMessageID: %w", err)
+	}
+
+	body, err := buildMIME(msg, id, html)
+	if err != nil {
+		return "", fmt.Errorf("buildMIME: %w", err)
+	}
+
+	from, err := mail.ParseAddress(msg.From)
+	if err != nil {
+		return "", fmt.Errorf("parse from address: %w", err)
+	}
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return "", fmt.Errorf("parse to address: %w", err)
+	}
+
+	if err := s.sendMail(s.addr, s.auth, from.Address, []string{to.Address}, body); err != nil {
+		return "", fmt.Errorf("sendMail: %w", err)
+	}
+	return id, nil
+}
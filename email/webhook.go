@@ -0,0 +1,237 @@
+package email
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mailgun/mailgun-go/v4"
+	"github.com/mailgun/mailgun-go/v4/events"
+	"github.com/operationspark/service-signup/i18n"
+	"github.com/operationspark/service-signup/signing"
+	"github.com/operationspark/service-signup/templates"
+)
+
+type (
+	// Notifier posts a message for staff. Ex: Slack #signups channel.
+	Notifier interface {
+		Notify(ctx context.Context, text string) error
+	}
+
+	SMSSender interface {
+		Send(ctx context.Context, toNum, msg string) error
+		FormatCell(string) string
+	}
+
+	WebhookOpts struct {
+		// Mailgun HTTP webhook signing key. Found in the Mailgun dashboard under Sending > Webhooks.
+		SigningKey string
+		Store      Store
+		// Notified when an email hard bounces. Optional.
+		Notifier Notifier
+		// Sends the SMS fallback when an email hard bounces. Optional.
+		SMSService SMSSender
+		// SMS message templates. Defaults to the embedded templates.
+		Templates *templates.Registry
+		Logger    *slog.Logger
+	}
+
+	// WebhookServer handles Mailgun delivery event webhooks.
+	// https://documentation.mailgun.com/en/latest/user_manual.html#webhooks-1
+	WebhookServer struct {
+		signingKey string
+		store      Store
+		notifier   Notifier
+		sms        SMSSender
+		templates  *templates.Registry
+		logger     *slog.Logger
+	}
+)
+
+// Webhooks with a timestamp older than this are rejected to prevent replay attacks.
+const maxSignatureAge = 5 * time.Minute
+
+func NewWebhookServer(o WebhookOpts) *WebhookServer {
+	logger := o.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &WebhookServer{
+		signingKey: o.SigningKey,
+		store:      o.Store,
+		notifier:   o.Notifier,
+		sms:        o.SMSService,
+		templates:  o.Templates,
+		logger:     logger.With("service", "email-webhook"),
+	}
+}
+
+func (s *WebhookServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	defer func() { _ = r.Body.Close() }()
+
+	if r.Method != http.MethodPost {
+		s.errorResponse(w, r, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var payload mailgun.WebhookPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		s.badRequestResponse(w, r, fmt.Errorf("invalid JSON body: %w", err).Error())
+		return
+	}
+
+	if err := s.verifySignature(payload.Signature, time.Now()); err != nil {
+		s.unauthorizedResponse(w, r, fmt.Errorf("verifySignature: %w", err))
+		return
+	}
+
+	raw, err := mailgun.ParseEvent(payload.EventData)
+	if err != nil {
+		// Mailgun retries non-2xx responses, so acknowledge events we don't track.
+		s.logger.InfoContext(r.Context(), "ignoring email event", slog.String("error", err.Error()))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	e, ok := toEvent(raw)
+	if !ok {
+		s.logger.InfoContext(r.Context(), "ignoring email event", slog.String("event", raw.GetName()))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	d, err := s.store.RecordEvent(r.Context(), e)
+	if errors.Is(err, ErrDeliveryNotFound) {
+		s.logger.InfoContext(r.Context(), "ignoring event for unknown message", slog.String("messageId", e.MessageID))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		s.serverErrorResponse(w, r, fmt.Errorf("recordEvent: %w", err))
+		return
+	}
+
+	if e.Status == StatusBounced {
+		if err := s.handleBounce(r.Context(), d, e); err != nil {
+			s.serverErrorResponse(w, r, fmt.Errorf("handleBounce: %w", err))
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// VerifySignature checks the webhook was sent by Mailgun. The signature is the hex-encoded HMAC-SHA256 of the timestamp and token, signed with the webhook signing key.
+func (s *WebhookServer) verifySignature(sig mailgun.Signature, now time.Time) error {
+	if s.signingKey == "" {
+		return errors.New("webhook signing key is not configured")
+	}
+
+	ts, err := strconv.ParseInt(sig.TimeStamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("parse timestamp: %w", err)
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > maxSignatureAge || age < -maxSignatureAge {
+		return fmt.Errorf("stale timestamp: %s", sig.TimeStamp)
+	}
+
+	want, err := signing.Sign([]byte(sig.TimeStamp+sig.Token), []byte(s.signingKey), crypto.SHA256, signing.EncodingHex)
+	if err != nil {
+		return fmt.Errorf("sign: %w", err)
+	}
+	if !hmac.Equal(want, []byte("sha256="+sig.Signature)) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+// HandleBounce flags the email address and the signup as invalid, lets staff know, and texts the info session details if the person opted in to SMS.
+// Notification failures are logged instead of returned so Mailgun does not retry the webhook and send duplicates.
+func (s *WebhookServer) handleBounce(ctx context.Context, d Delivery, e Event) error {
+	if err := s.store.MarkEmailInvalid(ctx, d); err != nil {
+		return fmt.Errorf("markEmailInvalid: %w", err)
+	}
+
+	smsSent := false
+	if d.SMSOptIn && d.Cell != "" && s.sms != nil {
+		if err := s.sendSMSFallback(ctx, d); err != nil {
+			s.logError(ctx, fmt.Errorf("sendSMSFallback: %w", err))
+		} else {
+			smsSent = true
+		}
+	}
+
+	if s.notifier != nil {
+		if err := s.notifier.Notify(ctx, bounceSummary(d, e, smsSent)); err != nil {
+			s.logError(ctx, fmt.Errorf("notify: %w", err))
+		}
+	}
+	return nil
+}
+
+func (s *WebhookServer) sendSMSFallback(ctx context.Context, d Delivery) error {
+	ctz, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		return fmt.Errorf("loadLocation: %w", err)
+	}
+
+	msg, err := s.templates.Render(templates.EmailBounced, i18n.Parse(d.Language), ctz, templates.SignupData{
+		NameFirst:     d.NameFirst,
+		StartDateTime: d.StartDateTime,
+		URL:           d.ShortLink,
+	})
+	if err != nil {
+		return fmt.Errorf("render: %w", err)
+	}
+	return s.sms.Send(ctx, s.sms.FormatCell(d.Cell), msg)
+}
+
+// BounceSummary creates the staff notification for a hard bounce.
+func bounceSummary(d Delivery, e Event, smsSent bool) string {
+	fallback := "No SMS fallback (opted out or no cell)."
+	if smsSent {
+		fallback = "Sent the info session link by SMS instead."
+	}
+	return fmt.Sprintf(
+		"Welcome email bounced for %s %s <%s> (%s).\n%s",
+		d.NameFirst,
+		d.NameLast,
+		d.Email,
+		e.Reason,
+		fallback,
+	)
+}
+
+// ToEvent converts a Mailgun event to a delivery Event. Returns false for events that are not tracked.
+func toEvent(raw mailgun.Event) (Event, bool) {
+	var e Event
+	switch ev := raw.(type) {
+	case *events.Delivered:
+		e = Event{MessageID: ev.Message.Headers.MessageID, Status: StatusDelivered, Recipient: ev.Recipient}
+	case *events.Opened:
+		e = Event{MessageID: ev.Message.Headers.MessageID, Status: StatusOpened, Recipient: ev.Recipient}
+	case *events.Clicked:
+		e = Event{MessageID: ev.Message.Headers.MessageID, Status: StatusClicked, Recipient: ev.Recipient}
+	case *events.Complained:
+		e = Event{MessageID: ev.Message.Headers.MessageID, Status: StatusComplained, Recipient: ev.Recipient}
+	case *events.Failed:
+		e = Event{MessageID: ev.Message.Headers.MessageID, Status: StatusFailed, Recipient: ev.Recipient, Reason: ev.Reason}
+		// Permanent failures are hard bounces. Mailgun retries temporary failures.
+		if ev.Severity == "permanent" {
+			e.Status = StatusBounced
+		}
+	default:
+		return Event{}, false
+	}
+
+	e.MessageID = NormalizeMessageID(e.MessageID)
+	e.Timestamp = raw.GetTimestamp()
+	return e, true
+}
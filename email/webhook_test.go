@@ -0,0 +1,199 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/operationspark/service-signup/signing"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	mockStore struct {
+		deliveries map[string]Delivery
+		invalid    []string
+	}
+
+	mockNotifier struct {
+		messages []string
+	}
+
+	mockSMSService struct {
+		sent map[string]string
+	}
+)
+
+func (m *mockStore) SaveDelivery(ctx context.Context, d Delivery) error {
+	m.deliveries[d.MessageID] = d
+	return nil
+}
+
+func (m *mockStore) RecordEvent(ctx context.Context, e Event) (Delivery, error) {
+	d, ok := m.deliveries[e.MessageID]
+	if !ok {
+		return Delivery{}, ErrDeliveryNotFound
+	}
+	d.Status = e.Status
+	m.deliveries[e.MessageID] = d
+	return d, nil
+}
+
+func (m *mockStore) MarkEmailInvalid(ctx context.Context, d Delivery) error {
+	m.invalid = append(m.invalid, d.Email+" "+d.SignupID)
+	return nil
+}
+
+func (m *mockNotifier) Notify(ctx context.Context, text string) error {
+	m.messages = append(m.messages, text)
+	return nil
+}
+
+func (m *mockSMSService) Send(ctx context.Context, toNum, msg string) error {
+	m.sent[toNum] = msg
+	return nil
+}
+
+func (m *mockSMSService) FormatCell(cell string) string {
+	return "+1" + strings.ReplaceAll(cell, "-", "")
+}
+
+const testSigningKey = "test-signing-key"
+
+func TestWebhookServer(t *testing.T) {
+	messageID := "20221031170000.1.ABC123@mail.operationspark.org"
+
+	newServer := func() (*WebhookServer, *mockStore, *mockNotifier, *mockSMSService) {
+		store := &mockStore{deliveries: map[string]Delivery{
+			messageID: {
+				MessageID: messageID,
+				SignupID:  "gl-123",
+				Email:     "henri@email.com",
+				NameFirst: "Henri",
+				NameLast:  "Testaroni",
+				Cell:      "555-123-4567",
+				SMSOptIn:  true,
+				ShortLink: "https://oprk.org/kRds5MKvKI",
+				Status:    StatusSent,
+			},
+		}}
+		notifier := &mockNotifier{}
+		smsSvc := &mockSMSService{sent: map[string]string{}}
+		s := NewWebhookServer(WebhookOpts{
+			SigningKey: testSigningKey,
+			Store:      store,
+			Notifier:   notifier,
+			SMSService: smsSvc,
+		})
+		return s, store, notifier, smsSvc
+	}
+
+	t.Run("records delivery events", func(t *testing.T) {
+		s, store, notifier, _ := newServer()
+
+		res := httptest.NewRecorder()
+		s.ServeHTTP(res, webhookRequest(t, testSigningKey, map[string]any{
+			"event":   "delivered",
+			"message": map[string]any{"headers": map[string]any{"message-id": messageID}},
+		}))
+
+		require.Equal(t, http.StatusNoContent, res.Code, res.Body.String())
+		require.Equal(t, StatusDelivered, store.deliveries[messageID].Status)
+		require.Empty(t, notifier.messages)
+	})
+
+	t.Run("flags the email, notifies Slack, and sends an SMS on hard bounce", func(t *testing.T) {
+		s, store, notifier, smsSvc := newServer()
+
+		res := httptest.NewRecorder()
+		s.ServeHTTP(res, webhookRequest(t, testSigningKey, map[string]any{
+			"event":    "failed",
+			"severity": "permanent",
+			"reason":   "bounce",
+			"message":  map[string]any{"headers": map[string]any{"message-id": messageID}},
+		}))
+
+		require.Equal(t, http.StatusNoContent, res.Code, res.Body.String())
+		require.Equal(t, StatusBounced, store.deliveries[messageID].Status)
+		require.Equal(t, []string{"henri@email.com gl-123"}, store.invalid)
+		require.Len(t, notifier.messages, 1)
+		require.Contains(t, notifier.messages[0], "henri@email.com")
+		require.Contains(t, smsSvc.sent["+15551234567"], "https://oprk.org/kRds5MKvKI")
+	})
+
+	t.Run("does not flag the email on temporary failures", func(t *testing.T) {
+		s, store, _, smsSvc := newServer()
+
+		res := httptest.NewRecorder()
+		s.ServeHTTP(res, webhookRequest(t, testSigningKey, map[string]any{
+			"event":    "failed",
+			"severity": "temporary",
+			"message":  map[string]any{"headers": map[string]any{"message-id": messageID}},
+		}))
+
+		require.Equal(t, http.StatusNoContent, res.Code, res.Body.String())
+		require.Equal(t, StatusFailed, store.deliveries[messageID].Status)
+		require.Empty(t, store.invalid)
+		require.Empty(t, smsSvc.sent)
+	})
+
+	t.Run("rejects invalid signatures", func(t *testing.T) {
+		s, store, _, _ := newServer()
+
+		res := httptest.NewRecorder()
+		s.ServeHTTP(res, webhookRequest(t, "wrong-key", map[string]any{
+			"event":   "delivered",
+			"message": map[string]any{"headers": map[string]any{"message-id": messageID}},
+		}))
+
+		require.Equal(t, http.StatusUnauthorized, res.Code)
+		require.Equal(t, StatusSent, store.deliveries[messageID].Status)
+	})
+
+	t.Run("acknowledges events for unknown messages", func(t *testing.T) {
+		s, _, _, _ := newServer()
+
+		res := httptest.NewRecorder()
+		s.ServeHTTP(res, webhookRequest(t, testSigningKey, map[string]any{
+			"event":   "opened",
+			"message": map[string]any{"headers": map[string]any{"message-id": "unknown@mail.operationspark.org"}},
+		}))
+
+		require.Equal(t, http.StatusNoContent, res.Code)
+	})
+}
+
+func TestNormalizeMessageID(t *testing.T) {
+	require.Equal(t, "abc@mail.operationspark.org", NormalizeMessageID("<abc@mail.operationspark.org>"))
+	require.Equal(t, "abc@mail.operationspark.org", NormalizeMessageID("abc@mail.operationspark.org"))
+}
+
+// WebhookRequest creates a Mailgun webhook request signed with the given key.
+func webhookRequest(t *testing.T, key string, eventData map[string]any) *http.Request {
+	t.Helper()
+
+	timestamp := fmt.Sprint(time.Now().Unix())
+	token := "a8ce0edb2dd8301dee6c2405235584e45aa91d1e9f979f3de0"
+	sig, err := signing.Sign([]byte(timestamp+token), []byte(key), crypto.SHA256, signing.EncodingHex)
+	require.NoError(t, err)
+
+	eventData["timestamp"] = float64(time.Now().Unix())
+	body, err := json.Marshal(map[string]any{
+		"signature": map[string]any{
+			"timestamp": timestamp,
+			"token":     token,
+			"signature": strings.TrimPrefix(string(sig), "sha256="),
+		},
+		"event-data": eventData,
+	})
+	require.NoError(t, err)
+
+	return httptest.NewRequest(http.MethodPost, "/webhooks/mailgun", bytes.NewReader(body))
+}
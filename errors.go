@@ -2,13 +2,19 @@ package signup
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 
-	"github.com/getsentry/sentry-go"
+	"github.com/mailgun/mailgun-go/v4"
+	"github.com/operationspark/service-signup/logging"
+	"github.com/twilio/twilio-go/client"
 )
 
 type (
@@ -19,51 +25,232 @@ type (
 	InvalidFieldError struct {
 		Field string
 	}
+
+	// HTTPError is an error response from an integration.
+	HTTPError struct {
+		// Integration that sent the response. Ex: "zoom".
+		Vendor     string
+		Method     string
+		URL        string
+		StatusCode int
+		// Status code and text. Ex: "404 Not Found".
+		Status string
+		// Parsed JSON response body. Nil if the body is not a JSON object.
+		Body map[string]any
+		// Response body, unless it is HTML.
+		RawBody string
+		// Vendor's error code and message from the JSON body, if any. Ex: Twilio's "50407".
+		Code    string
+		Message string
+
+		htmlBody bool
+	}
+
+	// VendorUserError matches an integration error caused by the signup's values to the response that tells the person what to fix.
+	vendorUserError struct {
+		vendor string
+		status int
+		// Vendor error code. Empty matches any code.
+		code string
+		// Lowercase text the vendor's error message contains. Empty matches any message.
+		contains string
+		resp     badReqBodyResp
+	}
 )
 
+// Error bodies longer than this are cut off.
+const maxErrorBodyBytes = 64 << 10
+
+// VendorUserErrors are the integration errors HandleSignUp reports to the person instead of failing with a 500.
+var vendorUserErrors = []vendorUserError{
+	{
+		// Zoom limits each email to 3 registrations per meeting per day.
+		vendor:   "zoom",
+		status:   http.StatusTooManyRequests,
+		contains: "for the registrant",
+		resp:     badReqBodyResp{Message: "Already registered for this session", Field: "email"},
+	},
+	{
+		vendor:   "zoom",
+		status:   http.StatusBadRequest,
+		contains: "email",
+		resp:     badReqBodyResp{Message: "Invalid Email Address", Field: "email"},
+	},
+	{
+		vendor:   "mailgun",
+		status:   http.StatusBadRequest,
+		contains: "not a valid address",
+		resp:     badReqBodyResp{Message: "Invalid Email Address", Field: "email"},
+	},
+	{
+		vendor: "twilio",
+		status: http.StatusBadRequest,
+		code:   twilioInvalidPhoneCode,
+		resp:   badReqBodyResp{Message: "Invalid Phone Number", Field: "phone"},
+	},
+}
+
 func (e *InvalidFieldError) Error() string {
 	return fmt.Sprintf("invalid value for field: '%s'", e.Field)
 }
 
-// handleHTTPError parses and prints the response body. It expects the response body to contain JSON and returns an error if the body in HTML.
-func handleHTTPError(resp *http.Response) error {
-	reqLabel := fmt.Sprintf(
-		"%s: %s://%s\n%s\n",
-		resp.Request.Method,
-		resp.Request.URL.Scheme,
-		resp.Request.URL.Host,
-		resp.Request.URL.RequestURI(),
-	)
-
-	errMsg := fmt.Sprintf("HTTP Error:\n%s\nResponse:\n%s", reqLabel, resp.Status)
+// newHTTPError reads an error response from an integration. JSON bodies are parsed for the vendor's error code and message. HTML bodies are left out to avoid flooding the logs.
+func newHTTPError(vendor string, resp *http.Response) *HTTPError {
+	e := &HTTPError{
+		Vendor:     vendor,
+		Method:     resp.Request.Method,
+		URL:        resp.Request.URL.String(),
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+	}
 
-	// Ignore response body if it's HTML to avoid flooding the logs
-	isHTML := strings.Contains(resp.Header.Get("Content-Type"), "text/html")
-	if isHTML {
-		return fmt.Errorf("%s\n[HTML response removed]", errMsg)
+	if strings.Contains(resp.Header.Get("Content-Type"), "text/html") {
+		e.htmlBody = true
+		return e
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
 	if err != nil {
-		return fmt.Errorf("response code: %s", resp.Status)
+		return e
+	}
+	e.setBody(b)
+	return e
+}
+
+// SetBody keeps the raw body and, if it is a JSON object, the parsed body and the error code and message found in it.
+func (e *HTTPError) setBody(b []byte) {
+	e.RawBody = string(b)
+
+	var body map[string]any
+	if err := json.Unmarshal(b, &body); err != nil {
+		return
+	}
+	e.Body = body
+
+	switch code := body["code"].(type) {
+	case string:
+		e.Code = code
+	case float64:
+		e.Code = strconv.FormatFloat(code, 'f', -1, 64)
+	}
+	// Zoom, Mailgun, and Twilio use "message". Others use "error".
+	for _, key := range []string{"message", "error", "error_description"} {
+		if msg, ok := body[key].(string); ok {
+			e.Message = msg
+			return
+		}
+	}
+}
+
+func (e *HTTPError) Error() string {
+	header := "HTTP Error:"
+	if e.Vendor != "" {
+		header = fmt.Sprintf("%s HTTP Error:", e.Vendor)
+	}
+
+	reqLabel := e.URL
+	if u, err := url.Parse(e.URL); err == nil && u.Host != "" {
+		reqLabel = fmt.Sprintf("%s://%s\n%s", u.Scheme, u.Host, u.RequestURI())
+	}
+	if e.Method != "" {
+		reqLabel = fmt.Sprintf("%s: %s", e.Method, reqLabel)
 	}
 
-	return fmt.Errorf("%s\n%s", errMsg, body)
+	errMsg := fmt.Sprintf("%s\n%s\n\nResponse:\n%s", header, reqLabel, e.Status)
+	if e.htmlBody {
+		return fmt.Sprintf("%s\n[HTML response removed]", errMsg)
+	}
+	return fmt.Sprintf("%s\n%s", errMsg, e.RawBody)
+}
+
+// Retryable reports whether the request might succeed if sent again: rate limits, timeouts, and server errors. Errors caused by the signup's values (see vendorUserErrors) are never retryable, even if the vendor reports them as rate limits.
+func (e *HTTPError) Retryable() bool {
+	for _, ue := range vendorUserErrors {
+		if ue.matches(e) {
+			return false
+		}
+	}
+	return e.StatusCode == http.StatusTooManyRequests ||
+		e.StatusCode == http.StatusRequestTimeout ||
+		e.StatusCode >= 500
+}
+
+// TwilioError converts errors from the Twilio SDK to an HTTPError. Other errors are returned as is.
+func twilioError(err error) error {
+	var restErr *client.TwilioRestError
+	if !errors.As(err, &restErr) {
+		return err
+	}
+	e := &HTTPError{
+		Vendor:     "twilio",
+		StatusCode: restErr.Status,
+		Status:     fmt.Sprintf("%d %s", restErr.Status, http.StatusText(restErr.Status)),
+		Code:       strconv.Itoa(restErr.Code),
+		Message:    restErr.Message,
+		RawBody:    restErr.Error(),
+		Body: map[string]any{
+			"code":      restErr.Code,
+			"message":   restErr.Message,
+			"more_info": restErr.MoreInfo,
+			"details":   restErr.Details,
+		},
+	}
+	return e
+}
+
+// MailgunError converts errors from the Mailgun SDK to an HTTPError. Other errors are returned as is.
+func mailgunError(err error) error {
+	var respErr *mailgun.UnexpectedResponseError
+	if !errors.As(err, &respErr) {
+		return err
+	}
+	e := &HTTPError{
+		Vendor:     "mailgun",
+		Method:     http.MethodPost,
+		URL:        respErr.URL,
+		StatusCode: respErr.Actual,
+		Status:     fmt.Sprintf("%d %s", respErr.Actual, http.StatusText(respErr.Actual)),
+	}
+	e.setBody(respErr.Data)
+	return e
+}
+
+// UserError returns the response for errors caused by a value the person entered, such as a phone number Twilio can't text. It returns false for other errors.
+func userError(err error) (badReqBodyResp, bool) {
+	var invalidNum ErrInvalidNumber
+	if errors.As(err, &invalidNum) {
+		return badReqBodyResp{Message: "Invalid Phone Number", Field: "phone"}, true
+	}
+
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		return badReqBodyResp{}, false
+	}
+	for _, ue := range vendorUserErrors {
+		if ue.matches(httpErr) {
+			return ue.resp, true
+		}
+	}
+	return badReqBodyResp{}, false
+}
+
+func (ue vendorUserError) matches(e *HTTPError) bool {
+	if ue.vendor != e.Vendor || ue.status != e.StatusCode {
+		return false
+	}
+	if ue.code != "" && ue.code != e.Code {
+		return false
+	}
+	return ue.contains == "" || strings.Contains(strings.ToLower(e.Message), ue.contains)
 }
 
 func (ss *signupServer) logError(ctx context.Context, r *http.Request, err error) {
 	method := r.Method
 	url := r.URL.String()
 
-	ss.logger.ErrorContext(
-		ctx,
-		err.Error(),
+	logging.Error(ctx, ss.logger, err,
 		slog.String("method", method),
 		slog.String("url", url))
-
-	if hub := sentry.GetHubFromContext(ctx); hub != nil {
-		hub.CaptureException(err)
-	}
 }
 
 // errorResponse writes an error response to the client. The msg is sent as the error message in the response body,
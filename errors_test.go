@@ -7,7 +7,10 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+
+	"github.com/twilio/twilio-go/client"
 )
 
 type JSONErr struct {
@@ -15,7 +18,7 @@ type JSONErr struct {
 	Message string `json:"message"`
 }
 
-func TestHandleHTTPError(t *testing.T) {
+func TestHTTPError(t *testing.T) {
 	t.Run("prints human-readable message for HTTP error status responses", func(t *testing.T) {
 		mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Respond with an error
@@ -26,7 +29,7 @@ func TestHandleHTTPError(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
-		err = handleHTTPError(resp)
+		err = newHTTPError("", resp)
 		assertEqual(t, err.Error(), fmt.Sprintf(`HTTP Error:
 GET: %s
 /
@@ -53,7 +56,7 @@ Invalid API key`+"\n", mockServer.URL))
 			t.Fatal(err)
 		}
 
-		err = handleHTTPError(resp)
+		err = newHTTPError("", resp)
 		assertEqual(t, err.Error(), fmt.Sprintf(`HTTP Error:
 GET: %s
 /
@@ -76,12 +79,12 @@ Response:
 		if err != nil {
 			t.Fatal(err)
 		}
-		gotErr := handleHTTPError(resp)
+		gotErr := newHTTPError("zoom", resp)
 		if gotErr == nil {
 			t.Fatal("unexpected nil err")
 		}
 
-		want := fmt.Sprintf(`HTTP Error:
+		want := fmt.Sprintf(`zoom HTTP Error:
 GET: %s
 /v2/meetings/89012345678/registrants?occurrence_id=1665594000000
 
@@ -91,10 +94,74 @@ Response:
 `, srv.URL)
 
 		assertEqual(t, gotErr.Error(), want)
+		assertEqual(t, gotErr.StatusCode, http.StatusTooManyRequests)
+		assertEqual(t, gotErr.Code, "429")
+		assertEqual(t, strings.HasPrefix(gotErr.Message, "You have exceeded the daily rate limit"), true)
+		// Zoom's per-registrant limit won't reset until tomorrow.
+		assertEqual(t, gotErr.Retryable(), false)
+	})
+
+	t.Run("converts Twilio SDK errors", func(t *testing.T) {
+		sdkErr := &client.TwilioRestError{Code: 50407, Message: "Invalid messaging binding address", Status: http.StatusBadRequest}
 
+		var httpErr *HTTPError
+		if !errors.As(twilioError(fmt.Errorf("create: %w", sdkErr)), &httpErr) {
+			t.Fatal("want *HTTPError")
+		}
+		assertEqual(t, httpErr.Vendor, "twilio")
+		assertEqual(t, httpErr.Code, "50407")
+		assertEqual(t, httpErr.Retryable(), false)
 	})
 }
 
+func TestUserError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want badReqBodyResp
+		ok   bool
+	}{
+		{
+			name: "Zoom duplicate registrant",
+			err: fmt.Errorf("task failed: %q: %w", "zoom service", &HTTPError{
+				Vendor:     "zoom",
+				StatusCode: http.StatusTooManyRequests,
+				Message:    "You have exceeded the daily rate limit of (3) for Add meeting registrant API requests for the registrant (henri@email.com).",
+			}),
+			want: badReqBodyResp{Message: "Already registered for this session", Field: "email"},
+			ok:   true,
+		},
+		{
+			name: "Mailgun invalid email",
+			err:  &HTTPError{Vendor: "mailgun", StatusCode: http.StatusBadRequest, Message: "to parameter is not a valid address. please check documentation"},
+			want: badReqBodyResp{Message: "Invalid Email Address", Field: "email"},
+			ok:   true,
+		},
+		{
+			name: "Twilio invalid number",
+			err:  ErrInvalidNumber{err: errors.New("invalid number: +15555555555")},
+			want: badReqBodyResp{Message: "Invalid Phone Number", Field: "phone"},
+			ok:   true,
+		},
+		{
+			name: "vendor outage",
+			err:  &HTTPError{Vendor: "zoom", StatusCode: http.StatusServiceUnavailable},
+		},
+		{
+			name: "other errors",
+			err:  errors.New("database down"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := userError(tt.err)
+			assertEqual(t, ok, tt.ok)
+			assertEqual(t, got, tt.want)
+		})
+	}
+}
+
 // JSONError writes an HTTP error code and a JSON structured error body to an HTTP response.
 func JSONError(w http.ResponseWriter, err interface{}, code int) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
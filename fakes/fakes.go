@@ -0,0 +1,411 @@
+// Package fakes is an in-process stand-in for every external API the signup service calls, for local development.
+// Each integration is served under its own path prefix (Ex: "/zoom", "/twilio"). Every request is recorded and listed on the outbox page.
+package fakes
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// Server answers requests like the real APIs and records them.
+	Server struct {
+		// Base URL of the server. Ex: "http://127.0.0.1:8081".
+		URL string
+
+		srv    *http.Server
+		mux    *http.ServeMux
+		logger *slog.Logger
+
+		mu       sync.Mutex
+		requests []Request
+		// Twilio Conversation SIDs by participant phone number.
+		conversations map[string]string
+		// Original URLs by short code.
+		shortLinks map[string]string
+		nextID     int
+		// Counter for fake resource IDs.
+		idSeq int
+	}
+
+	// Request is a recorded request to a fake integration.
+	Request struct {
+		ID      int       `json:"id"`
+		Time    time.Time `json:"time"`
+		Service string    `json:"service"`
+		Method  string    `json:"method"`
+		Path    string    `json:"path"`
+		// Human-readable description of what would have been sent. Ex: "SMS to +15045551234: Hi Henri!".
+		Summary string `json:"summary"`
+		Body    string `json:"body"`
+		Status  int    `json:"status"`
+	}
+
+	// HandlerFunc handles a fake API request. The body has already been read. It returns a summary for the outbox and the JSON response.
+	handlerFunc func(r *http.Request, body []byte) (summary string, status int, resp any)
+)
+
+// Service names.
+const (
+	ServiceZoom       = "zoom"
+	ServiceGreenlight = "greenlight"
+	ServiceMailgun    = "mailgun"
+	ServiceTwilio     = "twilio"
+	ServiceSlack      = "slack"
+	ServiceSnapMail   = "snapmail"
+	ServiceShortener  = "shortener"
+	ServiceMessenger  = "messenger"
+)
+
+// New creates a fake server. Call Start to listen, or use the server as an http.Handler.
+func New(logger *slog.Logger) *Server {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	s := &Server{
+		mux:           http.NewServeMux(),
+		logger:        logger.With("service", "fakes"),
+		conversations: map[string]string{},
+		shortLinks:    map[string]string{},
+	}
+	s.routes()
+	return s
+}
+
+// Start listens on addr (Ex: "127.0.0.1:0" for any free port) and serves in the background. The server's URL is set once it is listening.
+func (s *Server) Start(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+	s.URL = "http://" + ln.Addr().String()
+	s.srv = &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := s.srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error(fmt.Errorf("serve: %w", err).Error())
+		}
+	}()
+	return nil
+}
+
+// Close stops the server.
+func (s *Server) Close(ctx context.Context) error {
+	if s.srv == nil {
+		return nil
+	}
+	return s.srv.Shutdown(ctx)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Requests returns the recorded requests, oldest first.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *Server) routes() {
+	s.handle("POST /zoom/oauth/token", ServiceZoom, s.zoomToken)
+	s.handle("POST /zoom/v2/meetings/{meetingID}/registrants", ServiceZoom, s.zoomRegister)
+
+	s.handle("POST /greenlight/", ServiceGreenlight, s.greenlightSignup)
+
+	s.handle("POST /mailgun/v3/{domain}/messages", ServiceMailgun, s.mailgunSend)
+	s.handle("GET /mailgun/v3/domains/{domain}", ServiceMailgun, s.mailgunDomain)
+
+	s.handle("GET /twilio/api/2010-04-01/Accounts/{file}", ServiceTwilio, s.twilioAccount)
+	s.handle("GET /twilio/conversations/v1/Services/{service}/ParticipantConversations", ServiceTwilio, s.twilioFindConversations)
+	s.handle("POST /twilio/conversations/v1/Services/{service}/Conversations", ServiceTwilio, s.twilioCreateConversation)
+	s.handle("POST /twilio/conversations/v1/Services/{service}/Conversations/{convo}/Participants", ServiceTwilio, s.twilioAddParticipant)
+	s.handle("POST /twilio/conversations/v1/Services/{service}/Conversations/{convo}/Messages", ServiceTwilio, s.twilioSendMessage)
+
+	s.handle("POST /slack/", ServiceSlack, s.slackWebhook)
+	s.handle("POST /snapmail/", ServiceSnapMail, s.snapMail)
+
+	s.handle("POST /shortener/api/urls", ServiceShortener, s.shorten)
+	// Readiness checks.
+	s.mux.HandleFunc("HEAD /shortener/api/urls", func(w http.ResponseWriter, r *http.Request) {})
+	s.mux.HandleFunc("GET /shortener/{code}", s.followShortLink)
+
+	s.mux.HandleFunc("GET /renderer", func(w http.ResponseWriter, r *http.Request) {})
+	s.mux.HandleFunc("GET /renderer/m/{params}", s.renderMessage)
+
+	s.handle("POST /messenger/", ServiceMessenger, s.messengerWebhook)
+
+	// Record unexpected requests so they show up in the outbox.
+	s.handle("/", "unknown", func(r *http.Request, body []byte) (string, int, any) {
+		return "No fake for this endpoint", http.StatusNotFound, map[string]string{"error": "not found"}
+	})
+}
+
+// Handle records every request to the pattern, then writes the handler's response as JSON.
+func (s *Server) handle(pattern, service string, h handlerFunc) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// Handlers parse forms from the body too.
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		summary, status, resp := h(r, body)
+		s.record(Request{
+			Service: service,
+			Method:  r.Method,
+			Path:    r.URL.RequestURI(),
+			Summary: summary,
+			Body:    string(body),
+			Status:  status,
+		})
+		s.logger.Info(summary, slog.String("fake", service), slog.String("path", r.URL.Path))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if resp != nil {
+			_ = json.NewEncoder(w).Encode(resp)
+		}
+	})
+}
+
+func (s *Server) record(req Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	req.ID = s.nextID
+	req.Time = time.Now()
+	s.requests = append(s.requests, req)
+}
+
+// NewID returns a unique ID with the prefix. Ex: "CH0000000000000000000000000000001".
+func (s *Server) newID(prefix string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.idSeq++
+	return fmt.Sprintf("%s%032d", prefix, s.idSeq)
+}
+
+func (s *Server) zoomToken(r *http.Request, body []byte) (string, int, any) {
+	return "OAuth token issued", http.StatusOK, map[string]any{
+		"access_token": "fake-zoom-token",
+		"token_type":   "bearer",
+		"expires_in":   3600,
+		"scope":        "meeting:write:admin",
+	}
+}
+
+func (s *Server) zoomRegister(r *http.Request, body []byte) (string, int, any) {
+	var reg struct {
+		FirstName string `json:"first_name"`
+		LastName  string `json:"last_name"`
+		Email     string `json:"email"`
+	}
+	_ = json.Unmarshal(body, &reg)
+	meetingID := r.PathValue("meetingID")
+	return fmt.Sprintf("Registered %s %s <%s> for meeting %s", reg.FirstName, reg.LastName, reg.Email, meetingID),
+		http.StatusCreated,
+		map[string]any{
+			"id":            meetingID,
+			"registrant_id": s.newID("reg"),
+			"join_url":      fmt.Sprintf("https://us06web.zoom.us/w/%s?tk=fake", meetingID),
+		}
+}
+
+func (s *Server) greenlightSignup(r *http.Request, body []byte) (string, int, any) {
+	var su struct {
+		NameFirst string `json:"nameFirst"`
+		NameLast  string `json:"nameLast"`
+		Email     string `json:"email"`
+	}
+	_ = json.Unmarshal(body, &su)
+	return fmt.Sprintf("Signup for %s %s <%s>", su.NameFirst, su.NameLast, su.Email),
+		http.StatusOK,
+		map[string]string{"status": "success", "signupId": s.newID("gl")}
+}
+
+func (s *Server) mailgunSend(r *http.Request, body []byte) (string, int, any) {
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		_ = r.ParseForm()
+	}
+	domain := r.PathValue("domain")
+	summary := fmt.Sprintf("Email to %s: %q", r.FormValue("to"), r.FormValue("subject"))
+	if t := r.FormValue("template"); t != "" {
+		summary += fmt.Sprintf(" (template %q)", t)
+	}
+	return summary, http.StatusOK, map[string]string{
+		"id":      fmt.Sprintf("<%s@%s>", s.newID("msg"), domain),
+		"message": "Queued. Thank you.",
+	}
+}
+
+func (s *Server) mailgunDomain(r *http.Request, body []byte) (string, int, any) {
+	domain := r.PathValue("domain")
+	return "Domain " + domain + " checked", http.StatusOK, map[string]any{
+		"domain": map[string]string{"name": domain, "state": "active"},
+	}
+}
+
+func (s *Server) twilioAccount(r *http.Request, body []byte) (string, int, any) {
+	sid := strings.TrimSuffix(r.PathValue("file"), ".json")
+	return "Account " + sid + " checked", http.StatusOK, map[string]string{"sid": sid, "status": "active"}
+}
+
+func (s *Server) twilioFindConversations(r *http.Request, body []byte) (string, int, any) {
+	address := r.URL.Query().Get("Address")
+
+	s.mu.Lock()
+	sid, ok := s.conversations[address]
+	s.mu.Unlock()
+
+	convos := []map[string]string{}
+	if ok {
+		convos = append(convos, map[string]string{"conversation_sid": sid})
+	}
+	return fmt.Sprintf("Found %d conversation(s) for %s", len(convos), address), http.StatusOK, map[string]any{
+		"conversations": convos,
+		"meta":          map[string]any{"key": "conversations", "next_page_url": nil},
+	}
+}
+
+func (s *Server) twilioCreateConversation(r *http.Request, body []byte) (string, int, any) {
+	form, _ := url.ParseQuery(string(body))
+	sid := s.newID("CH")
+	return fmt.Sprintf("Created conversation %s (%s)", sid, form.Get("FriendlyName")), http.StatusCreated, map[string]string{
+		"sid":           sid,
+		"friendly_name": form.Get("FriendlyName"),
+	}
+}
+
+func (s *Server) twilioAddParticipant(r *http.Request, body []byte) (string, int, any) {
+	form, _ := url.ParseQuery(string(body))
+	convo := r.PathValue("convo")
+	participant := form.Get("Identity")
+	if address := form.Get("MessagingBinding.Address"); address != "" {
+		participant = address
+		s.mu.Lock()
+		s.conversations[address] = convo
+		s.mu.Unlock()
+	}
+	return fmt.Sprintf("Added %s to conversation %s", participant, convo), http.StatusCreated, map[string]string{
+		"sid":              s.newID("MB"),
+		"conversation_sid": convo,
+	}
+}
+
+func (s *Server) twilioSendMessage(r *http.Request, body []byte) (string, int, any) {
+	form, _ := url.ParseQuery(string(body))
+	convo := r.PathValue("convo")
+
+	to := convo
+	s.mu.Lock()
+	for address, sid := range s.conversations {
+		if sid == convo {
+			to = address
+		}
+	}
+	s.mu.Unlock()
+
+	return fmt.Sprintf("SMS to %s: %s", to, form.Get("Body")), http.StatusCreated, map[string]string{
+		"sid":              s.newID("IM"),
+		"conversation_sid": convo,
+		"body":             form.Get("Body"),
+	}
+}
+
+func (s *Server) slackWebhook(r *http.Request, body []byte) (string, int, any) {
+	var msg struct {
+		Text string `json:"text"`
+	}
+	_ = json.Unmarshal(body, &msg)
+	summary := "Slack message"
+	if msg.Text != "" {
+		summary += ": " + msg.Text
+	}
+	return summary, http.StatusOK, nil
+}
+
+func (s *Server) snapMail(r *http.Request, body []byte) (string, int, any) {
+	return "SNAP mail signup event", http.StatusOK, map[string]string{"status": "ok"}
+}
+
+func (s *Server) messengerWebhook(r *http.Request, body []byte) (string, int, any) {
+	return "Messenger API " + strings.TrimPrefix(r.URL.Path, "/messenger"), http.StatusOK, map[string]string{"status": "ok"}
+}
+
+func (s *Server) shorten(r *http.Request, body []byte) (string, int, any) {
+	var link struct {
+		OriginalURL string `json:"originalUrl"`
+	}
+	_ = json.Unmarshal(body, &link)
+
+	s.mu.Lock()
+	// Codes are 10 characters, like ospk.org codes.
+	code := fmt.Sprintf("fake%06d", len(s.shortLinks)+1)
+	s.shortLinks[code] = link.OriginalURL
+	s.mu.Unlock()
+
+	shortURL := fmt.Sprintf("%s/shortener/%s", baseURL(r), code)
+	return fmt.Sprintf("Shortened to %s", shortURL), http.StatusCreated, map[string]string{
+		"shortUrl":    shortURL,
+		"code":        code,
+		"originalUrl": link.OriginalURL,
+	}
+}
+
+func (s *Server) followShortLink(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	original, ok := s.shortLinks[r.PathValue("code")]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	http.Redirect(w, r, original, http.StatusFound)
+}
+
+// RenderMessage renders a plain Info Session details page from the message params, like the real renderer at sms.operationspark.org.
+func (s *Server) renderMessage(w http.ResponseWriter, r *http.Request) {
+	raw := r.PathValue("params")
+	decoded, err := base64.URLEncoding.DecodeString(raw)
+	if err != nil {
+		decoded, err = base64.StdEncoding.DecodeString(raw)
+	}
+	if err != nil {
+		http.Error(w, "invalid message params: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var params infoSessionParams
+	if err := json.Unmarshal(decoded, &params); err != nil {
+		http.Error(w, "invalid message params: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	// Session times are shown in Central Time.
+	if ct, err := time.LoadLocation("America/Chicago"); err == nil {
+		params.Date = params.Date.In(ct)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := infoSessionTmpl.Execute(w, params); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func baseURL(r *http.Request) string {
+	return "http://" + r.Host
+}
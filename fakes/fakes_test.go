@@ -0,0 +1,135 @@
+package fakes
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakes(t *testing.T) {
+	t.Run("records Mailgun emails", func(t *testing.T) {
+		s := New(nil)
+
+		body := &bytes.Buffer{}
+		mw := multipart.NewWriter(body)
+		require.NoError(t, mw.WriteField("to", "henri@email.com"))
+		require.NoError(t, mw.WriteField("subject", "Welcome!"))
+		require.NoError(t, mw.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/mailgun/v3/mail.example.com/messages", body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		res := httptest.NewRecorder()
+		s.ServeHTTP(res, req)
+
+		require.Equal(t, http.StatusOK, res.Code)
+		var resp struct {
+			ID string `json:"id"`
+		}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&resp))
+		require.Contains(t, resp.ID, "@mail.example.com")
+
+		reqs := s.Requests()
+		require.Len(t, reqs, 1)
+		require.Equal(t, ServiceMailgun, reqs[0].Service)
+		require.Equal(t, `Email to henri@email.com: "Welcome!"`, reqs[0].Summary)
+	})
+
+	t.Run("finds conversations created for a phone number", func(t *testing.T) {
+		s := New(nil)
+		base := "/twilio/conversations/v1/Services/IS123"
+
+		res := postForm(t, s, base+"/Conversations", url.Values{"FriendlyName": {"Henri Testaroni"}})
+		var convo struct {
+			SID string `json:"sid"`
+		}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&convo))
+		require.NotEmpty(t, convo.SID)
+
+		postForm(t, s, base+"/Conversations/"+convo.SID+"/Participants", url.Values{"MessagingBinding.Address": {"+15045551234"}})
+		postForm(t, s, base+"/Conversations/"+convo.SID+"/Messages", url.Values{"Body": {"Hi Henri!"}})
+
+		req := httptest.NewRequest(http.MethodGet, base+"/ParticipantConversations?Address=%2B15045551234", nil)
+		res = httptest.NewRecorder()
+		s.ServeHTTP(res, req)
+		require.Contains(t, res.Body.String(), convo.SID)
+
+		reqs := s.Requests()
+		require.Equal(t, "SMS to +15045551234: Hi Henri!", reqs[2].Summary)
+	})
+
+	t.Run("shortens and follows links", func(t *testing.T) {
+		s := New(nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/shortener/api/urls", strings.NewReader(`{"originalUrl":"https://operationspark.org"}`))
+		res := httptest.NewRecorder()
+		s.ServeHTTP(res, req)
+		require.Equal(t, http.StatusCreated, res.Code)
+
+		var link struct {
+			ShortURL string `json:"shortUrl"`
+		}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&link))
+		u, err := url.Parse(link.ShortURL)
+		require.NoError(t, err)
+
+		res = httptest.NewRecorder()
+		s.ServeHTTP(res, httptest.NewRequest(http.MethodGet, u.Path, nil))
+		require.Equal(t, http.StatusFound, res.Code)
+		require.Equal(t, "https://operationspark.org", res.Header().Get("Location"))
+	})
+
+	t.Run("records unknown endpoints as not found", func(t *testing.T) {
+		s := New(nil)
+
+		res := httptest.NewRecorder()
+		s.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/nope", nil))
+		require.Equal(t, http.StatusNotFound, res.Code)
+		require.Equal(t, "unknown", s.Requests()[0].Service)
+	})
+}
+
+func TestOutbox(t *testing.T) {
+	s := New(nil)
+	s.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/slack/webhook", strings.NewReader(`{"text":"New signup"}`)))
+	s.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/snapmail/", nil))
+
+	t.Run("lists requests as JSON, newest first", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/dev/outbox", nil)
+		req.Header.Set("Accept", "application/json")
+		res := httptest.NewRecorder()
+		s.Outbox(res, req)
+
+		var reqs []Request
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&reqs))
+		require.Len(t, reqs, 2)
+		require.Equal(t, ServiceSnapMail, reqs[0].Service)
+		require.Equal(t, "Slack message: New signup", reqs[1].Summary)
+	})
+
+	t.Run("filters by service", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/dev/outbox?service=slack", nil)
+		res := httptest.NewRecorder()
+		s.Outbox(res, req)
+
+		require.Equal(t, http.StatusOK, res.Code)
+		require.Contains(t, res.Body.String(), "Slack message: New signup")
+		require.NotContains(t, res.Body.String(), "SNAP mail signup event")
+	})
+}
+
+func postForm(t *testing.T, s *Server, path string, form url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res := httptest.NewRecorder()
+	s.ServeHTTP(res, req)
+	require.Equal(t, http.StatusCreated, res.Code)
+	return res
+}
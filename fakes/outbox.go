@@ -0,0 +1,46 @@
+package fakes
+
+import (
+	_ "embed"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+//go:embed outbox.html
+var outboxHTML string
+
+var outboxTmpl = template.Must(template.New("outbox").Parse(outboxHTML))
+
+// Outbox lists every recorded request, newest first. It responds with JSON if the client accepts "application/json", and with an HTML page otherwise.
+// The "service" query parameter filters by integration. Ex: "/dev/outbox?service=twilio".
+func (s *Server) Outbox(w http.ResponseWriter, r *http.Request) {
+	reqs := s.Requests()
+	slices.Reverse(reqs)
+
+	if service := r.URL.Query().Get("service"); service != "" {
+		reqs = slices.DeleteFunc(reqs, func(req Request) bool { return req.Service != service })
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(reqs); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	data := struct {
+		Requests []Request
+		Services []string
+	}{
+		Requests: reqs,
+		Services: []string{ServiceZoom, ServiceGreenlight, ServiceMailgun, ServiceTwilio, ServiceSlack, ServiceSnapMail, ServiceShortener, ServiceMessenger},
+	}
+	if err := outboxTmpl.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,30 @@
+package fakes
+
+import (
+	_ "embed"
+	"html/template"
+	"time"
+)
+
+// InfoSessionParams are the message params the signup service encodes in renderer URLs.
+type infoSessionParams struct {
+	Template     string    `json:"template"`
+	ZoomLink     string    `json:"zoomLink"`
+	Date         time.Time `json:"date"`
+	Name         string    `json:"name"`
+	LocationType string    `json:"locationType"`
+	JoinCode     string    `json:"joinCode"`
+	Location     struct {
+		Name         string `json:"name"`
+		Line1        string `json:"line1"`
+		CityStateZip string `json:"cityStateZip"`
+		MapURL       string `json:"mapUrl"`
+	} `json:"location"`
+	GreenlightURL string `json:"greenlightUrl"`
+	Language      string `json:"language"`
+}
+
+//go:embed info_session.html
+var infoSessionHTML string
+
+var infoSessionTmpl = template.Must(template.New("infoSession").Parse(infoSessionHTML))
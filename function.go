@@ -2,35 +2,41 @@ package signup
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"runtime/debug"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
 
 	"github.com/GoogleCloudPlatform/functions-framework-go/functions"
-	"github.com/getsentry/sentry-go"
-	sentryhttp "github.com/getsentry/sentry-go/http"
+	"github.com/operationspark/service-signup/antispam"
 	"github.com/operationspark/service-signup/conversations"
+	"github.com/operationspark/service-signup/email"
 	"github.com/operationspark/service-signup/mongodb"
 	"github.com/operationspark/service-signup/notify"
+	"github.com/operationspark/service-signup/sms"
+	"github.com/operationspark/service-signup/templates"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
 )
 
 type (
 	// StubStore is a no-op implementation of the Store interface.
-	// GCP Cloud Function requires the dreaded init() call to register the handler with the functions-framework. Init() is also unnecessarily called in test mode. Init() in turns calls NewServer() which needs to connect to MongoDB. To avoid that, I have this StubStore that implements the Store interface, but does nothing. It is only used in test mode to prevent the MongoDB connection error.
+	// GCP Cloud Function requires the dreaded init() call to register the handler with the functions-framework. Init() is also unnecessarily called in test mode. Servers created in tests (CI=true) still need a Store, but must not connect to MongoDB. To avoid that, I have this StubStore that implements the Store interface, but does nothing. It is only used in test mode to prevent the MongoDB connection error.
 	StubStore struct{}
 )
 
 // GetUpcomingSessions implements the Store interface.
-func (s *StubStore) GetUpcomingSessions(context.Context, time.Duration) ([]*notify.UpcomingSession, error) {
+func (s *StubStore) GetUpcomingSessions(context.Context, time.Duration, ...string) ([]*notify.UpcomingSession, error) {
 	return []*notify.UpcomingSession{}, nil
 }
 
@@ -38,106 +44,159 @@ func init() {
 	// Register an HTTP function with the Functions Framework
 	// This handler name maps to the entry point name in the Google Cloud Function platform.
 	// https://cloud.google.com/functions/docs/writing/write-http-functions
-	functions.HTTP("HandleSignUp", NewServer().ServeHTTP)
-}
-
-func NewServer() *http.ServeMux {
-	sentryDSN := os.Getenv("SENTRY_DSN")
-	rawSampleRate, ok := os.LookupEnv("SENTRY_SAMPLE_RATE")
-	if !ok {
-		rawSampleRate = "1.0"
-	}
-	sentrySampleRate, err := strconv.ParseFloat(rawSampleRate, 64)
-	if err != nil {
-		log.Fatalf("sentry sample rate: %v", err)
-	}
-	sentryEnv := "development"
-	if os.Getenv("SENTRY_ENV") != "" {
-		sentryEnv = os.Getenv("SENTRY_ENV")
+	// The config is loaded now so a bad deploy fails on startup instead of on the first signup. The server is created on the first request so importing the package (Ex: cmd/server, tests) doesn't connect to MongoDB.
+	cfg, cfgErr := LoadConfig(os.Getenv("CONFIG_FILE"))
+	// The Functions Framework sets FUNCTION_TARGET. Commands importing the package load their own config (Ex: cmd/server -dev).
+	if cfgErr != nil && os.Getenv("FUNCTION_TARGET") != "" {
+		log.Fatalf("config:\n%v", cfgErr)
 	}
 
-	err = sentry.Init(sentry.ClientOptions{
-		Dsn:              sentryDSN,
-		EnableTracing:    true,
-		TracesSampleRate: sentrySampleRate,
-		Environment:      sentryEnv,
+	var once sync.Once
+	var server http.Handler
+	functions.HTTP("HandleSignUp", func(w http.ResponseWriter, r *http.Request) {
+		once.Do(func() {
+			if cfgErr != nil {
+				log.Fatalf("config:\n%v", cfgErr)
+			}
+			server = NewServerFromConfig(cfg)
+		})
+		server.ServeHTTP(w, r)
 	})
+}
+
+// NewServer loads the config from the environment and the optional CONFIG_FILE, then creates the server. Invalid config is fatal.
+func NewServer() http.Handler {
+	cfg, err := LoadConfig(os.Getenv("CONFIG_FILE"))
 	if err != nil {
-		log.Fatalf("sentry init: %v", err)
+		log.Fatalf("config:\n%v", err)
 	}
-	defer sentry.Flush(2 * time.Second)
+	return NewServerFromConfig(cfg)
+}
 
-	// Check env vars only in GCP context
-	// K_REVISION is set in GCP environment, so if it's not set, we're not running in GCP and we can skip the check
-	// https://cloud.google.com/functions/docs/configuring/env-var#newer_runtimes
-	skipEnvVarCheck := os.Getenv("K_REVISION") == ""
-	err = checkEnvVars(skipEnvVarCheck)
+// NewServerFromConfig creates the server with every service configured from cfg. Errors are fatal.
+// Cloud Functions don't get a shutdown hook, so the app is never closed. Spans and Sentry events are sent in the background as they finish.
+func NewServerFromConfig(cfg Config) http.Handler {
+	app, err := NewApp(cfg)
 	if err != nil {
 		log.Fatal(err)
 	}
+	return app
+}
+
+// SplitList splits a comma- or space-separated list. Deploy env vars are comma-separated, so lists set there must use spaces.
+func splitList(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+}
+
+// NewEmailSender creates the email backend chosen by cfg.Email.Provider:
+//   - "mailgun" (default): Mailgun hosted templates.
+//   - "smtp": our own HTML templates sent through the SMTP server.
+//   - "capture": saves emails to the capture directory and serves them at /dev/emails for local development.
+func newEmailSender(cfg Config) email.Sender {
+	switch cfg.Email.Provider {
+	case "smtp":
+		return email.NewSMTPSender(email.SMTPOptions{
+			Host:     cfg.Email.SMTP.Host,
+			Port:     cfg.Email.SMTP.Port,
+			Username: cfg.Email.SMTP.Username,
+			Password: cfg.Email.SMTP.Password,
+		})
 
-	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
-	logger = logger.With("git_hash", getGitRev())
+	case "capture":
+		dir := cfg.Email.CaptureDir
+		if dir == "" {
+			dir = filepath.Join(os.TempDir(), "signup-emails")
+		}
+		capture, err := email.NewCaptureSender(dir)
+		if err != nil {
+			log.Fatalf("email capture: %v", err)
+		}
+		return capture
 
-	mux := http.NewServeMux()
-	sentryHandler := sentryhttp.New(sentryhttp.Options{})
-	mux.HandleFunc("/", sentryHandler.HandleFunc(NewSignupServer(logger).HandleSignUp))
-	mux.HandleFunc("/notify", sentryHandler.HandleFunc(NewNotifyServer(logger).ServeHTTP))
-	return mux
+	default:
+		return email.NewMailgunSender(cfg.Mailgun.Domain, cfg.Mailgun.APIKey, cfg.Mailgun.APIBase)
+	}
 }
 
-func checkEnvVars(skip bool) error {
-	if skip {
-		return nil
+// ConfigureSignupGuard configures bot and spam protection for the signup endpoint. See GuardConfig.
+func configureSignupGuard(cfg Config, store signupStore, logger *slog.Logger) *signupGuard {
+	var captcha antispam.Verifier
+	switch cfg.Guard.CaptchaProvider {
+	case "recaptcha":
+		captcha = antispam.NewRecaptcha(cfg.Guard.CaptchaSecretKey, cfg.Guard.CaptchaMinScore)
+	case "turnstile":
+		captcha = antispam.NewTurnstile(cfg.Guard.CaptchaSecretKey)
+	case "fake":
+		captcha = antispam.Fake{}
 	}
-	requiredEnvVars := []string{
-		"GREENLIGHT_API_KEY",
-		"GREENLIGHT_WEBHOOK_URL",
-		"MAIL_DOMAIN",
-		"MAILGUN_API_KEY",
-		"MONGO_URI",
-		"OS_MESSAGING_SERVICE_URL",
-		"OS_MESSAGING_SIGNING_SECRET",
-		"OS_RENDERING_SERVICE_URL",
-		"SLACK_WEBHOOK_URL",
-		"TWILIO_ACCOUNT_SID",
-		"TWILIO_AUTH_TOKEN",
-		"TWILIO_CONVERSATIONS_SID",
-		"TWILIO_PHONE_NUMBER",
-		"URL_SHORTENER_API_KEY",
-		"ZOOM_ACCOUNT_ID",
-		"ZOOM_CLIENT_ID",
-		"ZOOM_CLIENT_SECRET",
-		"ZOOM_MEETING_12",
-		"ZOOM_MEETING_17",
+
+	return newSignupGuard(signupGuardOptions{
+		captcha:      captcha,
+		ipLimiter:    antispam.NewRateLimiter(cfg.Guard.LimitPerIP, time.Hour),
+		emailLimiter: antispam.NewRateLimiter(cfg.Guard.LimitPerEmail, time.Hour),
+		quarantine:   cfg.Guard.Quarantine,
+		store:        store,
+		logger:       logger,
+	})
+}
+
+// NewTemplateRegistry loads the SMS templates. The embedded defaults can be overridden by templates in the SMS templates directory, then by templates in the "smsTemplates" MongoDB collection if enabled.
+func newTemplateRegistry(cfg Config, mongoClient *mongo.Client, dbName string) *templates.Registry {
+	opts := templates.Options{SegmentBudget: cfg.SMS.SegmentBudget}
+
+	if cfg.SMS.TemplatesDir != "" {
+		opts.Sources = append(opts.Sources, templates.DirSource(cfg.SMS.TemplatesDir))
 	}
 
-	for _, ev := range requiredEnvVars {
-		if os.Getenv(ev) == "" {
-			return fmt.Errorf("env var %q is required", ev)
-		}
+	if cfg.SMS.TemplatesFromDB && mongoClient != nil {
+		opts.Sources = append(opts.Sources, mongodb.New(dbName, mongoClient))
 	}
-	return nil
+
+	r, err := templates.New(context.Background(), opts)
+	if err != nil {
+		log.Fatalf("SMS templates: %v", err)
+	}
+	return r
 }
 
-func getMongoClient() (*mongo.Client, string, error) {
-	mongoURI := os.Getenv("MONGO_URI")
-	isCI := os.Getenv("CI") == "true"
-	parsed, err := url.Parse(mongoURI)
-	if isCI || (mongoURI == "" || err != nil) {
-		return nil, "", fmt.Errorf("invalid 'MONGO_URI' environmental variable: %q", mongoURI)
+// SMSQuietHours is the window in the recipient's time zone when non-urgent SMS messages are queued.
+func smsQuietHours(cfg Config) sms.QuietHours {
+	return sms.QuietHours{Start: cfg.SMS.QuietHoursStart, End: cfg.SMS.QuietHoursEnd}
+}
+
+func getMongoClient(cfg Config) (*mongo.Client, string, error) {
+	parsed, err := url.Parse(cfg.MongoURI)
+	if cfg.CI || (cfg.MongoURI == "" || err != nil) {
+		return nil, "", fmt.Errorf("invalid 'MONGO_URI' environmental variable: %q", cfg.Redacted().MongoURI)
 	}
 	dbName := strings.TrimPrefix(parsed.Path, "/")
-	m, err := mongo.Connect(context.Background(), options.Client().ApplyURI(mongoURI))
+	m, err := mongo.Connect(context.Background(), options.Client().ApplyURI(cfg.MongoURI).SetMonitor(otelmongo.NewMonitor()))
 	return m, dbName, err
 }
 
-func NewNotifyServer(logger *slog.Logger) *notify.Server {
-	mongoURI := os.Getenv("MONGO_URI")
-	isCI := os.Getenv("CI") == "true"
-	parsed, err := url.Parse(mongoURI)
-	if isCI || (mongoURI == "" || err != nil) {
-		fmt.Printf("Invalid 'MONGO_URI' environmental variable: %q\n", mongoURI)
+// NewTwilioServiceFromConfig creates the Twilio SMS service. Queued messages are saved with the queue.
+func newTwilioServiceFromConfig(cfg Config, smsLimiter *sms.Limiter, smsTemplates *templates.Registry, queue sms.Queue) *smsService {
+	return NewTwilioService(twilioServiceOptions{
+		accountSID:                 cfg.Twilio.AccountSID,
+		authToken:                  cfg.Twilio.AuthToken,
+		apiBase:                    cfg.Twilio.APIBase,
+		fromPhoneNum:               cfg.Twilio.PhoneNumber,
+		conversationsSid:           cfg.Twilio.ConversationsSID,
+		opSparkMessagingSvcBaseURL: cfg.Messaging.ServiceURL,
+		opSparkMessagingAPIKey:     cfg.ShortenerAPIKey,
+		limiter:                    smsLimiter,
+		quietHours:                 smsQuietHours(cfg),
+		queue:                      queue,
+		templates:                  smsTemplates,
+		programs:                   cfg.programs(),
+	})
+}
+
+func NewNotifyServer(cfg Config, logger *slog.Logger, mongoClient *mongo.Client, dbName string, smsLimiter *sms.Limiter, smsTemplates *templates.Registry) *notify.Server {
+	if mongoClient == nil {
+		fmt.Printf("Invalid 'MONGO_URI' environmental variable: %q\n", cfg.Redacted().MongoURI)
 		fmt.Printf("If you're running tests, you can ignore this message.\n\n")
 		// See StubStore comment above
 		return notify.NewServer(notify.ServerOpts{
@@ -146,99 +205,183 @@ func NewNotifyServer(logger *slog.Logger) *notify.Server {
 		})
 	}
 
-	dbName := strings.TrimPrefix(parsed.Path, "/")
-	mongoClient, err := mongo.Connect(context.Background(), options.Client().ApplyURI(mongoURI))
-	if err != nil {
-		log.Fatalf("Could not connect to MongoDB: %q", mongoURI)
-	}
 	mongoService := notify.NewMongoService(mongoClient, dbName)
 
 	// TODO: Should we just use the once instance of a Twilio service?
-	twilioSvc := NewTwilioService(twilioServiceOptions{
-		accountSID:                 os.Getenv("TWILIO_ACCOUNT_SID"),
-		authToken:                  os.Getenv("TWILIO_AUTH_TOKEN"),
-		fromPhoneNum:               os.Getenv("TWILIO_PHONE_NUMBER"),
-		conversationsSid:           os.Getenv("TWILIO_CONVERSATIONS_SID"),
-		opSparkMessagingSvcBaseURL: os.Getenv("OS_MESSAGING_SERVICE_URL"),
-	})
+	twilioSvc := newTwilioServiceFromConfig(cfg, smsLimiter, smsTemplates, mongodb.New(dbName, mongoClient))
 
 	return notify.NewServer(notify.ServerOpts{
-		OSRendererService: &osRenderer{baseURL: os.Getenv("OS_RENDERING_SERVICE_URL")},
+		OSRendererService: &osRenderer{baseURL: cfg.RendererURL, programs: cfg.programs()},
 		Store:             mongoService,
-		SMSService:        twilioSvc,
-		ShortLinkService:  NewURLShortener(ShortenerOpts{apiKey: os.Getenv("URL_SHORTENER_API_KEY")}),
-		Logger:            logger,
+		DigestStore:       mongoService,
+		// Signup digests go to their own channel when configured.
+		DigestNotifier:   NewSlackService(cfg.digestWebhookURL()),
+		SMSService:       twilioSvc,
+		QueuedSMSService: twilioSvc,
+		ShortLinkService: NewURLShortener(ShortenerOpts{apiOverride: cfg.ShortenerURL, apiKey: cfg.ShortenerAPIKey}),
+		Templates:        smsTemplates,
+		Programs:         cfg.programs().notifyPrograms(),
+		QuietHours:       smsQuietHours(cfg),
+		Logger:           logger,
 	})
 }
 
-func NewSignupServer(logger *slog.Logger) *signupServer {
-	// Set up services/tasks to run when someone signs up for an Info Session.
-	mgDomain := os.Getenv("MAIL_DOMAIN")
-	mgAPIKey := os.Getenv("MAILGUN_API_KEY")
-	mgSvc := NewMailgunService(mgDomain, mgAPIKey, "")
+// NewHealthServer checks every dependency the service needs to handle a signup.
+func NewHealthServer(cfg Config, mongoClient *mongo.Client, emailSender email.Sender) *healthServer {
+	zoomSvc := NewZoomService(ZoomOptions{
+		baseAPIOverride:   cfg.Zoom.APIBase,
+		baseOAuthOverride: cfg.Zoom.OAuthBase,
+		clientID:          cfg.Zoom.ClientID,
+		clientSecret:      cfg.Zoom.ClientSecret,
+		accountID:         cfg.Zoom.AccountID,
+	})
+	twilioSvc := NewTwilioService(twilioServiceOptions{
+		accountSID: cfg.Twilio.AccountSID,
+		authToken:  cfg.Twilio.AuthToken,
+		apiBase:    cfg.Twilio.APIBase,
+	})
+	shortener := NewURLShortener(ShortenerOpts{apiOverride: cfg.ShortenerURL, apiKey: cfg.ShortenerAPIKey})
+	renderer := &osRenderer{baseURL: cfg.RendererURL}
 
-	glWebhookURL := os.Getenv("GREENLIGHT_WEBHOOK_URL")
-	glAPIkey := os.Getenv("GREENLIGHT_API_KEY")
-	glSvc := NewGreenlightService(glWebhookURL, glAPIkey)
+	checks := []dependencyCheck{
+		{name: "mongo", check: func(ctx context.Context) error {
+			if mongoClient == nil {
+				return errors.New("not connected")
+			}
+			return mongoClient.Ping(ctx, nil)
+		}},
+		{name: "zoom", check: zoomSvc.checkHealth},
+		{name: "twilio", check: twilioSvc.checkAccount},
+		{name: "shortener", check: shortener.ping},
+		{name: "renderer", check: renderer.ping},
+	}
+	// Only the Mailgun backend has a domain to check.
+	if mg, ok := emailSender.(*email.MailgunSender); ok {
+		checks = append(checks, dependencyCheck{name: "mailgun", check: mg.CheckDomain})
+	}
 
-	slackWebhookURL := os.Getenv("SLACK_WEBHOOK_URL")
-	slackSvc := NewSlackService(slackWebhookURL)
+	return newHealthServer(healthOptions{
+		checks:   checks,
+		cacheTTL: time.Duration(cfg.ReadinessCacheSeconds) * time.Second,
+	})
+}
 
-	zoomAccountID := os.Getenv("ZOOM_ACCOUNT_ID")
-	zoomClientID := os.Getenv("ZOOM_CLIENT_ID")
-	zoomClientSecret := os.Getenv("ZOOM_CLIENT_SECRET")
-	zoomMeeting12 := os.Getenv("ZOOM_MEETING_12")
-	zoomMeeting17 := os.Getenv("ZOOM_MEETING_17")
+// NewReportServer serves signup attribution reports for marketing.
+func NewReportServer(cfg Config, logger *slog.Logger, mongoClient *mongo.Client, dbName string) *campaignReportServer {
+	return NewCampaignReportServer(cfg.ReportsAPIKey, mongodb.New(dbName, mongoClient), logger)
+}
 
-	zoomSvc := NewZoomService(ZoomOptions{
-		clientID:     zoomClientID,
-		clientSecret: zoomClientSecret,
-		accountID:    zoomAccountID,
+// NewAdminServerFromConfig serves the admin API over the Greenlight database. Imported signups are registered with the given signup service, and template previews are served by preview.
+func NewAdminServerFromConfig(cfg Config, logger *slog.Logger, mongoClient *mongo.Client, dbName string, service registerer, preview http.HandlerFunc) *adminServer {
+	return NewAdminServer(adminServerOptions{
+		apiKey:   cfg.AdminAPIKey,
+		store:    mongodb.New(dbName, mongoClient),
+		roster:   newRosterExporterFromConfig(cfg, logger, mongoClient, dbName),
+		importer: newSignupImporterFromConfig(logger, mongoClient, dbName, service),
+		reviewer: quarantineReviewerFrom(service),
+		preview:  preview,
+		logger:   logger,
 	})
+}
 
-	twilioAcctSID := os.Getenv("TWILIO_ACCOUNT_SID")
-	twilioAuthToken := os.Getenv("TWILIO_AUTH_TOKEN")
-	twilioPhoneNum := os.Getenv("TWILIO_PHONE_NUMBER")
-	twilioConversationsSid := os.Getenv("TWILIO_CONVERSATIONS_SID")
+// QuarantineReviewerFrom returns the signup service as a quarantineReviewer, or nil if it can't release quarantined signups.
+func quarantineReviewerFrom(service registerer) quarantineReviewer {
+	if reviewer, ok := service.(quarantineReviewer); ok {
+		return reviewer
+	}
+	return nil
+}
 
-	osMessagingSvcURL := os.Getenv("OS_MESSAGING_SERVICE_URL")
-	osMessagingSigningSecret := os.Getenv("OS_MESSAGING_SIGNING_SECRET")
+// NewSignupImporterFromConfig creates the bulk signup importer, or returns nil if the service can't register imported signups.
+func newSignupImporterFromConfig(logger *slog.Logger, mongoClient *mongo.Client, dbName string, service registerer) *signupImporter {
+	bulk, ok := service.(bulkRegisterer)
+	if !ok {
+		return nil
+	}
+	return &signupImporter{
+		service:  bulk,
+		sessions: mongodb.New(dbName, mongoClient),
+		logger:   logger.With("service", "import"),
+	}
+}
 
-	twilioSvc := NewTwilioService(twilioServiceOptions{
-		accountSID:                 twilioAcctSID,
-		authToken:                  twilioAuthToken,
-		fromPhoneNum:               twilioPhoneNum,
-		opSparkMessagingSvcBaseURL: osMessagingSvcURL,
-		conversationsSid:           twilioConversationsSid,
+// NewRosterExporterFromConfig creates the session roster exporter. Zoom attendance is looked up with the signup service's Zoom credentials.
+func newRosterExporterFromConfig(cfg Config, logger *slog.Logger, mongoClient *mongo.Client, dbName string) *rosterExporter {
+	return &rosterExporter{
+		sessions: notify.NewMongoService(mongoClient, dbName),
+		codes:    mongodb.New(dbName, mongoClient),
+		attendance: NewZoomService(ZoomOptions{
+			baseAPIOverride:   cfg.Zoom.APIBase,
+			baseOAuthOverride: cfg.Zoom.OAuthBase,
+			clientID:          cfg.Zoom.ClientID,
+			clientSecret:      cfg.Zoom.ClientSecret,
+			accountID:         cfg.Zoom.AccountID,
+		}),
+		programs: cfg.programs(),
+		logger:   logger.With("service", "roster"),
+	}
+}
+
+// NewEmailWebhookServer handles Mailgun delivery events for welcome emails.
+func NewEmailWebhookServer(cfg Config, logger *slog.Logger, mongoClient *mongo.Client, dbName string, smsLimiter *sms.Limiter, smsTemplates *templates.Registry) *email.WebhookServer {
+	gldbService := mongodb.New(dbName, mongoClient)
+
+	return email.NewWebhookServer(email.WebhookOpts{
+		SigningKey: cfg.Mailgun.WebhookSigningKey,
+		Store:      gldbService,
+		Notifier:   NewSlackService(cfg.Slack.WebhookURL),
+		SMSService: newTwilioServiceFromConfig(cfg, smsLimiter, smsTemplates, gldbService),
+		Templates:  smsTemplates,
+		Logger:     logger,
 	})
+}
 
-	mongoClient, dbName, err := getMongoClient()
-	if err != nil && os.Getenv("CI") != "true" {
-		log.Fatalf("Could not connect to MongoDB: %v\n", err)
-	}
+func NewSignupServer(cfg Config, logger *slog.Logger, mongoClient *mongo.Client, dbName string, smsLimiter *sms.Limiter, smsTemplates *templates.Registry, emailSender email.Sender) (*signupServer, error) {
+	// Set up services/tasks to run when someone signs up for an Info Session.
+	glSvc := NewGreenlightService(cfg.Greenlight.WebhookURL, cfg.Greenlight.APIKey)
+
+	slackSvc := NewSlackService(cfg.Slack.WebhookURL, WithGreenlightHost(cfg.Greenlight.Host))
+
+	zoomSvc := NewZoomService(ZoomOptions{
+		baseAPIOverride:   cfg.Zoom.APIBase,
+		baseOAuthOverride: cfg.Zoom.OAuthBase,
+		clientID:          cfg.Zoom.ClientID,
+		clientSecret:      cfg.Zoom.ClientSecret,
+		accountID:         cfg.Zoom.AccountID,
+	})
 
 	gldbService := mongodb.New(dbName, mongoClient)
-	snapMailURL := os.Getenv("SNAP_MAIL_URL")
-	snapMailSvc := NewSnapMail(snapMailURL, WithSigningSecret(os.Getenv("SIGNING_SECRET")))
+
+	mgSvc := NewMailgunService(cfg.Mailgun.Domain, cfg.Mailgun.APIKey, cfg.Mailgun.APIBase,
+		WithSender(emailSender),
+		WithDeliveryStore(gldbService),
+		WithAppEnv(cfg.AppEnv),
+		WithPrograms(cfg.programs()),
+		WithMailgunGreenlightHost(cfg.Greenlight.Host),
+	)
+
+	twilioSvc := newTwilioServiceFromConfig(cfg, smsLimiter, smsTemplates, gldbService)
+	snapMailSvc := NewSnapMail(cfg.SnapMail.URL, WithSigningSecret(cfg.SnapMail.SigningSecret))
 
 	convoLinkSvc := conversations.NewService(
-		conversations.WithMessengerAPIBase(osMessagingSvcURL+"/api/v0"),
-		conversations.WithSigningSecret(osMessagingSigningSecret),
+		conversations.WithMessengerAPIBase(cfg.Messaging.ServiceURL+"/api/v0"),
+		conversations.WithSigningSecret(cfg.Messaging.SigningSecret),
+		conversations.WithHTTPError(func(vendor string, resp *http.Response) error {
+			return newHTTPError(vendor, resp)
+		}),
 	)
 
 	logger = logger.With("service", "signup")
 
-	registrationService := newSignupService(
+	registrationService, err := newSignupService(
 		signupServiceOptions{
-			meetings: map[int]string{
-				12: zoomMeeting12,
-				17: zoomMeeting17,
-			},
+			// Zoom meetings and templates by program,
+			programs: cfg.programs(),
 			// registering the user for the Zoom meeting,
 			zoomService: zoomSvc,
 			gldbService: gldbService,
 			// Registration tasks:
-			// (executed concurrently)
+			// (executed concurrently, each once the fields it consumes are set)
 			tasks: []mutationTask{
 				// posting a WebHook to Greenlight,
 				glSvc,
@@ -251,15 +394,29 @@ func NewSignupServer(logger *slog.Logger) *signupServer {
 				// sending Signup message to SNAP mail application
 				snapMailSvc,
 			},
+			// linking the SMS conversation to the Greenlight signup.
 			postSignupTasks: []Runner{convoLinkSvc},
-			logger:          logger,
+			taskOptions:     cfg.Tasks.pipelineOptions(),
+			// Saved signups can be acted on from Slack.
+			store:             gldbService,
+			confirmationTasks: []mutationTask{mgSvc, twilioSvc},
+			greenlightHost:    cfg.Greenlight.Host,
+			rendererURL:       cfg.RendererURL,
+			shortener:         NewURLShortener(ShortenerOpts{apiOverride: cfg.ShortenerURL, apiKey: cfg.ShortenerAPIKey}),
+			logger:            logger,
 		},
 	)
 
-	return &signupServer{
-		service: registrationService,
-		logger:  logger,
+	if err != nil {
+		return nil, fmt.Errorf("newSignupService: %w", err)
 	}
+
+	return &signupServer{
+		service:   registrationService,
+		logger:    logger,
+		templates: smsTemplates,
+		guard:     configureSignupGuard(cfg, gldbService, logger),
+	}, nil
 }
 
 func getGitRev() string {
@@ -40,6 +40,16 @@ func (g greenlightService) name() string {
 	return "greenlight service"
 }
 
+// The webhook sends the user join code and sets the Greenlight signup ID.
+func (g greenlightService) spec() taskSpec {
+	return taskSpec{
+		key:         taskGreenlight,
+		consumes:    []signupField{fieldJoinCode},
+		produces:    []signupField{fieldSignupID},
+		integration: "greenlight",
+	}
+}
+
 type signupResp struct {
 	Status   string `json:"status"`
 	SignupID string `json:"signupId"`
@@ -58,6 +68,8 @@ type signupReq struct {
 	Email string `json:"email" schema:"email"`
 	// The session's location's Google Place details.
 	GooglePlace greenlight.GooglePlace `json:"googlePlace" schema:"googlePlace"`
+	// The person's preferred language for messages. "en" | "es".
+	Language string `json:"language"`
 	// Session's set location type. One of "IN_PERSON" | "VIRTUAL" | "IN_PERSON". If the session's location type is "HYBRID", a student can attend "IN_PERSON" or "VIRTUAL"ly.
 	LocationType string `json:"locationType" schema:"locationType"`
 	// A legacy 4-character join code for a Greenlight session.
@@ -74,6 +86,13 @@ type signupReq struct {
 	Token         string    `json:"token" schema:"token"`
 	// State or country where the person resides.
 	UserLocation string `json:"userLocation" schema:"userLocation"`
+	// Marketing attribution.
+	UTMSource   string `json:"utmSource,omitempty"`
+	UTMMedium   string `json:"utmMedium,omitempty"`
+	UTMCampaign string `json:"utmCampaign,omitempty"`
+	UTMTerm     string `json:"utmTerm,omitempty"`
+	UTMContent  string `json:"utmContent,omitempty"`
+	LandingPage string `json:"landingPage,omitempty"`
 }
 
 // PostWebhook sends a webhook to Greenlight (POST /signup).
@@ -85,6 +104,7 @@ func (g greenlightService) postWebhook(ctx context.Context, su *Signup) error {
 		Cohort:            su.Cohort,
 		Email:             su.Email,
 		GooglePlace:       su.GooglePlace,
+		Language:          string(su.lang()),
 		LocationType:      su.LocationType,
 		JoinCode:          su.userJoinCode,
 		NameFirst:         su.NameFirst,
@@ -97,6 +117,12 @@ func (g greenlightService) postWebhook(ctx context.Context, su *Signup) error {
 		StartDateTime:     su.StartDateTime,
 		Token:             su.Token,
 		UserLocation:      su.UserLocation,
+		UTMSource:         su.UTMSource,
+		UTMMedium:         su.UTMMedium,
+		UTMCampaign:       su.UTMCampaign,
+		UTMTerm:           su.UTMTerm,
+		UTMContent:        su.UTMContent,
+		LandingPage:       su.LandingPage,
 	}
 
 	reqBody, err := json.Marshal(signupReq)
@@ -123,7 +149,7 @@ func (g greenlightService) postWebhook(ctx context.Context, su *Signup) error {
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode >= 300 {
-		return handleHTTPError(resp)
+		return newHTTPError("greenlight", resp)
 	}
 
 	suResp := &signupResp{}
@@ -29,6 +29,9 @@ func TestPostWebhook(t *testing.T) {
 			UserLocation:      "Louisiana",
 			AttendingLocation: "IN_PERSON",
 			SMSOptIn:          true,
+			UTMSource:         "instagram",
+			UTMMedium:         "social",
+			UTMCampaign:       "spring-info-sessions",
 		}
 		mockGreenlightSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Sends API Key header
@@ -52,6 +55,9 @@ func TestPostWebhook(t *testing.T) {
 			require.Equal(t, "IN_PERSON", glReq.AttendingLocation)
 			require.Equal(t, false, glReq.SMSOptOut)
 			require.Equal(t, "2022-03-14T17:00:00Z", glReq.StartDateTime.Format(time.RFC3339))
+			require.Equal(t, "instagram", glReq.UTMSource)
+			require.Equal(t, "social", glReq.UTMMedium)
+			require.Equal(t, "spring-info-sessions", glReq.UTMCampaign)
 
 			// Responds with a signup ID
 			resp := signupResp{
@@ -0,0 +1,131 @@
+package signup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/operationspark/service-signup/antispam"
+)
+
+type (
+	// signupGuard screens signups for bots and spam before they trigger Zoom, Twilio, Mailgun, etc.
+	signupGuard struct {
+		// Verifies the signup's CAPTCHA token. Optional.
+		captcha      antispam.Verifier
+		ipLimiter    *antispam.RateLimiter
+		emailLimiter *antispam.RateLimiter
+		// When true, suspicious signups are saved for review and the client gets a normal response.
+		// Otherwise, they are rejected.
+		quarantine bool
+		// Saves quarantined signups.
+		store  signupStore
+		logger *slog.Logger
+	}
+
+	signupGuardOptions struct {
+		captcha      antispam.Verifier
+		ipLimiter    *antispam.RateLimiter
+		emailLimiter *antispam.RateLimiter
+		quarantine   bool
+		store        signupStore
+		logger       *slog.Logger
+	}
+
+	// suspiciousSignupError is returned for signups that look like bots or spam.
+	suspiciousSignupError struct {
+		Reason string
+	}
+)
+
+var errRateLimited = errors.New("too many signups")
+
+func newSignupGuard(o signupGuardOptions) *signupGuard {
+	logger := o.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &signupGuard{
+		captcha:      o.captcha,
+		ipLimiter:    o.ipLimiter,
+		emailLimiter: o.emailLimiter,
+		quarantine:   o.quarantine,
+		store:        o.store,
+		logger:       logger.With("service", "signup-guard"),
+	}
+}
+
+func (e *suspiciousSignupError) Error() string {
+	return "suspicious signup: " + e.Reason
+}
+
+// Check returns errRateLimited if the IP address or email has too many recent signups, or a *suspiciousSignupError if the signup looks like a bot or spam.
+func (g *signupGuard) check(ctx context.Context, su Signup, ip string) error {
+	if !g.ipLimiter.Allow(ip) {
+		return fmt.Errorf("%w from %s", errRateLimited, ip)
+	}
+	if !g.emailLimiter.Allow(strings.ToLower(strings.TrimSpace(su.Email))) {
+		return fmt.Errorf("%w for %s", errRateLimited, su.Email)
+	}
+
+	// People can't see the honeypot field, but bots fill it in.
+	if su.Website != "" {
+		return &suspiciousSignupError{Reason: "honeypot field filled"}
+	}
+
+	if antispam.IsDisposableEmail(su.Email) {
+		return &suspiciousSignupError{Reason: "disposable email domain"}
+	}
+
+	if g.captcha != nil {
+		err := g.captcha.Verify(ctx, su.Token, ip)
+		if errors.Is(err, antispam.ErrInvalidToken) {
+			return &suspiciousSignupError{Reason: err.Error()}
+		}
+		if err != nil {
+			// Don't turn people away when the CAPTCHA service is down.
+			g.logger.ErrorContext(ctx, fmt.Errorf("captcha.Verify: %w", err).Error())
+		}
+	}
+	return nil
+}
+
+// Hold saves a suspicious signup for review without running any of the signup tasks.
+func (g *signupGuard) hold(ctx context.Context, su Signup, reason string) error {
+	if g.store == nil {
+		return errors.New("signup store is not configured")
+	}
+
+	id, err := newRecordID()
+	if err != nil {
+		return fmt.Errorf("newRecordID: %w", err)
+	}
+	su.recordID = id
+
+	r := newSignupRecord(su)
+	r.Status = signupStatusQuarantined
+	r.QuarantineReason = reason
+	if err := g.store.SaveSignup(ctx, id, r); err != nil {
+		return fmt.Errorf("saveSignup: %w", err)
+	}
+	return nil
+}
+
+// ClientIP returns the IP address of the client. Cloud Functions run behind Google's front end, which appends the address it received the request from to X-Forwarded-For. Earlier entries come from the client and can be forged, so only the last one is used.
+func clientIP(r *http.Request) string {
+	if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+		hops := strings.Split(fwd, ",")
+		if last := strings.TrimSpace(hops[len(hops)-1]); last != "" {
+			return last
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,133 @@
+package signup
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/operationspark/service-signup/antispam"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignupGuard(t *testing.T) {
+	valid := Signup{
+		NameFirst: "Henri",
+		NameLast:  "Testaroni",
+		Email:     "henri@email.com",
+		Cell:      "555-123-4567",
+		Token:     "captcha-token",
+	}
+
+	newServer := func(quarantine bool) (*signupServer, *mockSignupStore, *bool) {
+		registered := false
+		store := &mockSignupStore{records: map[string]signupRecord{}}
+		server := &signupServer{
+			service: &MockSignupService{
+				RegisterFunc: func(ctx context.Context, su Signup) (Signup, error) {
+					registered = true
+					return su, nil
+				},
+			},
+			logger: slog.Default(),
+			guard: newSignupGuard(signupGuardOptions{
+				captcha:      antispam.Fake{},
+				ipLimiter:    antispam.NewRateLimiter(2, time.Hour),
+				emailLimiter: antispam.NewRateLimiter(1, time.Hour),
+				quarantine:   quarantine,
+				store:        store,
+			}),
+		}
+		return server, store, &registered
+	}
+
+	post := func(server *signupServer, su Signup, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", signupToJSON(t, su))
+		req.Header.Set("Content-Type", "application/json")
+		// Clients can send their own X-Forwarded-For; the proxy appends the real address.
+		req.Header.Set("X-Forwarded-For", "10.0.0.1, "+ip)
+		res := httptest.NewRecorder()
+		server.HandleSignUp(res, req)
+		return res
+	}
+
+	t.Run("registers signups that pass", func(t *testing.T) {
+		server, _, registered := newServer(false)
+
+		res := post(server, valid, "1.2.3.4")
+
+		require.Equal(t, http.StatusCreated, res.Code)
+		require.True(t, *registered)
+	})
+
+	t.Run("limits signups per email and per IP", func(t *testing.T) {
+		server, _, _ := newServer(false)
+
+		require.Equal(t, http.StatusCreated, post(server, valid, "1.2.3.4").Code)
+		require.Equal(t, http.StatusTooManyRequests, post(server, valid, "5.6.7.8").Code)
+
+		other := valid
+		other.Email = "other@email.com"
+		require.Equal(t, http.StatusCreated, post(server, other, "1.2.3.4").Code)
+		other.Email = "another@email.com"
+		require.Equal(t, http.StatusTooManyRequests, post(server, other, "1.2.3.4").Code)
+	})
+
+	t.Run("ignores client-supplied X-Forwarded-For entries", func(t *testing.T) {
+		server, _, _ := newServer(false)
+
+		for i, email := range []string{"a@email.com", "b@email.com", "c@email.com"} {
+			su := valid
+			su.Email = email
+			req := httptest.NewRequest(http.MethodPost, "/", signupToJSON(t, su))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Forwarded-For", fmt.Sprintf("10.0.0.%d, 1.2.3.4", i))
+			res := httptest.NewRecorder()
+			server.HandleSignUp(res, req)
+
+			want := http.StatusCreated
+			if i == 2 {
+				want = http.StatusTooManyRequests
+			}
+			require.Equal(t, want, res.Code, email)
+		}
+	})
+
+	t.Run("rejects suspicious signups", func(t *testing.T) {
+		for name, mutate := range map[string]func(*Signup){
+			"honeypot":         func(su *Signup) { su.Website = "http://spam.example" },
+			"disposable email": func(su *Signup) { su.Email = "henri@mailinator.com" },
+			"invalid captcha":  func(su *Signup) { su.Token = antispam.FakeInvalidToken },
+		} {
+			t.Run(name, func(t *testing.T) {
+				server, _, registered := newServer(false)
+				su := valid
+				mutate(&su)
+
+				res := post(server, su, "1.2.3.4")
+
+				require.Equal(t, http.StatusBadRequest, res.Code)
+				require.False(t, *registered)
+			})
+		}
+	})
+
+	t.Run("quarantines suspicious signups for review", func(t *testing.T) {
+		server, store, registered := newServer(true)
+		su := valid
+		su.Email = "henri@yopmail.com"
+
+		res := post(server, su, "1.2.3.4")
+
+		require.Equal(t, http.StatusCreated, res.Code)
+		require.False(t, *registered, "signup tasks should not run")
+		require.Len(t, store.records, 1)
+		for _, r := range store.records {
+			require.Equal(t, signupStatusQuarantined, r.Status)
+			require.Equal(t, "disposable email domain", r.QuarantineReason)
+		}
+	})
+}
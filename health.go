@@ -0,0 +1,183 @@
+package signup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// DependencyCheck returns an error if a service dependency cannot be used.
+	dependencyCheck struct {
+		name  string
+		check func(context.Context) error
+	}
+
+	healthOptions struct {
+		checks []dependencyCheck
+		// Maximum time each check can take. Defaults to 5 seconds.
+		timeout time.Duration
+		// How long a readiness report is reused before the dependencies are checked again. Keeps frequent probes from hammering vendor APIs. 0 checks on every request.
+		cacheTTL time.Duration
+	}
+
+	healthServer struct {
+		opts healthOptions
+
+		// Guards the cached report. Held while checking so concurrent probes wait for one set of checks.
+		mu     sync.Mutex
+		report readinessReport
+		// Set when the server is shutting down.
+		draining atomic.Bool
+	}
+
+	readinessReport struct {
+		// "ok" if every dependency is healthy, otherwise "unavailable".
+		Status       string                      `json:"status"`
+		CheckedAt    time.Time                   `json:"checkedAt"`
+		Dependencies map[string]dependencyStatus `json:"dependencies"`
+	}
+
+	dependencyStatus struct {
+		// "ok" | "error"
+		Status    string `json:"status"`
+		Error     string `json:"error,omitempty"`
+		LatencyMS int64  `json:"latencyMs"`
+	}
+)
+
+const (
+	healthStatusOK          = "ok"
+	healthStatusError       = "error"
+	healthStatusUnavailable = "unavailable"
+	healthStatusDraining    = "draining"
+)
+
+func newHealthServer(o healthOptions) *healthServer {
+	if o.timeout <= 0 {
+		o.timeout = 5 * time.Second
+	}
+	return &healthServer{opts: o}
+}
+
+// HandleLiveness responds 200 as long as the process can serve requests. It does not check dependencies, so a vendor outage does not get the service restarted.
+func (hs *healthServer) HandleLiveness(w http.ResponseWriter, r *http.Request) {
+	writeHealthJSON(w, http.StatusOK, map[string]string{
+		"status":  healthStatusOK,
+		"gitHash": getGitRev(),
+	})
+}
+
+// HandleReadiness checks every dependency concurrently and responds with each one's status. The response is 503 if any check fails.
+func (hs *healthServer) HandleReadiness(w http.ResponseWriter, r *http.Request) {
+	if hs.draining.Load() {
+		writeHealthJSON(w, http.StatusServiceUnavailable, map[string]string{"status": healthStatusDraining})
+		return
+	}
+	report := hs.readiness(r.Context())
+	status := http.StatusOK
+	if report.Status != healthStatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	writeHealthJSON(w, status, report)
+}
+
+// Drain fails readiness checks from now on so no new requests are routed to the server.
+func (hs *healthServer) drain() {
+	hs.draining.Store(true)
+}
+
+// Readiness returns the cached report if it is fresh, or checks the dependencies again.
+func (hs *healthServer) readiness(ctx context.Context) readinessReport {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	if !hs.report.CheckedAt.IsZero() && time.Since(hs.report.CheckedAt) < hs.opts.cacheTTL {
+		return hs.report
+	}
+
+	// The report is shared with other requests, so a client disconnecting should not cancel the checks.
+	ctx = context.WithoutCancel(ctx)
+
+	report := readinessReport{
+		Status:       healthStatusOK,
+		CheckedAt:    time.Now(),
+		Dependencies: make(map[string]dependencyStatus, len(hs.opts.checks)),
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for _, c := range hs.opts.checks {
+		wg.Add(1)
+		go func(c dependencyCheck) {
+			defer wg.Done()
+			ds := hs.run(ctx, c)
+			mu.Lock()
+			defer mu.Unlock()
+			report.Dependencies[c.name] = ds
+			if ds.Status != healthStatusOK {
+				report.Status = healthStatusUnavailable
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	hs.report = report
+	return report
+}
+
+// Run runs a single check with the timeout. Some clients (Ex: Twilio) do not accept a context, so a check that ignores the deadline is abandoned instead of waited on.
+func (hs *healthServer) run(ctx context.Context, c dependencyCheck) dependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, hs.opts.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- c.check(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s", hs.opts.timeout)
+	}
+
+	ds := dependencyStatus{Status: healthStatusOK, LatencyMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		ds.Status = healthStatusError
+		ds.Error = err.Error()
+	}
+	return ds
+}
+
+// CheckReachable returns an error if the URL does not respond, or responds with a server error. Client errors (Ex: 404 or 401 for a HEAD request on an API endpoint) mean the service is up.
+func checkReachable(ctx context.Context, client *http.Client, url string) error {
+	if url == "" {
+		return errors.New("URL not configured")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return fmt.Errorf("newRequestWithContext: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("client.Do: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("response code: %s", resp.Status)
+	}
+	return nil
+}
+
+func writeHealthJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,105 @@
+package signup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleReadiness(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+
+	getReport := func(t *testing.T, hs *healthServer) (int, readinessReport) {
+		t.Helper()
+		res := httptest.NewRecorder()
+		hs.HandleReadiness(res, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var report readinessReport
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&report))
+		return res.Code, report
+	}
+
+	t.Run("responds 200 when every dependency is healthy", func(t *testing.T) {
+		hs := newHealthServer(healthOptions{checks: []dependencyCheck{
+			{name: "mongo", check: ok},
+			{name: "zoom", check: ok},
+		}})
+
+		code, report := getReport(t, hs)
+
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, "ok", report.Status)
+		require.Equal(t, "ok", report.Dependencies["mongo"].Status)
+		require.Equal(t, "ok", report.Dependencies["zoom"].Status)
+	})
+
+	t.Run("responds 503 with the failing dependency's error", func(t *testing.T) {
+		hs := newHealthServer(healthOptions{checks: []dependencyCheck{
+			{name: "mongo", check: ok},
+			{name: "twilio", check: func(context.Context) error { return errors.New("401 Unauthorized") }},
+		}})
+
+		code, report := getReport(t, hs)
+
+		require.Equal(t, http.StatusServiceUnavailable, code)
+		require.Equal(t, "unavailable", report.Status)
+		require.Equal(t, "ok", report.Dependencies["mongo"].Status)
+		require.Equal(t, "error", report.Dependencies["twilio"].Status)
+		require.Equal(t, "401 Unauthorized", report.Dependencies["twilio"].Error)
+	})
+
+	t.Run("times out slow checks", func(t *testing.T) {
+		hs := newHealthServer(healthOptions{
+			timeout: 10 * time.Millisecond,
+			checks: []dependencyCheck{
+				// Ignores the context like the Twilio client does.
+				{name: "twilio", check: func(context.Context) error { time.Sleep(time.Second); return nil }},
+			},
+		})
+
+		code, report := getReport(t, hs)
+
+		require.Equal(t, http.StatusServiceUnavailable, code)
+		require.Contains(t, report.Dependencies["twilio"].Error, "timed out")
+	})
+
+	t.Run("reuses results within the cache TTL", func(t *testing.T) {
+		var calls atomic.Int32
+		hs := newHealthServer(healthOptions{
+			cacheTTL: time.Minute,
+			checks: []dependencyCheck{{name: "zoom", check: func(context.Context) error {
+				calls.Add(1)
+				return nil
+			}}},
+		})
+
+		getReport(t, hs)
+		getReport(t, hs)
+		require.Equal(t, int32(1), calls.Load())
+
+		hs.report.CheckedAt = time.Now().Add(-2 * time.Minute)
+		getReport(t, hs)
+		require.Equal(t, int32(2), calls.Load())
+	})
+}
+
+func TestCheckReachable(t *testing.T) {
+	status := http.StatusNotFound
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	require.NoError(t, checkReachable(context.Background(), srv.Client(), srv.URL))
+
+	status = http.StatusBadGateway
+	require.Error(t, checkReachable(context.Background(), srv.Client(), srv.URL))
+
+	require.Error(t, checkReachable(context.Background(), srv.Client(), ""))
+}
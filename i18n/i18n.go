@@ -0,0 +1,143 @@
+// Package i18n provides translated message copy and locale-aware date formatting for participant-facing messages.
+// SMS message bodies live in the templates package.
+package i18n
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type (
+	// Language is an ISO 639-1 language code. Ex: "en", "es".
+	Language string
+
+	// Key identifies a message in the catalog.
+	Key string
+)
+
+const (
+	English Language = "en"
+	Spanish Language = "es"
+)
+
+const (
+	// Welcome email subject line.
+	KeyWelcomeSubject Key = "welcomeSubject"
+
+	// Date and time layouts (Go reference time) used in messages.
+	KeyLayoutWeekday     Key = "layoutWeekday"     // Ex: "Monday"
+	KeyLayoutShortDate   Key = "layoutShortDate"   // Ex: "Mon Jan 02"
+	KeyLayoutLongDate    Key = "layoutLongDate"    // Ex: "Monday, Jan 02"
+	KeyLayoutNumericDate Key = "layoutNumericDate" // Ex: "1/2"
+	KeyLayoutShortTime   Key = "layoutShortTime"   // Ex: "3:04p MST"
+	KeyLayoutTime        Key = "layoutTime"        // Ex: "3:04 PM MST"
+	KeyLayoutCompactTime Key = "layoutCompactTime" // Ex: "3:04PM MST"
+)
+
+// Languages lists every supported language.
+var Languages = []Language{English, Spanish}
+
+var catalog = map[Language]map[Key]string{
+	English: {
+		KeyWelcomeSubject: "Welcome from Operation Spark!",
+
+		KeyLayoutWeekday:     "Monday",
+		KeyLayoutShortDate:   "Mon Jan 02",
+		KeyLayoutLongDate:    "Monday, Jan 02",
+		KeyLayoutNumericDate: " 1/2",
+		KeyLayoutShortTime:   "3:04p MST",
+		KeyLayoutTime:        "3:04 PM MST",
+		KeyLayoutCompactTime: "3:04PM MST",
+	},
+	Spanish: {
+		KeyWelcomeSubject: "¡Te damos la bienvenida a Operation Spark!",
+
+		KeyLayoutWeekday:     "Monday",
+		KeyLayoutShortDate:   "Mon 02 Jan",
+		KeyLayoutLongDate:    "Monday, 02 Jan",
+		KeyLayoutNumericDate: " 2/1",
+		KeyLayoutShortTime:   "3:04p MST",
+		KeyLayoutTime:        "3:04 PM MST",
+		KeyLayoutCompactTime: "3:04PM MST",
+	},
+}
+
+// Translations of English weekday and month names. Full names must come before their abbreviations.
+var dateNames = map[Language][]string{
+	Spanish: {
+		"Monday", "lunes",
+		"Tuesday", "martes",
+		"Wednesday", "miércoles",
+		"Thursday", "jueves",
+		"Friday", "viernes",
+		"Saturday", "sábado",
+		"Sunday", "domingo",
+		"Mon", "lun",
+		"Tue", "mar",
+		"Wed", "mié",
+		"Thu", "jue",
+		"Fri", "vie",
+		"Sat", "sáb",
+		"Sun", "dom",
+		"January", "enero",
+		"February", "febrero",
+		"March", "marzo",
+		"April", "abril",
+		"May", "mayo",
+		"June", "junio",
+		"July", "julio",
+		"August", "agosto",
+		"September", "septiembre",
+		"October", "octubre",
+		"November", "noviembre",
+		"December", "diciembre",
+		"Jan", "ene",
+		"Feb", "feb",
+		"Mar", "mar",
+		"Apr", "abr",
+		"Jun", "jun",
+		"Jul", "jul",
+		"Aug", "ago",
+		"Sep", "sep",
+		"Oct", "oct",
+		"Nov", "nov",
+		"Dec", "dic",
+	},
+}
+
+// Parse normalizes a language code or name to a supported Language. Unknown or empty values default to English.
+// Ex: "es-MX", "ES", "spanish", "español" -> Spanish
+func Parse(s string) Language {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch {
+	case s == "es", strings.HasPrefix(s, "es-"), strings.HasPrefix(s, "es_"), s == "spanish", s == "español", s == "espanol":
+		return Spanish
+	default:
+		return English
+	}
+}
+
+// T returns the message for the given key in the given language, formatted with args. Messages missing from a language fall back to English.
+func T(lang Language, key Key, args ...any) string {
+	msg, ok := catalog[Parse(string(lang))][key]
+	if !ok {
+		msg = catalog[English][key]
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// FormatTime formats t using the language's layout for the given key, with weekday and month names translated.
+func FormatTime(lang Language, t time.Time, layout Key) string {
+	lang = Parse(string(lang))
+	formatted := t.Format(T(lang, layout))
+
+	names := dateNames[lang]
+	for i := 0; i+1 < len(names); i += 2 {
+		formatted = strings.ReplaceAll(formatted, names[i], names[i+1])
+	}
+	return formatted
+}
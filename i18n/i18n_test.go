@@ -0,0 +1,77 @@
+package i18n
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Language
+	}{
+		{"", English},
+		{"en", English},
+		{"en-US", English},
+		{"fr", English},
+		{"es", Spanish},
+		{"ES", Spanish},
+		{"es-MX", Spanish},
+		{"Spanish", Spanish},
+		{"Español", Spanish},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, Parse(tt.in), "Parse(%q)", tt.in)
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	t.Run("every message is translated", func(t *testing.T) {
+		for key := range catalog[English] {
+			for _, lang := range Languages {
+				require.NotEmpty(t, catalog[lang][key], "%q is missing a %q translation", key, lang)
+			}
+		}
+	})
+
+	t.Run("unsupported languages fall back to English", func(t *testing.T) {
+		require.Equal(t, "Welcome from Operation Spark!", T("de", KeyWelcomeSubject))
+	})
+
+	t.Run("translates messages", func(t *testing.T) {
+		got := T(Spanish, KeyWelcomeSubject)
+		require.Equal(t, "¡Te damos la bienvenida a Operation Spark!", got)
+	})
+}
+
+func TestFormatTime(t *testing.T) {
+	ctz, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+	halloween := time.Date(2022, 10, 31, 12, 0, 0, 0, ctz)
+
+	tests := []struct {
+		lang   Language
+		layout Key
+		want   string
+	}{
+		{English, KeyLayoutShortDate, "Mon Oct 31"},
+		{Spanish, KeyLayoutShortDate, "lun 31 oct"},
+		{English, KeyLayoutLongDate, "Monday, Oct 31"},
+		{Spanish, KeyLayoutLongDate, "lunes, 31 oct"},
+		{English, KeyLayoutNumericDate, " 10/31"},
+		{Spanish, KeyLayoutNumericDate, " 31/10"},
+		{Spanish, KeyLayoutShortTime, "12:00p CDT"},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, FormatTime(tt.lang, halloween, tt.layout))
+	}
+
+	t.Run("translates months that share a weekday abbreviation", func(t *testing.T) {
+		march := time.Date(2023, 3, 7, 12, 0, 0, 0, ctz) // Tuesday
+		require.Equal(t, "mar 07 mar", FormatTime(Spanish, march, KeyLayoutShortDate))
+	})
+}
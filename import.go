@@ -0,0 +1,506 @@
+package signup
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/mail"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/operationspark/service-signup/greenlight"
+	"github.com/operationspark/service-signup/sms"
+	"golang.org/x/sync/errgroup"
+)
+
+type (
+	// bulkRegisterer registers signups without some of the signup tasks. Implemented by SignupService.
+	bulkRegisterer interface {
+		registerWithout(ctx context.Context, su Signup, logger *slog.Logger, skip []string) (Signup, error)
+	}
+
+	// importSessionStore reads Greenlight sessions and their locations. Implemented by mongodb.MongodbService.
+	importSessionStore interface {
+		GetSession(ctx context.Context, id string, dst any) error
+		GetLocation(ctx context.Context, id string, dst any) error
+	}
+
+	// signupImporter registers signups collected offline. Ex: paper forms at in-person events.
+	signupImporter struct {
+		service  bulkRegisterer
+		sessions importSessionStore
+		logger   *slog.Logger
+	}
+
+	// ImportOptions control a bulk signup import.
+	ImportOptions struct {
+		// Keys of the signup tasks to skip for every row. Ex: "slack".
+		Skip []string
+		// Rows registered at once. Default: 4.
+		Concurrency int
+		// Validate the rows without registering them.
+		DryRun bool
+		// Largest number of rows in the file. Default (and upper limit): 500.
+		MaxRows int
+	}
+
+	// importReport is the result of each row of an import, in file order.
+	importReport struct {
+		Created int            `json:"created"`
+		Valid   int            `json:"valid"`
+		Invalid int            `json:"invalid"`
+		Failed  int            `json:"failed"`
+		Rows    []importResult `json:"rows"`
+	}
+
+	importResult struct {
+		// Line of the row in the CSV file. The header is line 1.
+		Line   int          `json:"line"`
+		Email  string       `json:"email"`
+		Status importStatus `json:"status"`
+		// Column that caused the error, for invalid rows.
+		Field     string `json:"field,omitempty"`
+		Error     string `json:"error,omitempty"`
+		ShortLink string `json:"shortLink,omitempty"`
+	}
+
+	importStatus string
+
+	importRow struct {
+		su     Signup
+		result importResult
+	}
+)
+
+const (
+	importCreated importStatus = "created"
+	// The row is valid. Only set on dry runs.
+	importValid   importStatus = "valid"
+	importInvalid importStatus = "invalid"
+	// Registration failed. The row can be imported again.
+	importFailed importStatus = "failed"
+)
+
+// Import limits.
+const (
+	defaultImportConcurrency = 4
+	maxImportConcurrency     = 16
+	maxImportRows            = 500
+	// The admin API registers rows while the request waits, so it takes far fewer than cmd/import. Dry runs can have up to maxImportRows.
+	maxHTTPImportRows = 50
+	// Largest CSV body accepted by the admin API.
+	maxImportBytes = 5 << 20
+)
+
+var errTooManyRows = errors.New("too many rows")
+
+// ImportColumns lists the CSV columns an import can have. Columns are matched case-insensitively.
+var importColumns = []string{
+	"nameFirst",
+	"nameLast",
+	"email",
+	"cell",
+	"sessionId",
+	"attendingLocation",
+	"language",
+	"smsOptIn",
+	"referrer",
+	"referrerResponse",
+	"userLocation",
+	"utmSource",
+	"utmMedium",
+	"utmCampaign",
+	"utmTerm",
+	"utmContent",
+}
+
+// ImportSignups registers the signups in the CSV read from r and writes the JSON import report to w.
+func ImportSignups(ctx context.Context, cfg Config, r io.Reader, w io.Writer, o ImportOptions) error {
+	mongoClient, dbName, err := getMongoClient(cfg)
+	if err != nil {
+		return fmt.Errorf("getMongoClient: %w", err)
+	}
+	defer func() { _ = mongoClient.Disconnect(context.Background()) }()
+
+	logger := slog.Default()
+	smsLimiter := sms.NewLimiter(cfg.SMS.MessagesPerSecond, 1)
+	smsTemplates := newTemplateRegistry(cfg, mongoClient, dbName)
+	signupServer, err := NewSignupServer(cfg, logger, mongoClient, dbName, smsLimiter, smsTemplates, newEmailSender(cfg))
+	if err != nil {
+		return fmt.Errorf("newSignupServer: %w", err)
+	}
+
+	im := newSignupImporterFromConfig(logger, mongoClient, dbName, signupServer.service)
+	if im == nil {
+		return errors.New("signup service does not support imports")
+	}
+	report, err := im.importCSV(ctx, r, o)
+	if err != nil {
+		return fmt.Errorf("importCSV: %w", err)
+	}
+
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	if err := e.Encode(report); err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+	return nil
+}
+
+// ImportCSV validates every row, then registers the valid rows. It returns an error, without registering any rows, if the file can't be read, has unknown columns, or has too many rows.
+func (im *signupImporter) importCSV(ctx context.Context, r io.Reader, o ImportOptions) (importReport, error) {
+	for _, key := range o.Skip {
+		if !slices.Contains(signupTaskKeys, key) {
+			return importReport{}, fmt.Errorf("unknown task %q", key)
+		}
+	}
+	concurrency := o.Concurrency
+	if concurrency == 0 {
+		concurrency = defaultImportConcurrency
+	}
+	if concurrency < 1 || concurrency > maxImportConcurrency {
+		return importReport{}, fmt.Errorf("concurrency must be between 1 and %d", maxImportConcurrency)
+	}
+
+	maxRows := o.MaxRows
+	if maxRows == 0 || maxRows > maxImportRows {
+		maxRows = maxImportRows
+	}
+
+	rows, err := im.parseRows(ctx, r, maxRows)
+	if err != nil {
+		return importReport{}, err
+	}
+
+	if !o.DryRun {
+		g := errgroup.Group{}
+		g.SetLimit(concurrency)
+		for i := range rows {
+			row := &rows[i]
+			if row.result.Status != importValid {
+				continue
+			}
+			g.Go(func() error {
+				im.register(ctx, row, o.Skip)
+				return nil
+			})
+		}
+		_ = g.Wait()
+	}
+
+	report := importReport{Rows: make([]importResult, len(rows))}
+	for i, row := range rows {
+		report.Rows[i] = row.result
+		switch row.result.Status {
+		case importCreated:
+			report.Created++
+		case importValid:
+			report.Valid++
+		case importInvalid:
+			report.Invalid++
+		case importFailed:
+			report.Failed++
+		}
+	}
+	return report, nil
+}
+
+// Register registers the row's signup and sets the row's result.
+func (im *signupImporter) register(ctx context.Context, row *importRow, skip []string) {
+	logger := im.logger.With(
+		slog.Group("signup",
+			slog.String("email", row.su.Email),
+			slog.String("sessionID", row.su.SessionID),
+		),
+		slog.Int("line", row.result.Line),
+	)
+
+	su, err := im.service.registerWithout(ctx, row.su, logger, skip)
+	if err == nil {
+		row.result.Status = importCreated
+		row.result.ShortLink = su.ShortLink
+		return
+	}
+	if errResp, ok := userError(err); ok {
+		logger.InfoContext(ctx, "imported signup rejected by integration", slog.String("error", err.Error()))
+		row.result.Status = importInvalid
+		row.result.Field = errResp.Field
+		if errResp.Field == "phone" {
+			row.result.Field = "cell"
+		}
+		row.result.Error = errResp.Message
+		return
+	}
+	logger.ErrorContext(ctx, "imported signup failed", slog.String("error", err.Error()))
+	row.result.Status = importFailed
+	row.result.Error = err.Error()
+}
+
+// ParseRows reads the CSV, up to maxRows rows, and validates each row. Valid rows have the "valid" status.
+func (im *signupImporter) parseRows(ctx context.Context, r io.Reader, maxRows int) ([]importRow, error) {
+	cr := csv.NewReader(r)
+	// Rows with missing trailing cells are treated as blank.
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("empty CSV")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	// Spreadsheet apps often start UTF-8 CSV files with a byte order mark.
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		idx := slices.IndexFunc(importColumns, func(c string) bool { return strings.EqualFold(c, name) })
+		if idx < 0 {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		columns[importColumns[idx]] = i
+	}
+	for _, required := range []string{"nameFirst", "nameLast", "email", "cell"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing column %q", required)
+		}
+	}
+
+	sessions := map[string]*Signup{}
+	emails := map[string]int{}
+	var rows []importRow
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read: %w", err)
+		}
+		if len(rows) == maxRows {
+			return nil, fmt.Errorf("%w: the limit is %d", errTooManyRows, maxRows)
+		}
+		line, _ := cr.FieldPos(0)
+
+		value := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		if slices.IndexFunc(record, func(cell string) bool { return strings.TrimSpace(cell) != "" }) < 0 {
+			// Skip blank lines left by spreadsheets.
+			continue
+		}
+
+		row := importRow{result: importResult{Line: line, Email: value("email"), Status: importValid}}
+		field, msg, err := im.parseRow(ctx, &row.su, value, sessions)
+		switch {
+		case err != nil:
+			row.result.Status = importFailed
+			row.result.Error = err.Error()
+		case field != "":
+			row.result.Status = importInvalid
+			row.result.Field = field
+			row.result.Error = msg
+		default:
+			email := strings.ToLower(row.su.Email)
+			if first, ok := emails[email]; ok {
+				row.result.Status = importInvalid
+				row.result.Field = "email"
+				row.result.Error = fmt.Sprintf("Duplicate of line %d", first)
+			} else {
+				emails[email] = line
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// ParseRow fills in the signup from the row's values and its session. It returns the invalid column and a message for the person fixing the file, or an error if the session can't be loaded.
+func (im *signupImporter) parseRow(ctx context.Context, su *Signup, value func(string) string, sessions map[string]*Signup) (string, string, error) {
+	su.NameFirst = value("nameFirst")
+	su.NameLast = value("nameLast")
+	su.Email = value("email")
+	su.Cell = value("cell")
+	su.Language = strings.ToLower(value("language"))
+	su.Referrer = value("referrer")
+	su.ReferrerResponse = value("referrerResponse")
+	su.UserLocation = value("userLocation")
+	su.UTMSource = value("utmSource")
+	su.UTMMedium = value("utmMedium")
+	su.UTMCampaign = value("utmCampaign")
+	su.UTMTerm = value("utmTerm")
+	su.UTMContent = value("utmContent")
+
+	if su.NameFirst == "" {
+		return "nameFirst", "Required", nil
+	}
+	if su.NameLast == "" {
+		return "nameLast", "Required", nil
+	}
+	if addr, err := mail.ParseAddress(su.Email); err != nil || addr.Address != su.Email {
+		return "email", "Invalid Email Address", nil
+	}
+	cell, ok := normalizePhone(su.Cell)
+	if !ok {
+		return "cell", "Invalid Phone Number", nil
+	}
+	// The SMS tasks only strip dashes before adding the country code.
+	su.Cell = cell
+	if su.Language != "" && su.Language != "en" && su.Language != "es" {
+		return "language", `Must be "en" or "es"`, nil
+	}
+	optIn, ok := parseYesNo(value("smsOptIn"))
+	if !ok {
+		return "smsOptIn", `Must be "yes" or "no"`, nil
+	}
+	su.SMSOptIn = optIn
+
+	attending := strings.ToUpper(strings.NewReplacer(" ", "_", "-", "_").Replace(value("attendingLocation")))
+	if attending != "" && attending != "IN_PERSON" && attending != "VIRTUAL" {
+		return "attendingLocation", `Must be "IN_PERSON" or "VIRTUAL"`, nil
+	}
+	su.AttendingLocation = attending
+
+	sessionID := value("sessionId")
+	if sessionID == "" {
+		// Information requests without a session.
+		return "", "", nil
+	}
+	session, err := im.session(ctx, sessionID, sessions)
+	if err != nil {
+		return "", "", err
+	}
+	if session == nil {
+		return "sessionId", "Session not found", nil
+	}
+	su.SessionID = session.SessionID
+	su.StartDateTime = session.StartDateTime
+	su.Cohort = session.Cohort
+	su.LocationType = session.LocationType
+	su.ProgramID = session.ProgramID
+	su.GooglePlace = session.GooglePlace
+	if su.LocationType != "HYBRID" {
+		su.AttendingLocation = su.LocationType
+	} else if su.AttendingLocation == "" {
+		return "attendingLocation", "Required for hybrid sessions", nil
+	}
+	return "", "", nil
+}
+
+// Session returns the signup fields of the Greenlight session with the given ID, or nil if there is no such session. Sessions are cached in the given map.
+func (im *signupImporter) session(ctx context.Context, id string, cache map[string]*Signup) (*Signup, error) {
+	if su, ok := cache[id]; ok {
+		return su, nil
+	}
+	var session greenlight.Session
+	if err := optional(im.sessions.GetSession(ctx, id, &session)); err != nil {
+		return nil, fmt.Errorf("getSession: %w", err)
+	}
+	if session.ID == "" {
+		cache[id] = nil
+		return nil, nil
+	}
+
+	var loc greenlight.Location
+	if session.LocationID != "" {
+		if err := optional(im.sessions.GetLocation(ctx, session.LocationID, &loc)); err != nil {
+			return nil, fmt.Errorf("getLocation: %w", err)
+		}
+	}
+	su := &Signup{
+		SessionID:     session.ID,
+		StartDateTime: session.Times.Start.DateTime,
+		Cohort:        session.Cohort,
+		LocationType:  session.LocationType,
+		ProgramID:     session.ProgramID,
+		GooglePlace:   loc.GooglePlace,
+	}
+	cache[id] = su
+	return su, nil
+}
+
+// NormalizePhone returns the 10 digits of a US number, ignoring formatting and the country code. It returns false unless the number has 10 digits, or 11 starting with the US country code.
+func normalizePhone(number string) (string, bool) {
+	var digits []rune
+	for _, r := range number {
+		switch {
+		case r >= '0' && r <= '9':
+			digits = append(digits, r)
+		case strings.ContainsRune(" ()-.+", r):
+		default:
+			return "", false
+		}
+	}
+	if len(digits) == 11 && digits[0] == '1' {
+		digits = digits[1:]
+	}
+	if len(digits) != 10 {
+		return "", false
+	}
+	return string(digits), true
+}
+
+// ParseYesNo parses the answer to a yes or no question. Blank means no.
+func parseYesNo(s string) (bool, bool) {
+	switch strings.ToLower(s) {
+	case "", "n", "no", "false", "0":
+		return false, true
+	case "y", "yes", "true", "1", "x":
+		return true, true
+	}
+	return false, false
+}
+
+// HandleImport registers the signups in a CSV request body and responds with the import report.
+// The request waits for every row to be registered, so files are limited to maxHTTPImportRows rows. Larger files can be validated here with dryRun, then imported with cmd/import.
+//
+//	POST /admin/imports?skip=slack,snapMail&concurrency=4&dryRun=true
+func (s *adminServer) handleImport(w http.ResponseWriter, r *http.Request) {
+	if s.importer == nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	q := r.URL.Query()
+	o := ImportOptions{}
+	if skip := q.Get("skip"); skip != "" {
+		o.Skip = strings.Split(skip, ",")
+	}
+	concurrency, err := positiveParam(q, "concurrency", defaultImportConcurrency)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	o.Concurrency = concurrency
+	if raw := q.Get("dryRun"); raw != "" {
+		if o.DryRun, err = strconv.ParseBool(raw); err != nil {
+			http.Error(w, fmt.Sprintf("invalid 'dryRun': %q", raw), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if !o.DryRun {
+		o.MaxRows = maxHTTPImportRows
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxImportBytes)
+	report, err := s.importer.importCSV(r.Context(), body, o)
+	if errors.Is(err, errTooManyRows) {
+		http.Error(w, err.Error()+". Import larger files with cmd/import.", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.writeJSON(w, r, report)
+}
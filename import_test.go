@@ -0,0 +1,194 @@
+package signup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/operationspark/service-signup/greenlight"
+	"github.com/stretchr/testify/require"
+)
+
+type mockBulkRegisterer struct {
+	mu         sync.Mutex
+	registered []Signup
+	skipped    []string
+	// Errors to return, by email.
+	errs map[string]error
+}
+
+func (m *mockBulkRegisterer) registerWithout(ctx context.Context, su Signup, logger *slog.Logger, skip []string) (Signup, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.skipped = skip
+	if err := m.errs[su.Email]; err != nil {
+		return su, err
+	}
+	m.registered = append(m.registered, su)
+	su.ShortLink = "https://ospk.org/" + su.NameFirst
+	return su, nil
+}
+
+func TestImportCSV(t *testing.T) {
+	start := mustMakeTime(t, time.RFC822, "16 Nov 22 18:00 UTC")
+	hybrid := greenlight.Session{ID: "session-1", Cohort: "is-nov-16-22-12pm", LocationType: "HYBRID", LocationID: "loc-1", ProgramID: "5sTmB97DzcqCwEZFR"}
+	hybrid.Times.Start.DateTime = start
+	virtual := greenlight.Session{ID: "session-2", LocationType: "VIRTUAL"}
+	store := &mockAdminStore{
+		sessions: map[string]greenlight.Session{"session-1": hybrid, "session-2": virtual},
+		locations: map[string]greenlight.Location{
+			"loc-1": {ID: "loc-1", GooglePlace: greenlight.GooglePlace{Name: "Operation Spark", Address: "514 Franklin Ave, New Orleans, LA 70117, USA"}},
+		},
+	}
+
+	csv := strings.Join([]string{
+		"\ufeffNameFirst,nameLast,email,cell,sessionId,attendingLocation,smsOptIn,utmSource",
+		"Henri,Testaroni,henri@email.com,(555) 123-4567,session-1,in person,yes,career-fair",
+		"Halle,Bot,halle@email.com,555.987.6543,session-2,,no,career-fair",
+		",,,,,,,",
+		"Bad,Email,not-an-email,555-123-4567,,,,",
+		"Bad,Phone,phone@email.com,555-1234,,,,",
+		"No,Session,nosession@email.com,5551234567,missing,,,",
+		"No,Location,noloc@email.com,5551234567,session-1,,,",
+		"Dupe,Henri,HENRI@email.com,5551234567,,,,",
+		"Twilio,Rejected,twilio@email.com,5551234567,,,,",
+		"Zoom,Down,zoom@email.com,5551234567,,,,",
+	}, "\n")
+
+	t.Run("registers valid rows and reports invalid rows", func(t *testing.T) {
+		service := &mockBulkRegisterer{errs: map[string]error{
+			"twilio@email.com": ErrInvalidNumber{err: errors.New("invalid number")},
+			"zoom@email.com":   &HTTPError{Vendor: "zoom", StatusCode: http.StatusServiceUnavailable},
+		}}
+		im := &signupImporter{service: service, sessions: store, logger: slog.Default()}
+
+		report, err := im.importCSV(context.Background(), strings.NewReader(csv), ImportOptions{Skip: []string{taskSlack}, Concurrency: 2})
+		require.NoError(t, err)
+
+		require.Equal(t, 2, report.Created)
+		require.Equal(t, 6, report.Invalid)
+		require.Equal(t, 1, report.Failed)
+		require.Equal(t, []string{taskSlack}, service.skipped)
+
+		results := map[int]importResult{}
+		for _, r := range report.Rows {
+			results[r.Line] = r
+		}
+		require.Len(t, results, 9, "blank lines are skipped")
+		require.Equal(t, importResult{Line: 2, Email: "henri@email.com", Status: importCreated, ShortLink: "https://ospk.org/Henri"}, results[2])
+		require.Equal(t, importCreated, results[3].Status)
+		require.Equal(t, importResult{Line: 5, Email: "not-an-email", Status: importInvalid, Field: "email", Error: "Invalid Email Address"}, results[5])
+		require.Equal(t, "cell", results[6].Field)
+		require.Equal(t, "Session not found", results[7].Error)
+		require.Equal(t, "Required for hybrid sessions", results[8].Error)
+		require.Equal(t, "Duplicate of line 2", results[9].Error)
+		require.Equal(t, importResult{Line: 10, Email: "twilio@email.com", Status: importInvalid, Field: "cell", Error: "Invalid Phone Number"}, results[10])
+		require.Equal(t, importFailed, results[11].Status)
+
+		var henri Signup
+		for _, su := range service.registered {
+			if su.Email == "henri@email.com" {
+				henri = su
+			}
+		}
+		require.Equal(t, "IN_PERSON", henri.AttendingLocation)
+		require.True(t, henri.SMSOptIn)
+		require.True(t, henri.StartDateTime.Equal(start))
+		require.Equal(t, "is-nov-16-22-12pm", henri.Cohort)
+		require.Equal(t, "HYBRID", henri.LocationType)
+		require.Equal(t, "Operation Spark", henri.GooglePlace.Name)
+		require.Equal(t, "career-fair", henri.UTMSource)
+	})
+
+	t.Run("normalizes formatted numbers for the SMS task", func(t *testing.T) {
+		service := &mockBulkRegisterer{}
+		im := &signupImporter{service: service, sessions: store, logger: slog.Default()}
+		csv := strings.Join([]string{
+			"nameFirst,nameLast,email,cell",
+			"Henri,Testaroni,henri@email.com,(555) 123-4567",
+			"Halle,Bot,halle@email.com,+1 555 123 4567",
+			"Dot,Ted,dot@email.com,555.123.4567",
+		}, "\n")
+
+		report, err := im.importCSV(context.Background(), strings.NewReader(csv), ImportOptions{})
+		require.NoError(t, err)
+		require.Equal(t, 3, report.Created)
+
+		sms := &smsService{}
+		for _, su := range service.registered {
+			require.Equal(t, "+15551234567", sms.FormatCell(su.Cell), su.Email)
+		}
+	})
+
+	t.Run("validates without registering on dry runs", func(t *testing.T) {
+		service := &mockBulkRegisterer{}
+		im := &signupImporter{service: service, sessions: store, logger: slog.Default()}
+
+		report, err := im.importCSV(context.Background(), strings.NewReader(csv), ImportOptions{DryRun: true})
+		require.NoError(t, err)
+		require.Equal(t, 4, report.Valid)
+		require.Equal(t, 5, report.Invalid)
+		require.Empty(t, service.registered)
+	})
+
+	t.Run("rejects files it can't import", func(t *testing.T) {
+		im := &signupImporter{service: &mockBulkRegisterer{}, sessions: store, logger: slog.Default()}
+		for name, tt := range map[string]struct {
+			csv string
+			o   ImportOptions
+		}{
+			"empty":          {csv: ""},
+			"unknown column": {csv: "nameFirst,nameLast,email,cell,shoeSize\n"},
+			"missing column": {csv: "nameFirst,nameLast,email\n"},
+			"unknown task":   {csv: "nameFirst,nameLast,email,cell\n", o: ImportOptions{Skip: []string{"fax"}}},
+			"too many rows":  {csv: "nameFirst,nameLast,email,cell\n" + strings.Repeat("A,B,c@d.com,5551234567\n", maxImportRows+1)},
+		} {
+			_, err := im.importCSV(context.Background(), strings.NewReader(tt.csv), tt.o)
+			require.Error(t, err, name)
+		}
+	})
+
+	t.Run("serves imports on the admin API", func(t *testing.T) {
+		service := &mockBulkRegisterer{}
+		s := NewAdminServer(adminServerOptions{
+			apiKey:   "admin-key",
+			store:    store,
+			importer: &signupImporter{service: service, sessions: store, logger: slog.Default()},
+			logger:   slog.Default(),
+		})
+		post := func(target, body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+			req.Header.Set("Authorization", "Bearer admin-key")
+			req.Header.Set("Content-Type", "text/csv")
+			res := httptest.NewRecorder()
+			s.ServeHTTP(res, req)
+			return res
+		}
+
+		res := post("/admin/imports?skip=slack,snapMail", "nameFirst,nameLast,email,cell\nHenri,Testaroni,henri@email.com,5551234567\n")
+		require.Equal(t, http.StatusOK, res.Code)
+		var report importReport
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&report))
+		require.Equal(t, 1, report.Created)
+		require.Equal(t, []string{taskSlack, taskSnapMail}, service.skipped)
+
+		tooMany := "nameFirst,nameLast,email,cell\n" + strings.Repeat("A,B,c@d.com,5551234567\n", maxHTTPImportRows+1)
+		res = post("/admin/imports", tooMany)
+		require.Equal(t, http.StatusRequestEntityTooLarge, res.Code)
+		require.Contains(t, res.Body.String(), "cmd/import")
+		res = post("/admin/imports?dryRun=true", tooMany)
+		require.Equal(t, http.StatusOK, res.Code, "large files can be validated")
+
+		for _, target := range []string{"/admin/imports?skip=fax", "/admin/imports?concurrency=0", "/admin/imports?dryRun=maybe"} {
+			res := post(target, "nameFirst,nameLast,email,cell\n")
+			require.Equal(t, http.StatusBadRequest, res.Code, target)
+		}
+	})
+}
@@ -0,0 +1,18 @@
+// Package logging reports errors to the logger and Sentry.
+package logging
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// Error logs the error with the args and sends it to Sentry if the context has a Sentry hub.
+func Error(ctx context.Context, logger *slog.Logger, err error, args ...any) {
+	logger.ErrorContext(ctx, err.Error(), args...)
+
+	if hub := sentry.GetHubFromContext(ctx); hub != nil {
+		hub.CaptureException(err)
+	}
+}
@@ -0,0 +1,41 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/operationspark/service-signup/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestError(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	var captured []*sentry.Event
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		BeforeSend: func(e *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+			captured = append(captured, e)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	ctx := sentry.SetHubOnContext(context.Background(), sentry.NewHub(client, sentry.NewScope()))
+
+	logging.Error(ctx, logger, errors.New("send failed"), slog.String("method", "POST"))
+
+	require.Contains(t, buf.String(), `msg="send failed" method=POST`)
+	require.Len(t, captured, 1)
+	require.Equal(t, "send failed", captured[0].Exception[0].Value)
+
+	t.Run("only logs without a Sentry hub", func(t *testing.T) {
+		logging.Error(context.Background(), logger, errors.New("no hub"))
+
+		require.Contains(t, buf.String(), `msg="no hub"`)
+		require.Len(t, captured, 1)
+	})
+}
@@ -4,29 +4,73 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"os"
-	"time"
+	"strings"
 
-	"github.com/mailgun/mailgun-go/v4"
+	"github.com/operationspark/service-signup/email"
+	"github.com/operationspark/service-signup/i18n"
 )
 
 type MailgunService struct {
-	domain          string               // Mail domain name.
-	defaultSender   string               // Default sender email address.
-	defaultTemplate string               // Default email template use when calling SendWelcome().
-	mgClient        *mailgun.MailgunImpl // Mailgun API Client
+	domain          string       // Mail domain name.
+	defaultSender   string       // Default sender email address.
+	defaultTemplate string       // Default email template use when calling SendWelcome().
+	programs        programs     // Welcome email templates by Greenlight program ID. Optional.
+	sender          email.Sender // Email backend. Defaults to the Mailgun API.
+	deliveries      email.Store  // Stores sent message IDs so delivery events can be matched to signups. Optional.
+	appEnv          string       // "staging" sends the "dev" version of the welcome template.
+	greenlightHost  string       // Base URL for the welcome email's enroll link. Defaults to production Greenlight.
 }
 
-func NewMailgunService(domain, apiKey, baseAPIurlOverride string) *MailgunService {
-	mgClient := mailgun.NewMailgun(domain, apiKey)
-	if len(baseAPIurlOverride) > 0 {
-		mgClient.SetAPIBase(baseAPIurlOverride)
-	}
-	return &MailgunService{
+type mailgunOption func(*MailgunService)
+
+func NewMailgunService(domain, apiKey, baseAPIurlOverride string, opts ...mailgunOption) *MailgunService {
+	m := &MailgunService{
 		domain:          domain,
 		defaultSender:   fmt.Sprintf("Operation Spark <admissions@%s>", domain),
 		defaultTemplate: "info-session-signup",
-		mgClient:        mgClient,
+		sender:          email.NewMailgunSender(domain, apiKey, baseAPIurlOverride),
+		greenlightHost:  greenlightBaseURL,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// WithDeliveryStore saves the message ID of every welcome email so Mailgun delivery events can be matched to the signup.
+func WithDeliveryStore(s email.Store) mailgunOption {
+	return func(m *MailgunService) {
+		m.deliveries = s
+	}
+}
+
+// WithAppEnv sets the app environment. Staging sends the "dev" version of Mailgun templates.
+func WithAppEnv(env string) mailgunOption {
+	return func(m *MailgunService) {
+		m.appEnv = env
+	}
+}
+
+// WithPrograms sends each program's welcome email template instead of the default template.
+func WithPrograms(ps programs) mailgunOption {
+	return func(m *MailgunService) {
+		m.programs = ps
+	}
+}
+
+// WithMailgunGreenlightHost links the welcome email to a different Greenlight (Ex: staging, local).
+func WithMailgunGreenlightHost(host string) mailgunOption {
+	return func(m *MailgunService) {
+		if host != "" {
+			m.greenlightHost = strings.TrimSuffix(host, "/")
+		}
+	}
+}
+
+// WithSender sends emails through a different backend (Ex: SMTP, local capture) instead of the Mailgun API.
+func WithSender(s email.Sender) mailgunOption {
+	return func(m *MailgunService) {
+		m.sender = s
 	}
 }
 
@@ -39,23 +83,54 @@ func (m MailgunService) run(ctx context.Context, su *Signup, logger *slog.Logger
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
-	return m.sendWelcome(ctx, *su)
+	messageID, err := m.sendWelcome(ctx, *su)
+	if err != nil {
+		return err
+	}
+
+	if m.deliveries == nil {
+		return nil
+	}
+	// The email was sent, so don't fail the signup if the delivery can't be saved.
+	if err := m.deliveries.SaveDelivery(ctx, su.delivery(messageID)); err != nil {
+		logger.ErrorContext(ctx,
+			fmt.Errorf("saveDelivery: %w", err).Error(),
+			slog.String("messageId", messageID),
+		)
+	}
+	return nil
 }
 
 func (m MailgunService) name() string {
 	return "mailgun service"
 }
 
-func (m MailgunService) sendWelcome(ctx context.Context, su Signup) error {
-	isStagingEnv := os.Getenv("APP_ENV") == "staging"
+// The welcome email links to the Zoom meeting and Greenlight, and the delivery record keeps the short link and the Greenlight signup ID.
+func (m MailgunService) spec() taskSpec {
+	return taskSpec{
+		key:         taskWelcomeEmail,
+		consumes:    []signupField{fieldZoomJoinURL, fieldJoinCode, fieldShortLink, fieldSignupID},
+		integration: "email",
+	}
+}
+
+// SendWelcome sends the welcome email and returns the Mailgun message ID.
+func (m MailgunService) sendWelcome(ctx context.Context, su Signup) (string, error) {
+	isStagingEnv := m.appEnv == "staging"
 
-	vars, err := su.welcomeData()
+	vars, err := su.welcomeData(m.greenlightHost)
 	if err != nil {
-		return fmt.Errorf("welcomeData: %w", err)
+		return "", fmt.Errorf("welcomeData: %w", err)
+	}
+
+	name := m.defaultTemplate
+	if m.programs != nil {
+		name = m.programs.get(su.ProgramID).welcomeTemplate
 	}
 
 	t := mgTemplate{
-		name: m.defaultTemplate,
+		name:    name,
+		subject: i18n.T(su.lang(), i18n.KeyWelcomeSubject),
 		variables: map[string]interface{}{
 			"firstName":            vars.FirstName,
 			"lastName":             vars.LastName,
@@ -72,7 +147,12 @@ func (m MailgunService) sendWelcome(ctx context.Context, su Signup) error {
 	}
 
 	if su.LocationType == "HYBRID" {
-		t.name = "info-session-signup-hybrid"
+		t.name += "-hybrid"
+	}
+
+	// Translated templates are suffixed with the language code. Ex: "info-session-signup-es"
+	if lang := su.lang(); lang != i18n.English {
+		t.name = fmt.Sprintf("%s-%s", t.name, lang)
 	}
 
 	if isStagingEnv {
@@ -83,37 +163,29 @@ func (m MailgunService) sendWelcome(ctx context.Context, su Signup) error {
 }
 
 type mgTemplate struct {
-	name      string                 // Name of mailgun template.
+	name      string                 // Name of the email template.
+	subject   string                 // Email subject line. Defaults to the English welcome subject.
 	variables map[string]interface{} // KV pairs of variables used in the email template.
 	version   string                 // Mailgun template version. If not set, the active version is used.
 }
 
-func (m MailgunService) sendWithTemplate(ctx context.Context, t mgTemplate, recipient string) error {
-	sender := m.defaultSender
-	subject := "Welcome from Operation Spark!"
-	// Empty body because we're using a template
-	body := ""
-
-	message := m.mgClient.NewMessage(sender, subject, body, recipient)
-	message.SetTemplate(t.name)
-	if len(t.version) > 0 {
-		message.SetTemplateVersion(t.version)
-	}
-	for k, v := range t.variables {
-		err := message.AddTemplateVariable(k, v)
-		if err != nil {
-			return fmt.Errorf("add template variable: %w ", err)
-		}
+// SendWithTemplate sends a templated email and returns the message ID without angle brackets.
+func (m MailgunService) sendWithTemplate(ctx context.Context, t mgTemplate, recipient string) (string, error) {
+	subject := i18n.T(i18n.English, i18n.KeyWelcomeSubject)
+	if len(t.subject) > 0 {
+		subject = t.subject
 	}
 
-	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second*10)
-	defer cancel()
-
-	// Send the message with a 10 second timeout
-	_, _, err := m.mgClient.Send(ctxWithTimeout, message)
+	id, err := m.sender.Send(ctx, email.Message{
+		From:            m.defaultSender,
+		To:              recipient,
+		Subject:         subject,
+		Template:        t.name,
+		TemplateVersion: t.version,
+		Variables:       t.variables,
+	})
 	if err != nil {
-		return fmt.Errorf("send: %w", err)
+		return "", fmt.Errorf("send: %w", mailgunError(err))
 	}
-
-	return nil
+	return id, nil
 }
@@ -3,12 +3,13 @@ package signup
 import (
 	"context"
 	"encoding/json"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"testing"
 	"time"
 
+	"github.com/operationspark/service-signup/email"
 	"github.com/operationspark/service-signup/greenlight"
 )
 
@@ -80,7 +81,7 @@ func TestSendWelcome(t *testing.T) {
 
 		mgSvc := NewMailgunService(domain, apiKey, mockMailgunAPI.URL+"/v4")
 
-		err := mgSvc.sendWelcome(context.Background(), form)
+		_, err := mgSvc.sendWelcome(context.Background(), form)
 
 		if err != nil {
 			t.Fatalf("send welcome: %v", err)
@@ -88,9 +89,6 @@ func TestSendWelcome(t *testing.T) {
 	})
 
 	t.Run("uses 'dev' info-session-signup template 'APP_ENV' == 'staging' ", func(t *testing.T) {
-		err := os.Setenv("APP_ENV", "staging")
-		assertNilError(t, err)
-
 		mockMailgunAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			err := r.ParseMultipartForm(128)
 			assertNilError(t, err)
@@ -106,9 +104,10 @@ func TestSendWelcome(t *testing.T) {
 			"mail.example.com",
 			"api-key",
 			mockMailgunAPI.URL+"/v4",
+			WithAppEnv("staging"),
 		)
 
-		err = mgSvc.sendWelcome(context.Background(), Signup{})
+		_, err := mgSvc.sendWelcome(context.Background(), Signup{})
 		assertNilError(t, err)
 	})
 
@@ -158,10 +157,147 @@ func TestSendWelcome(t *testing.T) {
 			mockMailgunAPI.URL+"/v4",
 		)
 
-		err := mgSvc.sendWelcome(context.Background(), signUp)
+		_, err := mgSvc.sendWelcome(context.Background(), signUp)
 		if err != nil {
 			t.Fatal(err)
 		}
 	})
 
+	t.Run("uses the welcome template of the signup's program", func(t *testing.T) {
+		mockMailgunAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err := r.ParseMultipartForm(128)
+			assertNilError(t, err)
+
+			assertEqual(t, r.FormValue("template"), "workshop-signup-hybrid")
+
+			_, err = w.Write([]byte("{}"))
+			assertNilError(t, err)
+		}))
+
+		ps := infoSessionPrograms(nil)
+		ps["workshop"] = program{id: "workshop", welcomeTemplate: "workshop-signup"}
+		mgSvc := NewMailgunService(
+			"mail.example.com",
+			"api-key",
+			mockMailgunAPI.URL+"/v4",
+			WithPrograms(ps),
+		)
+
+		_, err := mgSvc.sendWelcome(context.Background(), Signup{ProgramID: "workshop", LocationType: "HYBRID"})
+		assertNilError(t, err)
+	})
+
+	t.Run("links to the configured Greenlight host", func(t *testing.T) {
+		mockMailgunAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err := r.ParseMultipartForm(128)
+			assertNilError(t, err)
+
+			var gotVars welcomeVariables
+			err = json.Unmarshal([]byte(r.Form.Get("h:X-Mailgun-Variables")), &gotVars)
+			assertNilError(t, err)
+			assertEqual(t, gotVars.GreenlightEnrollURL, "http://localhost:3000/sessions/session-1/?subview=overview&userJoinCode=ujc&joinCode=tlav")
+
+			_, err = w.Write([]byte("{}"))
+			assertNilError(t, err)
+		}))
+		defer mockMailgunAPI.Close()
+
+		mgSvc := NewMailgunService(
+			"mail.example.com",
+			"api-key",
+			mockMailgunAPI.URL+"/v4",
+			WithMailgunGreenlightHost("http://localhost:3000/"),
+		)
+
+		_, err := mgSvc.sendWelcome(context.Background(), Signup{
+			StartDateTime: mustMakeTime(t, time.RFC3339, "2022-12-05T18:00:00.000Z"),
+			SessionID:     "session-1",
+			JoinCode:      "tlav",
+			userJoinCode:  "ujc",
+		})
+		assertNilError(t, err)
+	})
+
+	t.Run("uses the translated template and subject for Spanish speakers", func(t *testing.T) {
+		signUp := Signup{
+			Language:      "es",
+			LocationType:  "HYBRID",
+			StartDateTime: mustMakeTime(t, time.RFC3339, "2022-12-05T18:00:00.000Z"),
+		}
+
+		mockMailgunAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err := r.ParseMultipartForm(128)
+			assertNilError(t, err)
+
+			assertEqual(t, r.FormValue("template"), "info-session-signup-hybrid-es")
+			assertEqual(t, r.FormValue("subject"), "¡Te damos la bienvenida a Operation Spark!")
+
+			var gotVars welcomeVariables
+			err = json.Unmarshal([]byte(r.Form.Get("h:X-Mailgun-Variables")), &gotVars)
+			assertNilError(t, err)
+			assertEqual(t, gotVars.SessionDate, "lunes, 05 dic")
+
+			_, err = w.Write([]byte("{}"))
+			assertNilError(t, err)
+		}))
+
+		mgSvc := NewMailgunService(
+			"mail.example.com",
+			"api-key",
+			mockMailgunAPI.URL+"/v4",
+		)
+
+		_, err := mgSvc.sendWelcome(context.Background(), signUp)
+		assertNilError(t, err)
+	})
+
+	t.Run("saves the message ID so delivery events can be matched to the signup", func(t *testing.T) {
+		mockMailgunAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := w.Write([]byte(`{"id": "<20221031170000.1.ABC123@mail.example.com>", "message": "Queued. Thank you."}`))
+			assertNilError(t, err)
+		}))
+		defer mockMailgunAPI.Close()
+
+		store := &mockDeliveryStore{}
+		mgSvc := NewMailgunService(
+			"mail.example.com",
+			"api-key",
+			mockMailgunAPI.URL+"/v4",
+			WithDeliveryStore(store),
+		)
+
+		signupID := "gl-123"
+		su := Signup{
+			Email:     "henri@email.com",
+			Cell:      "555-123-4567",
+			SMSOptIn:  true,
+			ShortLink: "https://oprk.org/kRds5MKvKI",
+			id:        &signupID,
+		}
+		err := mgSvc.run(context.Background(), &su, slog.Default())
+		assertNilError(t, err)
+
+		assertEqual(t, len(store.saved), 1)
+		assertEqual(t, store.saved[0].MessageID, "20221031170000.1.ABC123@mail.example.com")
+		assertEqual(t, store.saved[0].Email, "henri@email.com")
+		assertEqual(t, store.saved[0].ShortLink, "https://oprk.org/kRds5MKvKI")
+		assertEqual(t, store.saved[0].SignupID, "gl-123")
+	})
+}
+
+type mockDeliveryStore struct {
+	saved []email.Delivery
+}
+
+func (m *mockDeliveryStore) SaveDelivery(ctx context.Context, d email.Delivery) error {
+	m.saved = append(m.saved, d)
+	return nil
+}
+
+func (m *mockDeliveryStore) RecordEvent(ctx context.Context, e email.Event) (email.Delivery, error) {
+	return email.Delivery{}, email.ErrDeliveryNotFound
+}
+
+func (m *mockDeliveryStore) MarkEmailInvalid(ctx context.Context, d email.Delivery) error {
+	return nil
 }
@@ -0,0 +1,178 @@
+// Package metrics defines the Prometheus metrics for the signup service and serves them at /metrics.
+package metrics
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "signup"
+
+// Signup outcomes.
+const (
+	OutcomeCreated     = "created"
+	OutcomeFailed      = "failed"
+	OutcomeInvalid     = "invalid"
+	OutcomeRejected    = "rejected"
+	OutcomeQuarantined = "quarantined"
+	OutcomeRateLimited = "rate_limited"
+)
+
+// Reminder results.
+const (
+	ResultSent   = "sent"
+	ResultFailed = "failed"
+)
+
+var (
+	// Registry holds the service's metrics along with the Go runtime and process metrics.
+	Registry = prometheus.NewRegistry()
+
+	// Signups counts signup requests by session location type and outcome.
+	Signups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "signups_total",
+		Help:      "Signup requests by session location type and outcome.",
+	}, []string{"location_type", "outcome"})
+
+	// TaskDuration observes how long each signup task takes, by task name.
+	TaskDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "task_duration_seconds",
+		Help:      "Duration of signup tasks.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"task"})
+
+	// TaskErrors counts failed signup tasks by task name.
+	TaskErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "task_errors_total",
+		Help:      "Failed signup tasks.",
+	}, []string{"task"})
+
+	// TaskRetries counts retried attempts of idempotent signup tasks by task name.
+	TaskRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "task_retries_total",
+		Help:      "Retried signup task attempts.",
+	}, []string{"task"})
+
+	// CircuitState is the circuit breaker state of each integration: 0 closed, 1 half-open, 2 open.
+	CircuitState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "circuit_state",
+		Help:      "Circuit breaker state by integration (0 closed, 1 half-open, 2 open).",
+	}, []string{"integration"})
+
+	// CircuitRejections counts tasks that were not run because their integration's circuit was open.
+	CircuitRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "circuit_rejections_total",
+		Help:      "Signup tasks not run because the integration's circuit was open.",
+	}, []string{"integration"})
+
+	// Reminders counts Info Session SMS reminders by result.
+	Reminders = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reminders_total",
+		Help:      "Info Session SMS reminders by result.",
+	}, []string{"result"})
+
+	// IntegrationResponses counts outbound HTTP responses by integration and status code. Requests that get no response have the "error" code.
+	IntegrationResponses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "integration_responses_total",
+		Help:      "Outbound HTTP responses by integration and status code.",
+	}, []string{"integration", "code"})
+
+	// ShortenerFallbacks counts messages sent with the long URL because the URL shortener failed.
+	ShortenerFallbacks = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "shortener_fallbacks_total",
+		Help:      "Links sent unshortened because the URL shortener failed.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		Signups,
+		TaskDuration,
+		TaskErrors,
+		TaskRetries,
+		CircuitState,
+		CircuitRejections,
+		Reminders,
+		IntegrationResponses,
+		ShortenerFallbacks,
+	)
+}
+
+// LocationType normalizes a session location type for use as a label. Unknown values are "unknown" to keep the number of series bounded.
+func LocationType(lt string) string {
+	switch lt := strings.ToUpper(lt); lt {
+	case "IN_PERSON", "VIRTUAL", "HYBRID":
+		return lt
+	default:
+		return "unknown"
+	}
+}
+
+// Handler serves the metrics in the Prometheus text format. If token is set, requests must send it as a Bearer token.
+func Handler(token string) http.Handler {
+	h := promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+	if token == "" {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// Transport counts the responses for each outbound request by integration.
+type Transport struct {
+	Base http.RoundTripper
+}
+
+// Integrations maps API host suffixes to integration names.
+var integrations = []struct{ suffix, name string }{
+	{"zoom.us", "zoom"},
+	{"twilio.com", "twilio"},
+	{"mailgun.net", "mailgun"},
+	{"slack.com", "slack"},
+	{"ospk.org", "shortener"},
+	{"greenlight.operationspark.org", "greenlight"},
+	{"operationspark.org", "operationspark"},
+}
+
+func (t Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	resp, err := t.Base.RoundTrip(r)
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	IntegrationResponses.WithLabelValues(integration(r.URL.Hostname()), code).Inc()
+	return resp, err
+}
+
+func integration(host string) string {
+	host = strings.ToLower(host)
+	for _, i := range integrations {
+		if host == i.suffix || strings.HasSuffix(host, "."+i.suffix) {
+			return i.name
+		}
+	}
+	return "other"
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration(t *testing.T) {
+	tests := map[string]string{
+		"api.zoom.us":                   "zoom",
+		"api.twilio.com":                "twilio",
+		"api.mailgun.net":               "mailgun",
+		"hooks.slack.com":               "slack",
+		"ospk.org":                      "shortener",
+		"greenlight.operationspark.org": "greenlight",
+		"www.operationspark.org":        "operationspark",
+		"notzoom.us":                    "other",
+		"127.0.0.1":                     "other",
+	}
+	for host, want := range tests {
+		require.Equal(t, want, integration(host), host)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	before := testutil.ToFloat64(IntegrationResponses.WithLabelValues("other", "429"))
+	client := &http.Client{Transport: Transport{Base: http.DefaultTransport}}
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	require.Equal(t, before+1, testutil.ToFloat64(IntegrationResponses.WithLabelValues("other", "429")))
+}
+
+func TestHandler(t *testing.T) {
+	h := Handler("secret")
+
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusUnauthorized, res.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	res = httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	require.Equal(t, http.StatusOK, res.Code)
+	require.Contains(t, res.Body.String(), "go_goroutines")
+}
+
+func TestLocationType(t *testing.T) {
+	require.Equal(t, "IN_PERSON", LocationType("in_person"))
+	require.Equal(t, "VIRTUAL", LocationType("VIRTUAL"))
+	require.Equal(t, "unknown", LocationType(""))
+	require.Equal(t, "unknown", LocationType("somewhere"))
+}
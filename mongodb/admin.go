@@ -0,0 +1,162 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SignupSearch filters Greenlight signups. Empty fields match every signup.
+type SignupSearch struct {
+	// Case-insensitive text in the person's name or email. Text with 7 or more digits also matches phone numbers, whatever their formatting.
+	Text      string
+	SessionID string
+	// Signups created in [From, To).
+	From  time.Time
+	To    time.Time
+	Skip  int64
+	Limit int64
+}
+
+// SearchSignups decodes the Greenlight signups matching the search into dst (a pointer to a slice), newest first, and returns the number of signups matching the search.
+func (m *MongodbService) SearchSignups(ctx context.Context, s SignupSearch, dst any) (int64, error) {
+	coll := m.client.Database(m.dbName).Collection("signups")
+	filter := signupSearchFilter(s)
+
+	total, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("countDocuments: %w", err)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: 1}}).
+		SetSkip(s.Skip).
+		SetLimit(s.Limit)
+	cur, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return 0, fmt.Errorf("find: %w", err)
+	}
+	if err := cur.All(ctx, dst); err != nil {
+		return 0, fmt.Errorf("cursor.All: %w", err)
+	}
+	return total, nil
+}
+
+func signupSearchFilter(s SignupSearch) bson.M {
+	filter := bson.M{}
+	if text := strings.TrimSpace(s.Text); text != "" {
+		re := primitive.Regex{Pattern: regexp.QuoteMeta(text), Options: "i"}
+		or := bson.A{
+			bson.M{"fullName": re},
+			bson.M{"nameFirst": re},
+			bson.M{"nameLast": re},
+			bson.M{"email": re},
+		}
+		if pattern := phonePattern(text); pattern != "" {
+			or = append(or, bson.M{"cell": primitive.Regex{Pattern: pattern}})
+		}
+		filter["$or"] = or
+	}
+	if s.SessionID != "" {
+		filter["sessionId"] = s.SessionID
+	}
+	createdAt := bson.M{}
+	if !s.From.IsZero() {
+		createdAt["$gte"] = s.From
+	}
+	if !s.To.IsZero() {
+		createdAt["$lt"] = s.To
+	}
+	if len(createdAt) > 0 {
+		filter["createdAt"] = createdAt
+	}
+	return filter
+}
+
+// PhonePattern returns a regular expression matching the last 10 digits of the text with any separators between them. Ex: "504-555-0100" matches "+15045550100". It returns "" if the text has fewer than 7 digits.
+func phonePattern(text string) string {
+	var digits []string
+	for _, r := range text {
+		if r >= '0' && r <= '9' {
+			digits = append(digits, string(r))
+		}
+	}
+	if len(digits) < 7 {
+		return ""
+	}
+	if len(digits) > 10 {
+		digits = digits[len(digits)-10:]
+	}
+	return strings.Join(digits, `\D*`) + "$"
+}
+
+// GetGreenlightSignup decodes the Greenlight signup with the given ID into dst.
+func (m *MongodbService) GetGreenlightSignup(ctx context.Context, id string, dst any) error {
+	return m.findOne(ctx, "signups", bson.M{"_id": id}, dst)
+}
+
+// GetSession decodes the Greenlight session with the given ID into dst.
+func (m *MongodbService) GetSession(ctx context.Context, id string, dst any) error {
+	return m.findOne(ctx, "sessions", bson.M{"_id": id}, dst)
+}
+
+// GetLocation decodes the Greenlight session location with the given ID into dst.
+func (m *MongodbService) GetLocation(ctx context.Context, id string, dst any) error {
+	return m.findOne(ctx, "locations", bson.M{"_id": id}, dst)
+}
+
+// GetUserJoinCode decodes the user join code with the given ID (ObjectID hex) into dst.
+func (m *MongodbService) GetUserJoinCode(ctx context.Context, id string, dst any) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("objectIDFromHex: %w", err)
+	}
+	return m.findOne(ctx, "userJoinCodes", bson.M{"_id": oid}, dst)
+}
+
+// FindSignupRecord decodes the newest signup record for the Greenlight signup ID into dst.
+func (m *MongodbService) FindSignupRecord(ctx context.Context, greenlightID string, dst any) error {
+	return m.findOne(ctx, "signupRecords", bson.M{"greenlightId": greenlightID}, dst,
+		options.FindOne().SetSort(bson.M{"createdAt": -1}))
+}
+
+// ListSignupRecords decodes a page of the signup records with the given status into dst (a pointer to a slice), oldest first, and returns the number of records with the status.
+func (m *MongodbService) ListSignupRecords(ctx context.Context, status string, skip, limit int64, dst any) (int64, error) {
+	coll := m.client.Database(m.dbName).Collection("signupRecords")
+	filter := bson.M{"status": status}
+
+	total, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("countDocuments: %w", err)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}).
+		SetSkip(skip).
+		SetLimit(limit)
+	cur, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return 0, fmt.Errorf("find: %w", err)
+	}
+	if err := cur.All(ctx, dst); err != nil {
+		return 0, fmt.Errorf("cursor.All: %w", err)
+	}
+	return total, nil
+}
+
+func (m *MongodbService) findOne(ctx context.Context, collection string, filter bson.M, dst any, opts ...*options.FindOneOptions) error {
+	res := m.client.Database(m.dbName).Collection(collection).FindOne(ctx, filter, opts...)
+	if res.Err() != nil {
+		return fmt.Errorf("findOne %s: %w", collection, res.Err())
+	}
+	if err := res.Decode(dst); err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+	return nil
+}
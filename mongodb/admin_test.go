@@ -0,0 +1,61 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/operationspark/service-signup/mongodb"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestSearchSignups(t *testing.T) {
+	srv := mongodb.New(dbName, dbClient)
+	ctx := context.Background()
+	coll := dbClient.Database(dbName).Collection("signups")
+	require.NoError(t, coll.Drop(ctx))
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	_, err := coll.InsertMany(ctx, []any{
+		bson.M{"_id": "henri", "sessionId": "s1", "nameFirst": "Henri", "nameLast": "Testaroni", "fullName": "Henri Testaroni", "email": "henri@email.com", "cell": "+15045550100", "createdAt": now},
+		bson.M{"_id": "halle", "sessionId": "s2", "nameFirst": "Halle", "nameLast": "Bot", "fullName": "Halle Bot", "email": "halle.bot@email.com", "cell": "504-555-0101", "createdAt": now.Add(-time.Hour)},
+		bson.M{"_id": "old", "sessionId": "s1", "nameFirst": "Henrietta", "nameLast": "Old", "fullName": "Henrietta Old", "email": "old@email.com", "cell": "", "createdAt": now.AddDate(0, -2, 0)},
+	})
+	require.NoError(t, err)
+
+	type signup struct {
+		ID string `bson:"_id"`
+	}
+	ids := func(signups []signup) []string {
+		var ids []string
+		for _, s := range signups {
+			ids = append(ids, s.ID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name   string
+		search mongodb.SignupSearch
+		want   []string
+		total  int64
+	}{
+		{"name", mongodb.SignupSearch{Text: "henri", Limit: 10}, []string{"henri", "old"}, 2},
+		{"email", mongodb.SignupSearch{Text: "halle.bot@", Limit: 10}, []string{"halle"}, 1},
+		{"phone in another format", mongodb.SignupSearch{Text: "(504) 555-0101", Limit: 10}, []string{"halle"}, 1},
+		{"phone with country code", mongodb.SignupSearch{Text: "504-555-0100", Limit: 10}, []string{"henri"}, 1},
+		{"session", mongodb.SignupSearch{SessionID: "s1", Limit: 10}, []string{"henri", "old"}, 2},
+		{"date range", mongodb.SignupSearch{From: now.AddDate(0, 0, -1), To: now.Add(time.Second), Limit: 10}, []string{"henri", "halle"}, 2},
+		{"second page", mongodb.SignupSearch{Skip: 1, Limit: 1}, []string{"halle"}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []signup
+			total, err := srv.SearchSignups(ctx, tt.search, &got)
+			require.NoError(t, err)
+			require.Equal(t, tt.total, total)
+			require.Equal(t, tt.want, ids(got))
+		})
+	}
+}
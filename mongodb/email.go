@@ -0,0 +1,89 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/operationspark/service-signup/email"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// deliveryEvent is a provider event stored on an "emailDeliveries" document.
+type deliveryEvent struct {
+	Status    email.Status `bson:"status"`
+	Reason    string       `bson:"reason,omitempty"`
+	Timestamp time.Time    `bson:"timestamp"`
+}
+
+// SaveDelivery saves a sent email to the "emailDeliveries" collection, keyed by the provider's message ID.
+func (m *MongodbService) SaveDelivery(ctx context.Context, d email.Delivery) error {
+	coll := m.client.Database(m.dbName).Collection("emailDeliveries")
+
+	now := time.Now()
+	if d.CreatedAt.IsZero() {
+		d.CreatedAt = now
+	}
+	d.UpdatedAt = now
+	if d.Status == "" {
+		d.Status = email.StatusSent
+	}
+
+	_, err := coll.InsertOne(ctx, d)
+	if err != nil {
+		return fmt.Errorf("insertOne: %w", err)
+	}
+	return nil
+}
+
+// RecordEvent sets the delivery's status and appends the event to its history.
+// Returns email.ErrDeliveryNotFound if no email was sent with the event's message ID.
+func (m *MongodbService) RecordEvent(ctx context.Context, e email.Event) (email.Delivery, error) {
+	coll := m.client.Database(m.dbName).Collection("emailDeliveries")
+
+	res := coll.FindOneAndUpdate(
+		ctx,
+		bson.M{"messageId": e.MessageID},
+		bson.M{
+			"$set": bson.M{"status": e.Status, "updatedAt": time.Now()},
+			"$push": bson.M{"events": deliveryEvent{
+				Status:    e.Status,
+				Reason:    e.Reason,
+				Timestamp: e.Timestamp,
+			}},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+	if errors.Is(res.Err(), mongo.ErrNoDocuments) {
+		return email.Delivery{}, email.ErrDeliveryNotFound
+	}
+	if res.Err() != nil {
+		return email.Delivery{}, fmt.Errorf("findOneAndUpdate: %w", res.Err())
+	}
+
+	var d email.Delivery
+	if err := res.Decode(&d); err != nil {
+		return email.Delivery{}, fmt.Errorf("decode: %w", err)
+	}
+	return d, nil
+}
+
+// MarkEmailInvalid flags every delivery to the delivery's email address as undeliverable, along with the signup record of the delivery's Greenlight signup.
+func (m *MongodbService) MarkEmailInvalid(ctx context.Context, d email.Delivery) error {
+	db := m.client.Database(m.dbName)
+	set := bson.M{"$set": bson.M{"emailInvalid": true, "updatedAt": time.Now()}}
+
+	if _, err := db.Collection("emailDeliveries").UpdateMany(ctx, bson.M{"email": d.Email}, set); err != nil {
+		return fmt.Errorf("updateMany emailDeliveries: %w", err)
+	}
+	if d.SignupID == "" {
+		return nil
+	}
+	if _, err := db.Collection("signupRecords").UpdateMany(ctx, bson.M{"greenlightId": d.SignupID}, set); err != nil {
+		return fmt.Errorf("updateMany signupRecords: %w", err)
+	}
+	return nil
+}
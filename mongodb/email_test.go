@@ -0,0 +1,65 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/operationspark/service-signup/email"
+	"github.com/operationspark/service-signup/mongodb"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestEmailDeliveries(t *testing.T) {
+	srv := mongodb.New(dbName, dbClient)
+	ctx := context.Background()
+
+	messageID := "20221031170000.1." + randID() + "@mail.operationspark.org"
+	address := randID() + "@email.com"
+
+	signupID := randID()
+	err := srv.SaveDelivery(ctx, email.Delivery{
+		MessageID: messageID,
+		SignupID:  signupID,
+		Email:     address,
+		NameFirst: "Henri",
+		SMSOptIn:  true,
+		ShortLink: "https://oprk.org/kRds5MKvKI",
+	})
+	require.NoError(t, err)
+
+	t.Run("records events", func(t *testing.T) {
+		d, err := srv.RecordEvent(ctx, email.Event{
+			MessageID: messageID,
+			Status:    email.StatusDelivered,
+			Timestamp: time.Now(),
+		})
+		require.NoError(t, err)
+		require.Equal(t, email.StatusDelivered, d.Status)
+		require.Equal(t, "https://oprk.org/kRds5MKvKI", d.ShortLink)
+	})
+
+	t.Run("returns ErrDeliveryNotFound for unknown messages", func(t *testing.T) {
+		_, err := srv.RecordEvent(ctx, email.Event{MessageID: "unknown", Status: email.StatusOpened})
+		require.ErrorIs(t, err, email.ErrDeliveryNotFound)
+	})
+
+	t.Run("marks emails and their signup invalid", func(t *testing.T) {
+		recordID := randID()
+		err := srv.SaveSignup(ctx, recordID, bson.M{"_id": recordID, "greenlightId": signupID, "email": address})
+		require.NoError(t, err)
+
+		require.NoError(t, srv.MarkEmailInvalid(ctx, email.Delivery{Email: address, SignupID: signupID}))
+
+		d, err := srv.RecordEvent(ctx, email.Event{MessageID: messageID, Status: email.StatusBounced})
+		require.NoError(t, err)
+		require.True(t, d.EmailInvalid)
+
+		var record struct {
+			EmailInvalid bool `bson:"emailInvalid"`
+		}
+		require.NoError(t, srv.GetSignup(ctx, recordID, &record))
+		require.True(t, record.EmailInvalid)
+	})
+}
@@ -0,0 +1,73 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// CampaignReport counts the signup records created in [from, to) by UTM source, medium, and campaign, and decodes the results into dst (a pointer to a slice).
+// A signup counts as attended when its user join code has been used. Spam, canceled, and quarantined signups are excluded.
+// Each result has "source", "medium", "campaign", "signups", and "attended" fields, sorted by signups.
+func (m *MongodbService) CampaignReport(ctx context.Context, from, to time.Time, dst any) error {
+	coll := m.client.Database(m.dbName).Collection("signupRecords")
+
+	usedCodes := bson.M{"$filter": bson.M{
+		"input": "$joinCode",
+		"cond": bson.M{"$and": bson.A{
+			// Unused codes have a null, missing, or empty "usedAt".
+			bson.M{"$gt": bson.A{"$$this.usedAt", nil}},
+			bson.M{"$ne": bson.A{"$$this.usedAt", ""}},
+		}},
+	}}
+
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{
+			"createdAt": bson.M{"$gte": from, "$lt": to},
+			"status":    bson.M{"$nin": bson.A{"spam", "canceled", "quarantined"}},
+		}},
+		// Records keep the join code's ObjectID as a hex string.
+		bson.M{"$lookup": bson.M{
+			"from": "userJoinCodes",
+			"let": bson.M{"codeId": bson.M{"$convert": bson.M{
+				"input":   "$userJoinCode",
+				"to":      "objectId",
+				"onError": nil,
+				"onNull":  nil,
+			}}},
+			"pipeline": bson.A{bson.M{"$match": bson.M{"$expr": bson.M{"$eq": bson.A{"$_id", "$$codeId"}}}}},
+			"as":       "joinCode",
+		}},
+		bson.M{"$group": bson.M{
+			"_id": bson.M{
+				"source":   bson.M{"$ifNull": bson.A{"$utmSource", ""}},
+				"medium":   bson.M{"$ifNull": bson.A{"$utmMedium", ""}},
+				"campaign": bson.M{"$ifNull": bson.A{"$utmCampaign", ""}},
+			},
+			"signups": bson.M{"$sum": 1},
+			"attended": bson.M{"$sum": bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{bson.M{"$size": usedCodes}, 0}}, 1, 0,
+			}}},
+		}},
+		bson.M{"$project": bson.M{
+			"_id":      0,
+			"source":   "$_id.source",
+			"medium":   "$_id.medium",
+			"campaign": "$_id.campaign",
+			"signups":  1,
+			"attended": 1,
+		}},
+		bson.M{"$sort": bson.D{{Key: "signups", Value: -1}, {Key: "source", Value: 1}, {Key: "campaign", Value: 1}}},
+	}
+
+	cur, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return fmt.Errorf("aggregate: %w", err)
+	}
+	if err := cur.All(ctx, dst); err != nil {
+		return fmt.Errorf("cursor.All: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/operationspark/service-signup/mongodb"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCampaignReport(t *testing.T) {
+	srv := mongodb.New(dbName, dbClient)
+	ctx := context.Background()
+	db := dbClient.Database(dbName)
+	require.NoError(t, db.Collection("signupRecords").Drop(ctx))
+
+	usedCode := primitive.NewObjectID()
+	unusedCode := primitive.NewObjectID()
+	_, err := db.Collection("userJoinCodes").InsertMany(ctx, []any{
+		bson.M{"_id": usedCode, "usedAt": time.Now().Format(time.RFC3339)},
+		bson.M{"_id": unusedCode, "usedAt": ""},
+	})
+	require.NoError(t, err)
+
+	now := time.Now()
+	_, err = db.Collection("signupRecords").InsertMany(ctx, []any{
+		bson.M{"_id": randID(), "status": "active", "utmSource": "facebook", "utmCampaign": "spring", "userJoinCode": usedCode.Hex(), "createdAt": now},
+		bson.M{"_id": randID(), "status": "active", "utmSource": "facebook", "utmCampaign": "spring", "userJoinCode": unusedCode.Hex(), "createdAt": now},
+		bson.M{"_id": randID(), "status": "active", "createdAt": now},
+		// Not counted
+		bson.M{"_id": randID(), "status": "spam", "utmSource": "facebook", "utmCampaign": "spring", "createdAt": now},
+		bson.M{"_id": randID(), "status": "active", "utmSource": "facebook", "utmCampaign": "spring", "createdAt": now.AddDate(0, -2, 0)},
+	})
+	require.NoError(t, err)
+
+	type stats struct {
+		Source   string `bson:"source"`
+		Campaign string `bson:"campaign"`
+		Signups  int    `bson:"signups"`
+		Attended int    `bson:"attended"`
+	}
+	var got []stats
+	err = srv.CampaignReport(ctx, now.AddDate(0, 0, -7), now.Add(time.Minute), &got)
+	require.NoError(t, err)
+
+	require.Equal(t, []stats{
+		{Source: "facebook", Campaign: "spring", Signups: 2, Attended: 1},
+		{Signups: 1},
+	}, got)
+}
@@ -0,0 +1,65 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SaveSignup saves a signup record to the "signupRecords" collection. The record must have an "_id" field matching the id.
+func (m *MongodbService) SaveSignup(ctx context.Context, id string, record any) error {
+	coll := m.client.Database(m.dbName).Collection("signupRecords")
+
+	if _, err := coll.InsertOne(ctx, record); err != nil {
+		return fmt.Errorf("insertOne %q: %w", id, err)
+	}
+	return nil
+}
+
+// GetSignup decodes the signup record with the given ID into dst.
+func (m *MongodbService) GetSignup(ctx context.Context, id string, dst any) error {
+	coll := m.client.Database(m.dbName).Collection("signupRecords")
+
+	res := coll.FindOne(ctx, bson.M{"_id": id})
+	if res.Err() != nil {
+		return fmt.Errorf("findOne: %w", res.Err())
+	}
+	if err := res.Decode(dst); err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+	return nil
+}
+
+// SwapSignupStatus changes a signup record's status from one value to another. It returns mongo.ErrNoDocuments if the record doesn't have the from status, so only one of two concurrent swaps succeeds.
+func (m *MongodbService) SwapSignupStatus(ctx context.Context, id string, from, to string) error {
+	coll := m.client.Database(m.dbName).Collection("signupRecords")
+
+	res, err := coll.UpdateOne(ctx,
+		bson.M{"_id": id, "status": from},
+		bson.M{"$set": bson.M{"status": to, "updatedAt": time.Now()}},
+	)
+	if err != nil {
+		return fmt.Errorf("updateOne: %w", err)
+	}
+	if res.MatchedCount != 1 {
+		return fmt.Errorf("signup %q with status %q: %w", id, from, mongo.ErrNoDocuments)
+	}
+	return nil
+}
+
+// SetSignupStatus updates a signup record's status. Ex: "spam", "canceled".
+func (m *MongodbService) SetSignupStatus(ctx context.Context, id string, status string) error {
+	coll := m.client.Database(m.dbName).Collection("signupRecords")
+
+	res, err := coll.UpdateByID(ctx, id, bson.M{"$set": bson.M{"status": status, "updatedAt": time.Now()}})
+	if err != nil {
+		return fmt.Errorf("updateByID: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("signup %q: %w", id, mongo.ErrNoDocuments)
+	}
+	return nil
+}
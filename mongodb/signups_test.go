@@ -0,0 +1,39 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/operationspark/service-signup/mongodb"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestSignupRecords(t *testing.T) {
+	srv := mongodb.New(dbName, dbClient)
+	ctx := context.Background()
+
+	type record struct {
+		ID        string `bson:"_id"`
+		Status    string `bson:"status"`
+		NameFirst string `bson:"nameFirst"`
+	}
+
+	id := randID()
+	require.NoError(t, srv.SaveSignup(ctx, id, record{ID: id, Status: "active", NameFirst: "Henri"}))
+
+	var got record
+	require.NoError(t, srv.GetSignup(ctx, id, &got))
+	require.Equal(t, "Henri", got.NameFirst)
+
+	require.NoError(t, srv.SetSignupStatus(ctx, id, "spam"))
+	require.NoError(t, srv.GetSignup(ctx, id, &got))
+	require.Equal(t, "spam", got.Status)
+
+	require.Error(t, srv.SetSignupStatus(ctx, "missing", "spam"))
+
+	require.NoError(t, srv.SwapSignupStatus(ctx, id, "spam", "active"))
+	require.ErrorIs(t, srv.SwapSignupStatus(ctx, id, "spam", "active"), mongo.ErrNoDocuments, "the status already changed")
+	require.NoError(t, srv.GetSignup(ctx, id, &got))
+	require.Equal(t, "active", got.Status)
+}
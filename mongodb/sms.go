@@ -0,0 +1,92 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/operationspark/service-signup/sms"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// queuedSMS is an SMS message held in the "smsQueue" collection until after the recipient's quiet hours.
+type queuedSMS struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	To        string             `bson:"to"`
+	Body      string             `bson:"body"`
+	SendAfter time.Time          `bson:"sendAfter"`
+	CreatedAt time.Time          `bson:"createdAt"`
+}
+
+// EnqueueSMS saves a deferred SMS message to the queue.
+func (m *MongodbService) EnqueueSMS(ctx context.Context, msg sms.Message) error {
+	coll := m.client.Database(m.dbName).Collection("smsQueue")
+
+	createdAt := msg.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	_, err := coll.InsertOne(ctx, queuedSMS{
+		To:        msg.To,
+		Body:      msg.Body,
+		SendAfter: msg.SendAfter,
+		CreatedAt: createdAt,
+	})
+	if err != nil {
+		return fmt.Errorf("insertOne: %w", err)
+	}
+	return nil
+}
+
+// DueSMS returns the queued SMS messages that can be sent at the given time, oldest first.
+func (m *MongodbService) DueSMS(ctx context.Context, now time.Time) ([]sms.Message, error) {
+	coll := m.client.Database(m.dbName).Collection("smsQueue")
+
+	cur, err := coll.Find(
+		ctx,
+		bson.M{"sendAfter": bson.M{"$lte": now}},
+		options.Find().SetSort(bson.M{"createdAt": 1}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("find: %w", err)
+	}
+
+	var docs []queuedSMS
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("cursor.All(): %w", err)
+	}
+
+	msgs := make([]sms.Message, 0, len(docs))
+	for _, d := range docs {
+		msgs = append(msgs, sms.Message{
+			ID:        d.ID.Hex(),
+			To:        d.To,
+			Body:      d.Body,
+			SendAfter: d.SendAfter,
+			CreatedAt: d.CreatedAt,
+		})
+	}
+	return msgs, nil
+}
+
+// DeleteSMS removes a message from the queue before it is sent. It returns mongo.ErrNoDocuments if the message was already removed (Ex: by an overlapping run), so only one run sends it.
+func (m *MongodbService) DeleteSMS(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("objectIDFromHex: %w", err)
+	}
+
+	coll := m.client.Database(m.dbName).Collection("smsQueue")
+	res, err := coll.DeleteOne(ctx, bson.M{"_id": objID})
+	if err != nil {
+		return fmt.Errorf("deleteOne: %w", err)
+	}
+	if res.DeletedCount == 0 {
+		return fmt.Errorf("sms %q: %w", id, mongo.ErrNoDocuments)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/operationspark/service-signup/mongodb"
+	"github.com/operationspark/service-signup/sms"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestSMSQueue(t *testing.T) {
+	srv := mongodb.New(dbName, dbClient)
+	ctx := context.Background()
+
+	err := dbClient.Database(dbName).Collection("smsQueue").Drop(ctx)
+	require.NoError(t, err)
+
+	now := time.Now()
+	err = srv.EnqueueSMS(ctx, sms.Message{To: "+15045550000", Body: "due", SendAfter: now.Add(-time.Minute)})
+	require.NoError(t, err)
+	err = srv.EnqueueSMS(ctx, sms.Message{To: "+15045550001", Body: "not due", SendAfter: now.Add(time.Hour)})
+	require.NoError(t, err)
+
+	due, err := srv.DueSMS(ctx, now)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	require.Equal(t, "due", due[0].Body)
+	require.Equal(t, "+15045550000", due[0].To)
+
+	err = srv.DeleteSMS(ctx, due[0].ID)
+	require.NoError(t, err)
+	err = srv.DeleteSMS(ctx, due[0].ID)
+	require.ErrorIs(t, err, mongo.ErrNoDocuments, "an overlapping run should not claim the message again")
+
+	due, err = srv.DueSMS(ctx, now.Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	require.Equal(t, "not due", due[0].Body)
+}
@@ -0,0 +1,26 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/operationspark/service-signup/templates"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// LoadTemplates returns the SMS template overrides saved in the "smsTemplates" collection.
+// Each document has a "name", "language", and a text/template "body".
+func (m *MongodbService) LoadTemplates(ctx context.Context) ([]templates.Template, error) {
+	coll := m.client.Database(m.dbName).Collection("smsTemplates")
+
+	cur, err := coll.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("find: %w", err)
+	}
+
+	var tmpls []templates.Template
+	if err := cur.All(ctx, &tmpls); err != nil {
+		return nil, fmt.Errorf("cursor.All(): %w", err)
+	}
+	return tmpls, nil
+}
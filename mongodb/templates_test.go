@@ -0,0 +1,34 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/operationspark/service-signup/i18n"
+	"github.com/operationspark/service-signup/mongodb"
+	"github.com/operationspark/service-signup/templates"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadTemplates(t *testing.T) {
+	srv := mongodb.New(dbName, dbClient)
+	ctx := context.Background()
+
+	coll := dbClient.Database(dbName).Collection("smsTemplates")
+	require.NoError(t, coll.Drop(ctx))
+
+	_, err := coll.InsertOne(ctx, templates.Template{
+		Name:     templates.OptInConfirmation,
+		Language: i18n.English,
+		Body:     "Thanks for opting in! Reply STOP to unsubscribe.",
+	})
+	require.NoError(t, err)
+
+	r, err := templates.New(ctx, templates.Options{Sources: []templates.Source{srv}})
+	require.NoError(t, err)
+
+	got, err := r.Render(templates.OptInConfirmation, i18n.English, time.UTC, nil)
+	require.NoError(t, err)
+	require.Equal(t, "Thanks for opting in! Reply STOP to unsubscribe.", got)
+}
@@ -0,0 +1,243 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type (
+	// DigestSignup is the part of a saved signup record used in digest reports.
+	// Signup records are saved to the "signupRecords" collection by the signup service.
+	DigestSignup struct {
+		SessionID     string    `bson:"sessionId"`
+		Cohort        string    `bson:"cohort"`
+		StartDateTime time.Time `bson:"startDateTime"`
+		Referrer      string    `bson:"referrer"`
+		LocationType  string    `bson:"locationType"`
+		CreatedAt     time.Time `bson:"createdAt"`
+	}
+
+	DigestStore interface {
+		// Signups returns the signups created in [from, to), excluding spam, canceled, and quarantined signups.
+		Signups(ctx context.Context, from, to time.Time) ([]DigestSignup, error)
+	}
+
+	// Notifier posts a message for staff. Ex: Slack channel.
+	Notifier interface {
+		Notify(ctx context.Context, text string) error
+	}
+
+	// Digest summarizes signups over a period.
+	Digest struct {
+		From, To time.Time
+		Total    int
+		// Total for the period before, of the same length.
+		PreviousTotal  int
+		BySession      []DigestCount
+		ByReferrer     []DigestCount
+		ByLocationType []DigestCount
+		Upcoming       []DigestCount
+	}
+
+	DigestCount struct {
+		Label string
+		Count int
+	}
+)
+
+const (
+	// JobSignupDigest posts a summary of recent signups to Slack. JobArgs.Period sets the report period. Ex: "1 day", "7 days".
+	JobSignupDigest = "signup-digest"
+)
+
+// SendDigest builds the signup digest for the period ending now (default: 1 day) and posts it to the digest Slack channel.
+// In dry run mode, the report is returned in the response body instead of posted.
+func (s *Server) sendDigest(w http.ResponseWriter, r *http.Request, args JobArgs) {
+	if s.digestStore == nil || s.digestNotify == nil {
+		s.notFoundResponse(w, r, "signup digest is not configured")
+		return
+	}
+
+	if args.Period == "" {
+		args.Period = "1 day"
+	}
+	period, err := args.Period.Parse()
+	if err != nil {
+		s.badRequestResponse(w, r, err.Error())
+		return
+	}
+
+	tz, err := time.LoadLocation(CentralTZName)
+	if err != nil {
+		s.serverErrorResponse(w, r, fmt.Errorf("loadLocation: %v", err))
+		return
+	}
+
+	ctx := r.Context()
+	d, err := s.buildDigest(ctx, time.Now(), period)
+	if err != nil {
+		s.serverErrorResponse(w, r, fmt.Errorf("buildDigest: %w", err))
+		return
+	}
+
+	report := d.Format(tz)
+	if args.DryRun {
+		s.logger.InfoContext(ctx, "Dry Run Mode: (not posting digest)", slog.String("digest", report))
+		if err := s.writeJSON(w, http.StatusOK, map[string]string{"digest": report}); err != nil {
+			s.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := s.digestNotify.Notify(ctx, report); err != nil {
+		s.serverErrorResponse(w, r, fmt.Errorf("notify: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// BuildDigest aggregates the signups in the period ending at now, and the sessions of every program in the same period ahead.
+func (s *Server) buildDigest(ctx context.Context, now time.Time, period time.Duration) (Digest, error) {
+	from := now.Add(-period)
+	signups, err := s.digestStore.Signups(ctx, from, now)
+	if err != nil {
+		return Digest{}, fmt.Errorf("signups: %w", err)
+	}
+
+	previous, err := s.digestStore.Signups(ctx, from.Add(-period), from)
+	if err != nil {
+		return Digest{}, fmt.Errorf("previous signups: %w", err)
+	}
+
+	upcoming, err := s.store.GetUpcomingSessions(ctx, period, s.programIDs()...)
+	if err != nil {
+		return Digest{}, fmt.Errorf("getUpcomingSessions: %w", err)
+	}
+
+	tz, err := time.LoadLocation(CentralTZName)
+	if err != nil {
+		return Digest{}, fmt.Errorf("loadLocation: %w", err)
+	}
+
+	d := newDigest(from, now, signups, len(previous), tz)
+
+	sort.Slice(upcoming, func(i, j int) bool {
+		return upcoming[i].Times.Start.DateTime.Before(upcoming[j].Times.Start.DateTime)
+	})
+	for _, sess := range upcoming {
+		d.Upcoming = append(d.Upcoming, DigestCount{
+			Label: fmt.Sprintf("%s (%s)", sess.Times.Start.DateTime.In(tz).Format(digestSessionLayout), orNone(sess.LocationType)),
+			Count: len(sess.Participants),
+		})
+	}
+	return d, nil
+}
+
+const digestSessionLayout = "Mon Jan 02 3:04 PM MST"
+
+func newDigest(from, to time.Time, signups []DigestSignup, previousTotal int, tz *time.Location) Digest {
+	bySession := map[string]int{}
+	byReferrer := map[string]int{}
+	byLocationType := map[string]int{}
+	for _, su := range signups {
+		session := "No session picked"
+		if !su.StartDateTime.IsZero() {
+			session = su.StartDateTime.In(tz).Format(digestSessionLayout)
+		}
+		bySession[session]++
+		byReferrer[orNone(strings.ToLower(su.Referrer))]++
+		byLocationType[orNone(su.LocationType)]++
+	}
+
+	return Digest{
+		From:           from,
+		To:             to,
+		Total:          len(signups),
+		PreviousTotal:  previousTotal,
+		BySession:      sortedCounts(bySession),
+		ByReferrer:     sortedCounts(byReferrer),
+		ByLocationType: sortedCounts(byLocationType),
+	}
+}
+
+// SortedCounts sorts by count, highest first, then by label.
+func sortedCounts(m map[string]int) []DigestCount {
+	counts := make([]DigestCount, 0, len(m))
+	for label, n := range m {
+		counts = append(counts, DigestCount{Label: label, Count: n})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Label < counts[j].Label
+	})
+	return counts
+}
+
+// Format creates the Slack mrkdwn report.
+func (d Digest) Format(tz *time.Location) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "*Signup Digest: %s - %s*\n", d.From.In(tz).Format("Mon Jan 02 3:04 PM"), d.To.In(tz).Format("Mon Jan 02 3:04 PM MST"))
+
+	change := d.Total - d.PreviousTotal
+	trend := "no change"
+	switch {
+	case change > 0:
+		trend = fmt.Sprintf("up %d", change)
+	case change < 0:
+		trend = fmt.Sprintf("down %d", -change)
+	}
+	fmt.Fprintf(&b, "*%d signups* (%s from %d the previous period)\n", d.Total, trend, d.PreviousTotal)
+
+	section := func(title string, counts []DigestCount, unit string) {
+		fmt.Fprintf(&b, "\n*%s*\n", title)
+		if len(counts) == 0 {
+			b.WriteString("• None\n")
+			return
+		}
+		for _, c := range counts {
+			fmt.Fprintf(&b, "• %s: %d%s\n", c.Label, c.Count, unit)
+		}
+	}
+	section("By Session", d.BySession, "")
+	section("By Referrer", d.ByReferrer, "")
+	section("By Location Type", d.ByLocationType, "")
+	section("Upcoming Sessions", d.Upcoming, " signed up")
+
+	return strings.TrimSpace(b.String())
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+// Signups returns the signup records created in [from, to), excluding spam, canceled, and quarantined signups.
+func (m *MongoService) Signups(ctx context.Context, from, to time.Time) ([]DigestSignup, error) {
+	coll := m.client.Database(m.dbName).Collection("signupRecords")
+
+	cur, err := coll.Find(ctx, bson.M{
+		"createdAt": bson.M{"$gte": from, "$lt": to},
+		"status":    bson.M{"$nin": []string{"spam", "canceled", "quarantined"}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("signupRecords.Find: %w", err)
+	}
+
+	var signups []DigestSignup
+	if err := cur.All(ctx, &signups); err != nil {
+		return nil, fmt.Errorf("signupRecords cursor.All(): %w", err)
+	}
+	return signups, nil
+}
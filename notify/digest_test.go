@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type MockNotifier struct {
+	text string
+}
+
+func (m *MockNotifier) Notify(ctx context.Context, text string) error {
+	m.text = text
+	return nil
+}
+
+func TestSignupDigest(t *testing.T) {
+	t.Run("Posts a summary of the day's signups", func(t *testing.T) {
+		mongoService := NewMongoService(dbClient, dbName)
+		err := dropDatabase(context.Background(), mongoService)
+		require.NoError(t, err)
+
+		insertFutureSession(t, mongoService, time.Hour*12)
+
+		start := time.Now().Add(time.Hour * 12)
+		now := time.Now()
+		records := []any{
+			bson.M{"_id": "1", "status": "active", "referrer": "Instagram", "locationType": "VIRTUAL", "startDateTime": start, "createdAt": now.Add(-time.Hour)},
+			bson.M{"_id": "2", "status": "active", "referrer": "instagram", "locationType": "IN_PERSON", "startDateTime": start, "createdAt": now.Add(-time.Hour * 2)},
+			bson.M{"_id": "3", "status": "active", "referrer": "Google", "locationType": "VIRTUAL", "startDateTime": start, "createdAt": now.Add(-time.Hour * 3)},
+			// Not counted
+			bson.M{"_id": "4", "status": "spam", "referrer": "Google", "locationType": "VIRTUAL", "startDateTime": start, "createdAt": now.Add(-time.Hour)},
+			// Previous period
+			bson.M{"_id": "5", "status": "active", "referrer": "Google", "locationType": "VIRTUAL", "startDateTime": start, "createdAt": now.Add(-time.Hour * 30)},
+		}
+		_, err = dbClient.Database(dbName).Collection("signupRecords").InsertMany(context.Background(), records)
+		require.NoError(t, err)
+
+		var body bytes.Buffer
+		err = json.NewEncoder(&body).Encode(Request{
+			JobName: JobSignupDigest,
+			JobArgs: JobArgs{Period: "1 day"},
+		})
+		require.NoError(t, err)
+
+		notifier := MockNotifier{}
+		srv := NewServer(ServerOpts{
+			Store:          mongoService,
+			DigestStore:    mongoService,
+			DigestNotifier: &notifier,
+			Logger:         slog.Default(),
+		})
+
+		resp := httptest.NewRecorder()
+		srv.ServeHTTP(resp, mustMakeReq(t, &body))
+
+		require.Equal(t, http.StatusOK, resp.Result().StatusCode)
+		require.Contains(t, notifier.text, "*3 signups* (up 2 from 1 the previous period)")
+		require.Contains(t, notifier.text, "• instagram: 2\n• google: 1")
+		require.Contains(t, notifier.text, "• VIRTUAL: 2\n• IN_PERSON: 1")
+		require.Contains(t, notifier.text, "*Upcoming Sessions*")
+	})
+}
+
+func TestDigestFormat(t *testing.T) {
+	tz, err := time.LoadLocation(CentralTZName)
+	require.NoError(t, err)
+
+	to := time.Date(2024, time.March, 4, 14, 0, 0, 0, time.UTC)
+	session := time.Date(2024, time.March, 5, 17, 0, 0, 0, time.UTC)
+	d := newDigest(to.Add(-time.Hour*24*7), to, []DigestSignup{
+		{StartDateTime: session, Referrer: "Facebook", LocationType: "HYBRID"},
+		{StartDateTime: session, LocationType: "HYBRID"},
+	}, 4, tz)
+
+	want := `*Signup Digest: Mon Feb 26 8:00 AM - Mon Mar 04 8:00 AM CST*
+*2 signups* (down 2 from 4 the previous period)
+
+*By Session*
+• Tue Mar 05 11:00 AM CST: 2
+
+*By Referrer*
+• facebook: 1
+• none: 1
+
+*By Location Type*
+• HYBRID: 2
+
+*Upcoming Sessions*
+• None`
+	require.Equal(t, want, d.Format(tz))
+}
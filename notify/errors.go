@@ -6,7 +6,7 @@ import (
 	"log/slog"
 	"net/http"
 
-	"github.com/getsentry/sentry-go"
+	"github.com/operationspark/service-signup/logging"
 )
 
 type (
@@ -25,11 +25,7 @@ func (e *InvalidFieldError) Error() string {
 
 // logError logs the error to the server's logger and Sentry if it's enabled.
 func (s *Server) logError(ctx context.Context, err error) {
-	s.logger.ErrorContext(ctx, err.Error())
-
-	if hub := sentry.GetHubFromContext(ctx); hub != nil {
-		hub.CaptureException(err)
-	}
+	logging.Error(ctx, s.logger, err)
 }
 
 // logRequestError logs the error and sends a generic 500 Internal Server Error response to the client.
@@ -38,15 +34,9 @@ func (s *Server) logRequestError(ctx context.Context, r *http.Request, err error
 	method := r.Method
 	url := r.URL.String()
 
-	s.logger.ErrorContext(
-		ctx,
-		err.Error(),
+	logging.Error(ctx, s.logger, err,
 		slog.String("method", method),
 		slog.String("url", url))
-
-	if hub := sentry.GetHubFromContext(ctx); hub != nil {
-		hub.CaptureException(err)
-	}
 }
 
 // errorResponse writes an error response to the client. The msg is only logged to the server's logger and not sent back to the client.
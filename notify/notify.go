@@ -16,6 +16,10 @@ import (
 	"time"
 
 	"github.com/operationspark/service-signup/greenlight"
+	"github.com/operationspark/service-signup/i18n"
+	"github.com/operationspark/service-signup/metrics"
+	"github.com/operationspark/service-signup/sms"
+	"github.com/operationspark/service-signup/templates"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/sync/errgroup"
@@ -23,12 +27,23 @@ import (
 
 type (
 	Participant struct {
-		NameFirst           string `bson:"nameFirst"`
-		NameLast            string `bson:"nameLast"`
-		FullName            string `bson:"fullName"`
-		Cell                string `bson:"cell"`
-		Email               string `bson:"email"`
-		ZoomJoinURL         string `bson:"zoomJoinUrl"`
+		// Greenlight signup ID.
+		ID          string `bson:"_id"`
+		NameFirst   string `bson:"nameFirst"`
+		NameLast    string `bson:"nameLast"`
+		FullName    string `bson:"fullName"`
+		Cell        string `bson:"cell"`
+		Email       string `bson:"email"`
+		ZoomJoinURL string `bson:"zoomJoinUrl"`
+		// Preferred language for messages. "en" | "es". Empty for signups before language support.
+		Language string `bson:"language"`
+		// "IN_PERSON" | "VIRTUAL". Chosen by the person for hybrid sessions.
+		AttendingLocation string `bson:"attendingLocation"`
+		SMSOptOut         bool   `bson:"smsOptOut"`
+		// ID (ObjectID hex) of the person's Greenlight user join code.
+		UserJoinCodeID string `bson:"joinCode"`
+		// Greenlight program of the person's session.
+		ProgramID           string
 		SessionDate         time.Time
 		SessionLocationType string
 		SessionLocation     Location
@@ -37,6 +52,7 @@ type (
 	UpcomingSession struct {
 		ID           string           `bson:"_id"`
 		ProgramID    string           `bson:"programId"`
+		Cohort       string           `bson:"cohort"`
 		Times        greenlight.Times `bson:"times"`
 		Participants []Participant
 		LocationID   string `bson:"locationId"`
@@ -55,13 +71,32 @@ type (
 		client *mongo.Client
 	}
 
+	// SessionQuery selects sessions by ID, or the sessions of the given programs starting in [From, To).
+	SessionQuery struct {
+		ID   string
+		From time.Time
+		To   time.Time
+		// Greenlight program IDs. Defaults to the Info Session program.
+		ProgramIDs []string
+	}
+
+	// Program sets how participants of one Greenlight program are reminded of their sessions.
+	Program struct {
+		ID string
+		// SMS reminder template. Defaults to templates.Reminder.
+		ReminderTemplate templates.Name
+		// Reminder periods the program's participants get. Ex: "2 days", "1 hour". Empty means every period.
+		Reminders []Period
+	}
+
 	OSRenderer interface {
 		// CreateMessageURL creates a URL to the Operation Spark Message Template Renderer with URL encoded data.
 		CreateMessageURL(Participant) (string, error)
 	}
 
 	Store interface {
-		GetUpcomingSessions(context.Context, time.Duration) ([]*UpcomingSession, error)
+		// GetUpcomingSessions returns the sessions of the given programs (default: Info Sessions) starting in the given time from now.
+		GetUpcomingSessions(ctx context.Context, inFuture time.Duration, programIDs ...string) ([]*UpcomingSession, error)
 	}
 
 	Shortener interface {
@@ -72,8 +107,20 @@ type (
 		OSRendererService OSRenderer
 		ShortLinkService  Shortener
 		SMSService        SMSSender
-		Store             Store
-		Logger            *slog.Logger
+		// Sends SMS messages deferred until after quiet hours. Optional.
+		QueuedSMSService QueuedSMSSender
+		Store            Store
+		// Reads signups for the digest report. Optional.
+		DigestStore DigestStore
+		// Posts the signup digest report. Optional.
+		DigestNotifier Notifier
+		// SMS message templates. Defaults to the embedded templates.
+		Templates *templates.Registry
+		// Programs to send reminders for. Defaults to the Info Session program.
+		Programs []Program
+		// Window when the SMS service holds non-urgent messages. Reminders for sessions starting before the window ends are sent right away.
+		QuietHours sms.QuietHours
+		Logger     *slog.Logger
 	}
 
 	SMSSender interface {
@@ -81,11 +128,22 @@ type (
 		FormatCell(string) string
 	}
 
+	QueuedSMSSender interface {
+		// SendQueued sends every deferred SMS message that is due and returns the number of messages sent.
+		SendQueued(ctx context.Context) (int, error)
+	}
+
 	Server struct {
 		osMsSvc       OSRenderer
 		shortySrv     Shortener
 		store         Store
 		twilioService SMSSender
+		queuedSMS     QueuedSMSSender
+		digestStore   DigestStore
+		digestNotify  Notifier
+		templates     *templates.Registry
+		programs      []Program
+		quietHours    sms.QuietHours
 		logger        *slog.Logger
 	}
 
@@ -117,12 +175,26 @@ const (
 	CentralTZName        = "America/Chicago"
 )
 
+const (
+	// JobSendQueuedSMS sends SMS messages held back during the recipients' quiet hours.
+	JobSendQueuedSMS = "send-queued-sms"
+)
+
 func NewServer(o ServerOpts) *Server {
+	if len(o.Programs) == 0 {
+		o.Programs = []Program{{ID: InfoSessionProgramID}}
+	}
 	return &Server{
 		osMsSvc:       o.OSRendererService,
 		shortySrv:     o.ShortLinkService,
 		store:         o.Store,
 		twilioService: o.SMSService,
+		queuedSMS:     o.QueuedSMSService,
+		digestStore:   o.DigestStore,
+		digestNotify:  o.DigestNotifier,
+		templates:     o.Templates,
+		programs:      o.Programs,
+		quietHours:    o.QuietHours,
 		logger:        o.Logger,
 	}
 }
@@ -140,6 +212,16 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if reqBody.JobName == JobSendQueuedSMS {
+		s.sendQueuedSMS(w, r)
+		return
+	}
+
+	if reqBody.JobName == JobSignupDigest {
+		s.sendDigest(w, r, reqBody.JobArgs)
+		return
+	}
+
 	// Remind attendees for some period in the future.
 	// (1 hour, 2 days, etc)
 	inFuture, err := reqBody.JobArgs.Period.Parse()
@@ -150,14 +232,21 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Add timezone to the request context.
-	// TODO: Base the TZ on some location information somewhere
+	// Every recipient is assumed to be in Central Time. Signups don't record the person's time zone, and sessions are run from New Orleans.
 	tz, err := time.LoadLocation(CentralTZName)
 	if err != nil {
 		s.serverErrorResponse(w, r, fmt.Errorf("loadLocation: %v", err))
 		return
 	}
 	ctx := context.WithValue(r.Context(), contextKeyRecipientTZ.String(), tz)
-	sessions, err := s.store.GetUpcomingSessions(ctx, inFuture)
+	ctx = sms.WithRecipientTZ(ctx, tz)
+
+	programIDs := s.programsRemindedFor(inFuture)
+	if len(programIDs) == 0 {
+		s.notFoundResponse(w, r, fmt.Sprintf("no programs send %s reminders", reqBody.JobArgs.Period))
+		return
+	}
+	sessions, err := s.store.GetUpcomingSessions(ctx, inFuture, programIDs...)
 	if err != nil {
 		s.serverErrorResponse(w, r, fmt.Errorf("store.GetUpcomingSessions: %v", err))
 		return
@@ -183,6 +272,60 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// ProgramsRemindedFor returns the IDs of the programs whose reminder plan includes the period.
+func (s *Server) programsRemindedFor(period time.Duration) []string {
+	var ids []string
+	for _, p := range s.programs {
+		if len(p.Reminders) == 0 {
+			ids = append(ids, p.ID)
+			continue
+		}
+		for _, reminder := range p.Reminders {
+			if d, err := reminder.Parse(); err == nil && d == period {
+				ids = append(ids, p.ID)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// ProgramIDs returns the IDs of every configured program.
+func (s *Server) programIDs() []string {
+	ids := make([]string, len(s.programs))
+	for i, p := range s.programs {
+		ids[i] = p.ID
+	}
+	return ids
+}
+
+// Program returns the settings of the program with the given ID, or of the Info Session program if there are none.
+func (s *Server) program(id string) Program {
+	for _, p := range s.programs {
+		if p.ID == id {
+			return p
+		}
+	}
+	return Program{ID: InfoSessionProgramID}
+}
+
+// SendQueuedSMS sends the SMS messages deferred during quiet hours.
+func (s *Server) sendQueuedSMS(w http.ResponseWriter, r *http.Request) {
+	if s.queuedSMS == nil {
+		s.notFoundResponse(w, r, "SMS queue is not configured")
+		return
+	}
+
+	sent, err := s.queuedSMS.SendQueued(r.Context())
+	if err != nil {
+		s.serverErrorResponse(w, r, fmt.Errorf("sendQueued: %w (sent: %d)", err, sent))
+		return
+	}
+
+	s.logger.InfoContext(r.Context(), "sent queued SMS messages", slog.Int("count", sent))
+	w.WriteHeader(http.StatusOK)
+}
+
 func NewMongoService(dbClient *mongo.Client, dbName string) *MongoService {
 	return &MongoService{
 		dbName: dbName,
@@ -190,63 +333,123 @@ func NewMongoService(dbClient *mongo.Client, dbName string) *MongoService {
 	}
 }
 
-// GetUpcomingSessions queries the database for Info Sessions starting between not and some time in the future. Returns the upcoming Info Sessions and the email addresses of each session's prospective participants.
-func (m *MongoService) GetUpcomingSessions(ctx context.Context, inFuture time.Duration) ([]*UpcomingSession, error) {
-	sessions := m.client.Database(m.dbName).Collection("sessions")
+// GetUpcomingSessions queries the database for sessions of the given programs (default: Info Sessions) starting between now and some time in the future. Returns the upcoming sessions and the email addresses of each session's prospective participants.
+func (m *MongoService) GetUpcomingSessions(ctx context.Context, inFuture time.Duration, programIDs ...string) ([]*UpcomingSession, error) {
+	now := time.Now()
+	return m.GetSessions(ctx, SessionQuery{From: now, To: now.Add(inFuture), ProgramIDs: programIDs})
+}
 
-	infoSessionProgID := "5sTmB97DzcqCwEZFR"
-	filterDate := time.Now().Add(inFuture)
+// GetSessions returns the session with the query's ID, or the Info Sessions starting in the query's time range, with each session's participants.
+func (m *MongoService) GetSessions(ctx context.Context, q SessionQuery) ([]*UpcomingSession, error) {
+	sessions := m.client.Database(m.dbName).Collection("sessions")
 
-	var upcomingSessions []*UpcomingSession
-	sessCursor, err := sessions.Find(ctx, bson.M{
-		"programId": infoSessionProgID,
-		"times.start.dateTime": bson.M{
-			"$gte": time.Now(),
-			"$lte": filterDate,
-		},
-	})
+	programIDs := q.ProgramIDs
+	if len(programIDs) == 0 {
+		programIDs = []string{InfoSessionProgramID}
+	}
+	filter := bson.M{"_id": q.ID}
+	if q.ID == "" {
+		filter = bson.M{
+			"programId": bson.M{"$in": programIDs},
+			"times.start.dateTime": bson.M{
+				"$gte": q.From,
+				"$lt":  q.To,
+			},
+		}
+	}
 
+	var found []*UpcomingSession
+	sessCursor, err := sessions.Find(ctx, filter)
 	if err != nil {
-		return upcomingSessions, fmt.Errorf("sessions.Find: %w", err)
+		return found, fmt.Errorf("sessions.Find: %w", err)
 	}
 
-	if err = sessCursor.All(ctx, &upcomingSessions); err != nil {
-		return upcomingSessions, fmt.Errorf("sessions cursor.All(): %w", err)
+	if err = sessCursor.All(ctx, &found); err != nil {
+		return found, fmt.Errorf("sessions cursor.All(): %w", err)
 	}
 
-	// Fetch the attendees
-	signups := m.client.Database(m.dbName).Collection("signups")
-	locations := m.client.Database(m.dbName).Collection("locations")
-	for _, session := range upcomingSessions {
-		suCur, err := signups.Find(ctx, bson.M{"sessionId": session.ID})
-		if err != nil {
-			return upcomingSessions, fmt.Errorf("signups.Find: %w", err)
+	for _, session := range found {
+		if err := m.loadParticipants(ctx, session); err != nil {
+			return found, err
 		}
+	}
+	return found, nil
+}
 
-		// Get associated Location data
-		var loc greenlight.Location
-		res := locations.FindOne(ctx, bson.M{"_id": session.LocationID})
-		if res.Err() != nil {
-			return upcomingSessions, fmt.Errorf("locations.findOne: %w\nlocationId: %q", err, session.LocationID)
-		}
-		err = res.Decode(&loc)
-		if err != nil {
-			return upcomingSessions, fmt.Errorf("decode location: %w", err)
+// WithdrawnSignups returns the IDs of the participants whose signup staff marked as spam or canceled (Ex: from Slack). Greenlight keeps those signups, so their status is read from the signup service's own records.
+func (m *MongoService) withdrawnSignups(ctx context.Context, participants []Participant) (map[string]bool, error) {
+	ids := make([]string, 0, len(participants))
+	for _, p := range participants {
+		if p.ID != "" {
+			ids = append(ids, p.ID)
 		}
+	}
+	withdrawn := map[string]bool{}
+	if len(ids) == 0 {
+		return withdrawn, nil
+	}
 
-		var attendees []Participant
-		if err = suCur.All(ctx, &attendees); err != nil {
-			return upcomingSessions, fmt.Errorf("signups.cursor.All(): %w", err)
-		}
+	cur, err := m.client.Database(m.dbName).Collection("signupRecords").Find(ctx, bson.M{
+		"greenlightId": bson.M{"$in": ids},
+		"status":       bson.M{"$in": []string{"spam", "canceled"}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("signupRecords.Find: %w", err)
+	}
+	var records []struct {
+		GreenlightID string `bson:"greenlightId"`
+	}
+	if err := cur.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("signupRecords cursor.All(): %w", err)
+	}
+	for _, r := range records {
+		withdrawn[r.GreenlightID] = true
+	}
+	return withdrawn, nil
+}
+
+// LoadParticipants fetches the session's signups and location, and adds each signup to the session's participants.
+func (m *MongoService) loadParticipants(ctx context.Context, session *UpcomingSession) error {
+	signups := m.client.Database(m.dbName).Collection("signups")
+	locations := m.client.Database(m.dbName).Collection("locations")
+
+	suCur, err := signups.Find(ctx, bson.M{"sessionId": session.ID})
+	if err != nil {
+		return fmt.Errorf("signups.Find: %w", err)
+	}
+
+	// Get associated Location data
+	var loc greenlight.Location
+	res := locations.FindOne(ctx, bson.M{"_id": session.LocationID})
+	if res.Err() != nil {
+		return fmt.Errorf("locations.findOne: %w\nlocationId: %q", res.Err(), session.LocationID)
+	}
+	err = res.Decode(&loc)
+	if err != nil {
+		return fmt.Errorf("decode location: %w", err)
+	}
 
-		for _, p := range attendees {
-			p.SessionDate = session.Times.Start.DateTime
-			p.SessionLocationType = session.LocationType
-			p.SessionLocation = transformLocation(loc)
-			session.Participants = append(session.Participants, p)
+	var attendees []Participant
+	if err = suCur.All(ctx, &attendees); err != nil {
+		return fmt.Errorf("signups.cursor.All(): %w", err)
+	}
+
+	withdrawn, err := m.withdrawnSignups(ctx, attendees)
+	if err != nil {
+		return fmt.Errorf("withdrawnSignups: %w", err)
+	}
+
+	for _, p := range attendees {
+		if withdrawn[p.ID] {
+			continue
 		}
+		p.ProgramID = session.ProgramID
+		p.SessionDate = session.Times.Start.DateTime
+		p.SessionLocationType = session.LocationType
+		p.SessionLocation = transformLocation(loc)
+		session.Participants = append(session.Participants, p)
 	}
-	return upcomingSessions, nil
+	return nil
 }
 
 // SendSMSReminders sends an SMS message to each of the attendees in each of the given sessions. Each SMS is sent in it's own goroutine.
@@ -257,11 +460,6 @@ func (s *Server) sendSMSReminders(ctx context.Context, sessions []*UpcomingSessi
 			// https://stackoverflow.com/questions/40326723/go-vet-range-variable-captured-by-func-literal-when-using-go-routine-inside-of-f
 			errs.Go(func(p Participant) func() error {
 				return func() error {
-					msg, err := reminderMsg(ctx, *session)
-					if err != nil {
-						return fmt.Errorf("reminderMsg: %w", err)
-					}
-
 					infoURL, err := s.osMsSvc.CreateMessageURL(p)
 					if err != nil {
 						return fmt.Errorf("osMsSvc.CreateMessageURL: %w", err)
@@ -270,10 +468,20 @@ func (s *Server) sendSMSReminders(ctx context.Context, sessions []*UpcomingSessi
 					// The link will be a long URL even if there is an error
 					link, err := s.shortySrv.ShortenURL(ctx, infoURL)
 					if err != nil {
+						metrics.ShortenerFallbacks.Inc()
 						s.logError(ctx, fmt.Errorf("shortenURL %q: %w", infoURL, err))
 					}
 
-					msg = fmt.Sprintf("%s\nMore details: %s", msg, link)
+					msg, err := reminderMsg(ctx, s.templates, s.program(p.ProgramID).ReminderTemplate, p, link)
+					if err != nil {
+						return fmt.Errorf("reminderMsg: %w", err)
+					}
+
+					smsCtx := ctx
+					if s.startsBeforeQuietHoursEnd(ctx, p.SessionDate) {
+						smsCtx = sms.WithUrgent(ctx)
+					}
+
 					toNum := s.twilioService.FormatCell(p.Cell)
 					if dryRun {
 						s.logger.InfoContext(ctx, "Dry Run Mode: (not sending SMS)",
@@ -281,7 +489,12 @@ func (s *Server) sendSMSReminders(ctx context.Context, sessions []*UpcomingSessi
 							slog.String("smsBody", msg),
 						)
 					}
-					return s.twilioService.Send(ctx, toNum, msg)
+					if err := s.twilioService.Send(smsCtx, toNum, msg); err != nil {
+						metrics.Reminders.WithLabelValues(metrics.ResultFailed).Inc()
+						return err
+					}
+					metrics.Reminders.WithLabelValues(metrics.ResultSent).Inc()
+					return nil
 				}
 			}(p))
 		}
@@ -289,27 +502,43 @@ func (s *Server) sendSMSReminders(ctx context.Context, sessions []*UpcomingSessi
 	return errs.Wait()
 }
 
+// StartsBeforeQuietHoursEnd checks if a session starts before the recipient's current quiet hours window ends. Reminders for those sessions are urgent, or they would arrive after the session started.
+func (s *Server) startsBeforeQuietHoursEnd(ctx context.Context, start time.Time) bool {
+	tz, err := sms.RecipientTZ(ctx)
+	if err != nil {
+		s.logError(ctx, fmt.Errorf("recipientTZ: %w", err))
+		return true
+	}
+	return s.quietHours.EndsAfter(start, time.Now().In(tz))
+}
+
 func (s *Server) writeJSON(w http.ResponseWriter, status int, data any) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	return json.NewEncoder(w).Encode(data)
 }
 
-func reminderMsg(ctx context.Context, session UpcomingSession) (string, error) {
+// ReminderMsg renders the reminder SMS template in the participant's language. An empty template name uses templates.Reminder.
+func reminderMsg(ctx context.Context, tmpls *templates.Registry, name templates.Name, p Participant, link string) (string, error) {
 	tz, ok := ctx.Value(contextKeyRecipientTZ.String()).(*time.Location)
 	if !ok {
 		return "", errors.New("could not retrieve local timezone from context")
 	}
 
-	day := session.Times.Start.DateTime.In(tz).Format("Monday")
-	time := session.Times.Start.DateTime.In(tz).Format("3:04PM MST")
-	date := session.Times.Start.DateTime.In(tz).Format(" 1/2")
-	if isToday(session.Times.Start.DateTime) {
-		day = "today"
-		date = ""
+	if name == "" {
+		name = templates.Reminder
 	}
+	return tmpls.Render(name, i18n.Parse(p.Language), tz, NewReminderData(p, link))
+}
 
-	return fmt.Sprintf("Hi from Operation Spark! A friendly reminder that you have an Intro to Coding Info Session %s%s at %s.", day, date, time), nil
+// NewReminderData creates the reminder template data for a participant.
+func NewReminderData(p Participant, link string) templates.ReminderData {
+	return templates.ReminderData{
+		NameFirst:   p.NameFirst,
+		SessionDate: p.SessionDate,
+		Today:       isToday(p.SessionDate),
+		URL:         link,
+	}
 }
 
 // IsToday is checks if the given time is today.
@@ -326,6 +555,9 @@ func (r *Request) fromJSON(body io.Reader) error {
 // Acceptable periods are "day(s)", "hour(s)", "minute(s)", "min(s)".
 func (p Period) Parse() (time.Duration, error) {
 	parts := strings.Fields(string(p))
+	if len(parts) != 2 {
+		return time.Duration(0), fmt.Errorf(`invalid period %q: want a number and a unit, Ex: "2 days"`, p)
+	}
 	rawVal := parts[0]
 	val, err := strconv.Atoi(rawVal)
 	if err != nil {
@@ -17,6 +17,8 @@ import (
 
 	"github.com/brianvoe/gofakeit/v6"
 	"github.com/operationspark/service-signup/greenlight"
+	"github.com/operationspark/service-signup/sms"
+	"github.com/operationspark/service-signup/templates"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
 )
@@ -25,15 +27,29 @@ type (
 	MockSMSService struct {
 		called     bool
 		calledWith []string
+		// Whether the last message was marked sms.WithUrgent.
+		urgent bool
 	}
 
-	MockOSRenderer  struct{}
+	MockOSRenderer struct {
+		err error
+	}
 	MockShortLinker struct{}
+
+	MockQueuedSMSService struct {
+		called bool
+	}
 )
 
+func (m *MockQueuedSMSService) SendQueued(ctx context.Context) (int, error) {
+	m.called = true
+	return 1, nil
+}
+
 func (m *MockSMSService) Send(ctx context.Context, toNum string, msg string) error {
 	m.called = true
 	m.calledWith = []string{toNum, msg}
+	m.urgent = sms.IsUrgent(ctx)
 	return nil
 }
 
@@ -42,7 +58,7 @@ func (m *MockSMSService) FormatCell(cell string) string {
 }
 
 func (m MockOSRenderer) CreateMessageURL(Participant) (string, error) {
-	return "", nil
+	return "", m.err
 }
 
 func (m MockShortLinker) ShortenURL(ctx context.Context, url string) (string, error) {
@@ -104,6 +120,59 @@ func TestGetUpcomingSessions(t *testing.T) {
 		}
 	})
 
+	t.Run("skips signups canceled or marked as spam by staff", func(t *testing.T) {
+		mSrv := &MongoService{
+			dbName: dbName,
+			client: dbClient,
+		}
+
+		err := dropDatabase(context.Background(), mSrv)
+		require.NoError(t, err)
+
+		sessID := insertFutureSession(t, mSrv, time.Hour*24)
+		err = insertRandSignups(t, mSrv, sessID, 3)
+		require.NoError(t, err)
+
+		before, err := mSrv.GetSessions(context.Background(), SessionQuery{ID: sessID})
+		require.NoError(t, err)
+		require.Len(t, before[0].Participants, 3)
+
+		canceled, spam, kept := before[0].Participants[0], before[0].Participants[1], before[0].Participants[2]
+		_, err = mSrv.client.Database(mSrv.dbName).Collection("signupRecords").InsertMany(context.Background(), []any{
+			bson.M{"_id": "record-1", "greenlightId": canceled.ID, "status": "canceled"},
+			bson.M{"_id": "record-2", "greenlightId": spam.ID, "status": "spam"},
+			bson.M{"_id": "record-3", "greenlightId": kept.ID, "status": "active"},
+		})
+		require.NoError(t, err)
+
+		got, err := mSrv.GetSessions(context.Background(), SessionQuery{ID: sessID})
+		require.NoError(t, err)
+		require.Len(t, got[0].Participants, 1)
+		require.Equal(t, kept.ID, got[0].Participants[0].ID)
+	})
+
+	t.Run("retrieves a session by ID", func(t *testing.T) {
+		mSrv := &MongoService{
+			dbName: dbName,
+			client: dbClient,
+		}
+
+		err := dropDatabase(context.Background(), mSrv)
+		require.NoError(t, err)
+
+		insertFutureSession(t, mSrv, time.Hour*24)
+		pastSessID := insertFutureSession(t, mSrv, -time.Hour*24*30)
+		err = insertRandSignups(t, mSrv, pastSessID, 3)
+		require.NoError(t, err)
+
+		got, err := mSrv.GetSessions(context.Background(), SessionQuery{ID: pastSessID})
+		require.NoError(t, err)
+
+		require.Len(t, got, 1)
+		require.Equal(t, pastSessID, got[0].ID)
+		require.Len(t, got[0].Participants, 3)
+	})
+
 	t.Run("handles session locations with string values for 'googlePlace' field. (Schemaless legacy data)", func(t *testing.T) {
 		mSrv := &MongoService{
 			dbName: dbName,
@@ -239,12 +308,87 @@ func TestServer(t *testing.T) {
 	})
 }
 
+func TestSendSMSReminders(t *testing.T) {
+	ctz, err := time.LoadLocation(CentralTZName)
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), contextKeyRecipientTZ.String(), ctz)
+	ctx = sms.WithRecipientTZ(ctx, ctz)
+
+	send := func(t *testing.T, q sms.QuietHours, start time.Time) *MockSMSService {
+		mockTwilio := &MockSMSService{}
+		srv := NewServer(ServerOpts{
+			OSRendererService: MockOSRenderer{},
+			ShortLinkService:  MockShortLinker{},
+			SMSService:        mockTwilio,
+			Templates:         templates.Default(),
+			QuietHours:        q,
+			Logger:            slog.Default(),
+		})
+		sessions := []*UpcomingSession{{Participants: []Participant{{NameFirst: "Henri", Cell: "555-123-4567", SessionDate: start}}}}
+		require.NoError(t, srv.sendSMSReminders(ctx, sessions, false))
+		require.True(t, mockTwilio.called)
+		return mockTwilio
+	}
+
+	now := time.Now().In(ctz)
+	// Quiet hours covering the rest of the day, ending tomorrow at this hour.
+	allDay := sms.QuietHours{Start: now.Hour(), End: now.Add(-time.Hour).Hour()}
+
+	t.Run("marks reminders urgent for sessions starting before quiet hours end", func(t *testing.T) {
+		require.True(t, send(t, allDay, now.Add(time.Hour)).urgent)
+	})
+
+	t.Run("lets the SMS service hold reminders for later sessions", func(t *testing.T) {
+		require.False(t, send(t, allDay, now.Add(48*time.Hour)).urgent)
+	})
+
+	t.Run("fails without the session link", func(t *testing.T) {
+		mockTwilio := &MockSMSService{}
+		srv := NewServer(ServerOpts{
+			OSRendererService: MockOSRenderer{err: errors.New("name is required")},
+			ShortLinkService:  MockShortLinker{},
+			SMSService:        mockTwilio,
+			Templates:         templates.Default(),
+			Logger:            slog.Default(),
+		})
+		sessions := []*UpcomingSession{{Participants: []Participant{{Cell: "555-123-4567", SessionDate: now.Add(time.Hour)}}}}
+
+		err := srv.sendSMSReminders(ctx, sessions, false)
+		require.ErrorContains(t, err, "name is required")
+		require.False(t, mockTwilio.called)
+	})
+}
+
+func TestSendQueuedSMS(t *testing.T) {
+	t.Run("Sends SMS messages deferred during quiet hours", func(t *testing.T) {
+		var body bytes.Buffer
+		err := json.NewEncoder(&body).Encode(Request{JobName: JobSendQueuedSMS})
+		require.NoError(t, err)
+
+		req := mustMakeReq(t, &body)
+		resp := httptest.NewRecorder()
+
+		mockTwilio := MockSMSService{}
+		mockQueue := MockQueuedSMSService{}
+		srv := NewServer(ServerOpts{
+			SMSService:       &mockTwilio,
+			QueuedSMSService: &mockQueue,
+			Logger:           slog.Default(),
+		})
+
+		srv.ServeHTTP(resp, req)
+
+		require.Equal(t, http.StatusOK, resp.Result().StatusCode)
+		require.True(t, mockQueue.called)
+		require.False(t, mockTwilio.called, "should not send reminders")
+	})
+}
+
 func TestReminderMsg(t *testing.T) {
 	t.Run(`Reminder message includes "today" if the session is today`, func(t *testing.T) {
 		ctx := context.WithValue(context.Background(), contextKeyRecipientTZ.String(), time.UTC)
-		session := UpcomingSession{}
-		session.Times.Start.DateTime = time.Now().Add(time.Hour * 5)
-		got, err := reminderMsg(ctx, session)
+		p := Participant{SessionDate: time.Now().Add(time.Hour * 5)}
+		got, err := reminderMsg(ctx, templates.Default(), "", p, "")
 		require.NoError(t, err)
 		want := "Hi from Operation Spark! A friendly reminder that you have an Intro to Coding Info Session today at "
 		require.Contains(t, got, want)
@@ -252,18 +396,32 @@ func TestReminderMsg(t *testing.T) {
 
 	t.Run("Reminder message includes the day of the week if the session is not today", func(t *testing.T) {
 		ctx := context.WithValue(context.Background(), contextKeyRecipientTZ.String(), time.UTC)
-		session := UpcomingSession{}
 		mardiGras, err := time.Parse("Jan 02, 2006", "Feb 21, 2023") // Mardi Gras
 		require.NoError(t, err)
 
-		session.Times.Start.DateTime = mardiGras
+		p := Participant{SessionDate: mardiGras}
 
-		got, err := reminderMsg(ctx, session)
+		got, err := reminderMsg(ctx, templates.Default(), "", p, "")
 		require.NoError(t, err)
 
 		want := "Tuesday 2/21 at "
 		require.Contains(t, got, want)
 	})
+
+	t.Run("Reminder message is translated to the participant's language", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), contextKeyRecipientTZ.String(), time.UTC)
+		mardiGras, err := time.Parse("Jan 02, 2006", "Feb 21, 2023") // Mardi Gras
+		require.NoError(t, err)
+
+		p := Participant{SessionDate: mardiGras, Language: "es"}
+
+		got, err := reminderMsg(ctx, templates.Default(), "", p, "https://ospk.org/abcd123456")
+		require.NoError(t, err)
+
+		require.Contains(t, got, "¡Hola de parte de Operation Spark!")
+		require.Contains(t, got, "martes 21/2 a las ")
+		require.Contains(t, got, "Más detalles: https://ospk.org/abcd123456")
+	})
 }
 
 // ** Test Helpers ** //
@@ -335,3 +493,17 @@ func insertRandSignups(t *testing.T, m *MongoService, infoSessionID string, n in
 func dropDatabase(ctx context.Context, m *MongoService) error {
 	return m.client.Database(m.dbName).Drop(ctx)
 }
+
+func TestProgramsRemindedFor(t *testing.T) {
+	t.Run("only reminds programs whose reminder plan includes the period", func(t *testing.T) {
+		srv := NewServer(ServerOpts{Programs: []Program{
+			{ID: InfoSessionProgramID},
+			{ID: "workshop", Reminders: []Period{"2 days", "1 hour"}},
+			{ID: "bootcamp", Reminders: []Period{"1 day"}},
+		}})
+
+		require.Equal(t, []string{InfoSessionProgramID, "workshop"}, srv.programsRemindedFor(time.Hour))
+		require.Equal(t, []string{InfoSessionProgramID, "bootcamp"}, srv.programsRemindedFor(24*time.Hour))
+		require.Equal(t, InfoSessionProgramID, srv.program("unknown").ID)
+	})
+}
@@ -0,0 +1,392 @@
+package signup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/operationspark/service-signup/metrics"
+	"go.opentelemetry.io/otel/codes"
+	"golang.org/x/sync/errgroup"
+)
+
+type (
+	// SignupField is a Signup value set by one task for other tasks to use.
+	signupField string
+
+	// TaskSpec declares a task's place in the signup pipeline.
+	taskSpec struct {
+		// Name used to configure the task. Ex: "sms".
+		key string
+		// Fields the task reads. The task runs after every task that produces them.
+		consumes []signupField
+		// Fields the task sets. Other changes the task makes to the signup are discarded.
+		produces []signupField
+		// External service the task calls. Tasks calling the same integration share a circuit breaker. Empty means no circuit breaker.
+		integration string
+		// Safe to run more than once for the same signup, so failed attempts are retried.
+		idempotent bool
+	}
+
+	// SpecifiedTask is implemented by tasks that declare their pipeline spec.
+	// Tasks without a spec are keyed by name and consume the fields set before the pipeline existed (Zoom URL, join code, short link).
+	specifiedTask interface {
+		spec() taskSpec
+	}
+
+	// Pipeline runs signup tasks concurrently. Each task starts once every task producing a field it consumes has finished.
+	pipeline struct {
+		steps []pipelineStep
+	}
+
+	pipelineStep struct {
+		task     mutationTask
+		spec     taskSpec
+		required bool
+		policy   taskPolicy
+		breaker  *circuitBreaker
+		// Indexes of the steps this step waits for.
+		deps []int
+	}
+
+	// PipelineOptions override task settings by key.
+	pipelineOptions struct {
+		// Tasks to skip.
+		disabled []string
+		// Tasks that fail the signup when they fail.
+		required []string
+		// Tasks that are logged and skipped when they fail.
+		optional []string
+		// Timeout and retries for every task.
+		policy taskPolicy
+		// Timeouts that replace policy.timeout, by task key.
+		timeouts map[string]time.Duration
+		breaker  breakerOptions
+	}
+
+	// TaskOutcome is the result of one signup task. Outcomes are saved with the signup record.
+	taskOutcome struct {
+		Task   string            `bson:"task" json:"task"`
+		Status taskOutcomeStatus `bson:"status" json:"status"`
+		Error  string            `bson:"error,omitempty" json:"error,omitempty"`
+		// Time the task took, including retries.
+		DurationMillis int64 `bson:"durationMs" json:"durationMs"`
+	}
+
+	taskOutcomeStatus string
+
+	// FuncTask adapts a function to a pipeline task.
+	funcTask struct {
+		taskSpec
+		taskName string
+		required bool
+		fn       func(ctx context.Context, su *Signup, logger *slog.Logger) error
+	}
+)
+
+const (
+	fieldZoomJoinURL    signupField = "zoomJoinURL"
+	fieldJoinCode       signupField = "joinCode"
+	fieldShortLink      signupField = "shortLink"
+	fieldSignupID       signupField = "signupID"
+	fieldConversationID signupField = "conversationID"
+)
+
+const (
+	taskSucceeded taskOutcomeStatus = "succeeded"
+	// The task failed. Failures of non-required tasks don't fail the signup.
+	taskFailed taskOutcomeStatus = "failed"
+	// The task did not run because its integration's circuit was open or a required task failed first.
+	taskSkipped taskOutcomeStatus = "skipped"
+)
+
+// Signup task keys.
+const (
+	taskZoom             = "zoom"
+	taskJoinCode         = "joinCode"
+	taskShortLink        = "shortLink"
+	taskGreenlight       = "greenlight"
+	taskWelcomeEmail     = "welcomeEmail"
+	taskSlack            = "slack"
+	taskSMS              = "sms"
+	taskSnapMail         = "snapMail"
+	taskConversationLink = "conversationLink"
+)
+
+// SignupTaskKeys lists the tasks that can be configured with SIGNUP_TASKS_*.
+var signupTaskKeys = []string{
+	taskZoom,
+	taskJoinCode,
+	taskShortLink,
+	taskGreenlight,
+	taskWelcomeEmail,
+	taskSlack,
+	taskSMS,
+	taskSnapMail,
+	taskConversationLink,
+}
+
+// FieldCopiers copy each field from a task's copy of the signup back to the shared signup.
+var fieldCopiers = map[signupField]func(dst, src *Signup){
+	fieldZoomJoinURL: func(dst, src *Signup) {
+		dst.zoomMeetingID = src.zoomMeetingID
+		dst.zoomMeetingURL = src.zoomMeetingURL
+	},
+	fieldJoinCode: func(dst, src *Signup) {
+		dst.userJoinCode = src.userJoinCode
+		dst.JoinCode = src.JoinCode
+	},
+	fieldShortLink:      func(dst, src *Signup) { dst.ShortLink = src.ShortLink },
+	fieldSignupID:       func(dst, src *Signup) { dst.id = src.id },
+	fieldConversationID: func(dst, src *Signup) { dst.conversationID = src.conversationID },
+}
+
+// NewPipeline orders the tasks by their dependencies. It returns an error if the options name an unknown task or the dependencies form a cycle.
+// Each integration gets one circuit breaker, shared by every run of the pipeline.
+func newPipeline(tasks []mutationTask, o pipelineOptions) (*pipeline, error) {
+	var steps []pipelineStep
+	keys := map[string]bool{}
+	breakers := map[string]*circuitBreaker{}
+	for _, t := range tasks {
+		spec := specOf(t)
+		keys[spec.key] = true
+		if slices.Contains(o.disabled, spec.key) {
+			continue
+		}
+		required := t.isRequired()
+		if slices.Contains(o.required, spec.key) {
+			required = true
+		}
+		if slices.Contains(o.optional, spec.key) {
+			required = false
+		}
+		policy := o.policy
+		if timeout, ok := o.timeouts[spec.key]; ok {
+			policy.timeout = timeout
+		}
+		var breaker *circuitBreaker
+		if spec.integration != "" && o.breaker.failures > 0 {
+			if breakers[spec.integration] == nil {
+				breakers[spec.integration] = newCircuitBreaker(spec.integration, o.breaker)
+			}
+			breaker = breakers[spec.integration]
+		}
+		steps = append(steps, pipelineStep{task: t, spec: spec, required: required, policy: policy, breaker: breaker})
+	}
+
+	for _, list := range [][]string{o.disabled, o.required, o.optional} {
+		for _, key := range list {
+			if !keys[key] {
+				return nil, fmt.Errorf("unknown task %q", key)
+			}
+		}
+	}
+	for key := range o.timeouts {
+		if !keys[key] {
+			return nil, fmt.Errorf("unknown task %q", key)
+		}
+	}
+
+	producers := map[signupField][]int{}
+	for i, step := range steps {
+		for _, f := range step.spec.produces {
+			if _, ok := fieldCopiers[f]; !ok {
+				return nil, fmt.Errorf("task %q produces unknown field %q", step.spec.key, f)
+			}
+			producers[f] = append(producers[f], i)
+		}
+	}
+	for i := range steps {
+		for _, f := range steps[i].spec.consumes {
+			for _, p := range producers[f] {
+				if p != i && !slices.Contains(steps[i].deps, p) {
+					steps[i].deps = append(steps[i].deps, p)
+				}
+			}
+		}
+	}
+
+	p := &pipeline{steps: steps}
+	if err := p.checkCycles(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// SpecOf returns the task's spec, or the default spec for tasks that don't declare one.
+func specOf(t mutationTask) taskSpec {
+	if st, ok := t.(specifiedTask); ok {
+		return st.spec()
+	}
+	return taskSpec{
+		key:      t.name(),
+		consumes: []signupField{fieldZoomJoinURL, fieldJoinCode, fieldShortLink},
+	}
+}
+
+// CheckCycles returns an error if any steps depend on each other.
+func (p *pipeline) checkCycles() error {
+	// Kahn's algorithm: repeatedly remove steps with no remaining dependencies.
+	remaining := make([]int, len(p.steps))
+	dependents := make([][]int, len(p.steps))
+	var ready []int
+	for i, step := range p.steps {
+		remaining[i] = len(step.deps)
+		for _, d := range step.deps {
+			dependents[d] = append(dependents[d], i)
+		}
+		if remaining[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	for len(ready) > 0 {
+		i := ready[0]
+		ready = ready[1:]
+		for _, d := range dependents[i] {
+			remaining[d]--
+			if remaining[d] == 0 {
+				ready = append(ready, d)
+			}
+		}
+	}
+
+	var cycle []string
+	for i, n := range remaining {
+		if n > 0 {
+			cycle = append(cycle, p.steps[i].spec.key)
+		}
+	}
+	if len(cycle) > 0 {
+		return fmt.Errorf("task dependency cycle: %s", strings.Join(cycle, ", "))
+	}
+	return nil
+}
+
+// Run runs every task and merges the fields they produce into su. It returns the first error from a required task, after which tasks that have not started are skipped.
+// The outcome of each task is set on su in pipeline order. Tasks with a key in skip are not run, and tasks waiting on them run without their fields.
+func (p *pipeline) run(ctx context.Context, su *Signup, logger *slog.Logger, skip ...string) error {
+	var mu sync.Mutex
+	done := make([]chan struct{}, len(p.steps))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	outcomes := make([]taskOutcome, len(p.steps))
+	for i, step := range p.steps {
+		outcomes[i] = taskOutcome{Task: step.spec.key, Status: taskSkipped}
+	}
+
+	g, gCtx := errgroup.WithContext(ctx)
+	for i, step := range p.steps {
+		g.Go(func() error {
+			defer close(done[i])
+			for _, d := range step.deps {
+				select {
+				case <-done[d]:
+				case <-gCtx.Done():
+					return nil
+				}
+			}
+			if gCtx.Err() != nil || slices.Contains(skip, step.spec.key) {
+				return nil
+			}
+
+			// Each task gets its own copy so concurrent tasks don't race on the signup.
+			mu.Lock()
+			taskSignup := *su
+			mu.Unlock()
+
+			outcome, err := p.runStep(gCtx, step, &taskSignup, logger)
+
+			mu.Lock()
+			for _, f := range step.spec.produces {
+				fieldCopiers[f](su, &taskSignup)
+			}
+			outcomes[i] = outcome
+			mu.Unlock()
+			return err
+		})
+	}
+	err := g.Wait()
+	su.taskOutcomes = outcomes
+	return err
+}
+
+func (p *pipeline) runStep(ctx context.Context, step pipelineStep, su *Signup, logger *slog.Logger) (taskOutcome, error) {
+	t := step.task
+	ctx, span := tracer.Start(ctx, t.name())
+	defer span.End()
+
+	start := time.Now()
+	err := runWithPolicy(ctx, step, su, logger)
+	elapsed := time.Since(start)
+	metrics.TaskDuration.WithLabelValues(t.name()).Observe(elapsed.Seconds())
+	outcome := taskOutcome{Task: step.spec.key, Status: taskSucceeded, DurationMillis: elapsed.Milliseconds()}
+	if err == nil {
+		return outcome, nil
+	}
+	outcome.Error = err.Error()
+	if !step.required && errors.Is(err, errCircuitOpen) {
+		outcome.Status = taskSkipped
+		logger.InfoContext(ctx,
+			"non-mandatory task skipped",
+			slog.String("task", t.name()),
+			slog.String("error", err.Error()))
+		return outcome, nil
+	}
+
+	outcome.Status = taskFailed
+	metrics.TaskErrors.WithLabelValues(t.name()).Inc()
+	span.RecordError(err)
+	if step.required {
+		span.SetStatus(codes.Error, err.Error())
+		return outcome, fmt.Errorf("task failed: %q: %w", t.name(), err)
+	}
+	logger.InfoContext(ctx,
+		"non-mandatory task failed",
+		slog.String("task", t.name()),
+		slog.String("error", err.Error()))
+	return outcome, nil
+}
+
+func (f funcTask) run(ctx context.Context, su *Signup, logger *slog.Logger) error {
+	return f.fn(ctx, su, logger)
+}
+
+func (f funcTask) name() string {
+	return f.taskName
+}
+
+func (f funcTask) isRequired() bool {
+	return f.required
+}
+
+func (f funcTask) spec() taskSpec {
+	return f.taskSpec
+}
+
+// RunnerTask runs a post-signup Runner once the Greenlight signup ID and the SMS conversation ID are set. Runners never fail the signup.
+func runnerTask(r Runner) funcTask {
+	return funcTask{
+		taskSpec: taskSpec{
+			key:         taskConversationLink,
+			consumes:    []signupField{fieldSignupID, fieldConversationID},
+			integration: "messenger",
+		},
+		taskName: r.Name(),
+		fn: func(ctx context.Context, su *Signup, logger *slog.Logger) error {
+			// Users who opt out of texts have no conversation.
+			if !su.SMSOptIn {
+				return nil
+			}
+			if su.conversationID == nil || su.id == nil {
+				return fmt.Errorf("conversationID (%v) or signup ID (%v) is nil", su.conversationID, su.id)
+			}
+			return r.Run(ctx, *su.conversationID, *su.id)
+		},
+	}
+}
@@ -0,0 +1,191 @@
+package signup
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipeline(t *testing.T) {
+	t.Run("runs tasks after the tasks that produce their inputs", func(t *testing.T) {
+		var mu sync.Mutex
+		var order []string
+		var smsLink string
+		record := func(key string) {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, key)
+		}
+
+		sms := funcTask{
+			taskSpec: taskSpec{key: "sms", consumes: []signupField{fieldShortLink}, produces: []signupField{fieldConversationID}},
+			fn: func(ctx context.Context, su *Signup, logger *slog.Logger) error {
+				smsLink = su.ShortLink
+				convoID := "CH123"
+				su.conversationID = &convoID
+				record("sms")
+				return nil
+			},
+		}
+		shortLink := funcTask{
+			taskSpec: taskSpec{key: "shortLink", produces: []signupField{fieldShortLink}},
+			fn: func(ctx context.Context, su *Signup, logger *slog.Logger) error {
+				// Give the SMS task a chance to run early if it isn't waiting.
+				time.Sleep(10 * time.Millisecond)
+				su.ShortLink = "https://ospk.org/abc1234567"
+				record("shortLink")
+				return nil
+			},
+		}
+
+		p, err := newPipeline([]mutationTask{sms, shortLink}, pipelineOptions{})
+		require.NoError(t, err)
+
+		su := Signup{}
+		err = p.run(context.Background(), &su, slog.Default())
+		require.NoError(t, err)
+		require.Equal(t, []string{"shortLink", "sms"}, order)
+		require.Equal(t, "https://ospk.org/abc1234567", smsLink)
+		require.Equal(t, "https://ospk.org/abc1234567", su.ShortLink)
+		require.Equal(t, "CH123", *su.conversationID)
+	})
+
+	t.Run("discards changes to fields a task does not produce", func(t *testing.T) {
+		task := funcTask{
+			taskSpec: taskSpec{key: "greenlight", produces: []signupField{fieldSignupID}},
+			fn: func(ctx context.Context, su *Signup, logger *slog.Logger) error {
+				id := "gl123"
+				su.id = &id
+				su.ShortLink = "https://example.com"
+				return nil
+			},
+		}
+		p, err := newPipeline([]mutationTask{task}, pipelineOptions{})
+		require.NoError(t, err)
+
+		su := Signup{}
+		require.NoError(t, p.run(context.Background(), &su, slog.Default()))
+		require.Equal(t, "gl123", *su.id)
+		require.Empty(t, su.ShortLink)
+	})
+
+	t.Run("stops at the first required task failure", func(t *testing.T) {
+		dependentRan := false
+		failing := funcTask{
+			taskSpec: taskSpec{key: "joinCode", produces: []signupField{fieldJoinCode}},
+			required: true,
+			fn: func(ctx context.Context, su *Signup, logger *slog.Logger) error {
+				return errors.New("database down")
+			},
+		}
+		dependent := funcTask{
+			taskSpec: taskSpec{key: "greenlight", consumes: []signupField{fieldJoinCode}},
+			fn: func(ctx context.Context, su *Signup, logger *slog.Logger) error {
+				dependentRan = true
+				return nil
+			},
+		}
+		p, err := newPipeline([]mutationTask{failing, dependent}, pipelineOptions{})
+		require.NoError(t, err)
+
+		su := Signup{}
+		err = p.run(context.Background(), &su, slog.Default())
+		require.ErrorContains(t, err, "database down")
+		require.False(t, dependentRan)
+		require.Equal(t, []taskOutcome{
+			{Task: "joinCode", Status: taskFailed, Error: "database down"},
+			{Task: "greenlight", Status: taskSkipped},
+		}, su.taskOutcomes)
+	})
+
+	t.Run("continues after optional task failures", func(t *testing.T) {
+		dependentRan := false
+		failing := funcTask{
+			taskSpec: taskSpec{key: "sms", produces: []signupField{fieldConversationID}},
+			fn: func(ctx context.Context, su *Signup, logger *slog.Logger) error {
+				return errors.New("invalid number")
+			},
+		}
+		dependent := funcTask{
+			taskSpec: taskSpec{key: "conversationLink", consumes: []signupField{fieldConversationID}},
+			fn: func(ctx context.Context, su *Signup, logger *slog.Logger) error {
+				dependentRan = true
+				return nil
+			},
+		}
+		p, err := newPipeline([]mutationTask{failing, dependent}, pipelineOptions{})
+		require.NoError(t, err)
+
+		require.NoError(t, p.run(context.Background(), &Signup{}, slog.Default()))
+		require.True(t, dependentRan)
+	})
+
+	t.Run("skips tasks for one run", func(t *testing.T) {
+		ran := map[string]bool{}
+		var mu sync.Mutex
+		task := func(key string) funcTask {
+			return funcTask{
+				taskSpec: taskSpec{key: key},
+				fn: func(ctx context.Context, su *Signup, logger *slog.Logger) error {
+					mu.Lock()
+					defer mu.Unlock()
+					ran[key] = true
+					return nil
+				},
+			}
+		}
+		p, err := newPipeline([]mutationTask{task("slack"), task("sms")}, pipelineOptions{})
+		require.NoError(t, err)
+
+		su := Signup{}
+		require.NoError(t, p.run(context.Background(), &su, slog.Default(), "slack"))
+		require.Equal(t, map[string]bool{"sms": true}, ran)
+		require.Equal(t, taskSkipped, su.taskOutcomes[0].Status)
+
+		require.NoError(t, p.run(context.Background(), &su, slog.Default()))
+		require.True(t, ran["slack"])
+	})
+
+	t.Run("applies task options", func(t *testing.T) {
+		slackRan := false
+		slack := funcTask{
+			taskSpec: taskSpec{key: "slack"},
+			fn: func(ctx context.Context, su *Signup, logger *slog.Logger) error {
+				slackRan = true
+				return nil
+			},
+		}
+		failing := funcTask{
+			taskSpec: taskSpec{key: "snapMail"},
+			fn: func(ctx context.Context, su *Signup, logger *slog.Logger) error {
+				return errors.New("snap mail down")
+			},
+		}
+
+		p, err := newPipeline([]mutationTask{slack, failing}, pipelineOptions{
+			disabled: []string{"slack"},
+			required: []string{"snapMail"},
+		})
+		require.NoError(t, err)
+
+		err = p.run(context.Background(), &Signup{}, slog.Default())
+		require.ErrorContains(t, err, "snap mail down")
+		require.False(t, slackRan)
+
+		_, err = newPipeline([]mutationTask{slack}, pipelineOptions{disabled: []string{"fax"}})
+		require.ErrorContains(t, err, `unknown task "fax"`)
+	})
+
+	t.Run("rejects dependency cycles", func(t *testing.T) {
+		a := funcTask{taskSpec: taskSpec{key: "a", consumes: []signupField{fieldShortLink}, produces: []signupField{fieldJoinCode}}}
+		b := funcTask{taskSpec: taskSpec{key: "b", consumes: []signupField{fieldJoinCode}, produces: []signupField{fieldShortLink}}}
+
+		_, err := newPipeline([]mutationTask{a, b}, pipelineOptions{})
+		require.ErrorContains(t, err, "task dependency cycle: a, b")
+	})
+}
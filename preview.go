@@ -0,0 +1,111 @@
+package signup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/operationspark/service-signup/i18n"
+	"github.com/operationspark/service-signup/notify"
+	"github.com/operationspark/service-signup/templates"
+)
+
+type (
+	// previewRequest is the request body for the template preview endpoint.
+	// Either Signup or Participant is used as the template data, depending on the template.
+	previewRequest struct {
+		Template    templates.Name      `json:"template"`
+		Language    i18n.Language       `json:"language"`
+		Signup      *Signup             `json:"signup,omitempty"`
+		Participant *notify.Participant `json:"participant,omitempty"`
+		// Info session details link. Defaults to a sample short link.
+		URL string `json:"url,omitempty"`
+	}
+
+	previewResponse struct {
+		Body         string `json:"body"`
+		Segments     int    `json:"segments"`
+		Encoding     string `json:"encoding"`
+		Budget       int    `json:"budget"`
+		WithinBudget bool   `json:"withinBudget"`
+	}
+)
+
+const previewSampleURL = "https://oprk.org/kRds5MKvKI"
+
+// HandlePreview renders an SMS template against a sample Signup or Participant so copy changes can be reviewed before they go out.
+func (ss *signupServer) HandlePreview(w http.ResponseWriter, r *http.Request) {
+	defer func() { _ = r.Body.Close() }()
+
+	if r.Method != http.MethodPost {
+		ss.errorResponse(w, r, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req previewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		ss.badRequestResponse(w, r, fmt.Errorf("invalid JSON body: %w", err).Error())
+		return
+	}
+
+	data, err := req.templateData()
+	if err != nil {
+		ss.badRequestResponse(w, r, err.Error())
+		return
+	}
+
+	ctz, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		ss.serverErrorResponse(w, r, fmt.Errorf("loadLocation: %w", err))
+		return
+	}
+
+	body, err := ss.templates.Execute(req.Template, req.Language, ctz, data)
+	if err != nil {
+		ss.badRequestResponse(w, r, err.Error())
+		return
+	}
+
+	segments := templates.Segments(body)
+	resp := previewResponse{
+		Body:         body,
+		Segments:     segments,
+		Encoding:     templates.Encoding(body),
+		Budget:       ss.templates.SegmentBudget(),
+		WithinBudget: segments <= ss.templates.SegmentBudget(),
+	}
+	if err := ss.writeJSON(w, http.StatusOK, resp); err != nil {
+		ss.serverErrorResponse(w, r, fmt.Errorf("write 'ok' response: %w", err))
+	}
+}
+
+// TemplateData creates the data for the requested template from the sample Signup or Participant.
+func (req previewRequest) templateData() (any, error) {
+	url := req.URL
+	if url == "" {
+		url = previewSampleURL
+	}
+
+	switch req.Template {
+	case templates.SignupConfirmation, templates.EmailBounced:
+		if req.Signup == nil {
+			return nil, fmt.Errorf("'signup' is required for the %q template", req.Template)
+		}
+		return templates.SignupData{
+			NameFirst:     req.Signup.NameFirst,
+			StartDateTime: req.Signup.StartDateTime,
+			URL:           url,
+		}, nil
+	case templates.Reminder:
+		if req.Participant == nil {
+			return nil, errors.New("'participant' is required for the reminder template")
+		}
+		return notify.NewReminderData(*req.Participant, url), nil
+	case templates.OptInConfirmation:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown template: %q", req.Template)
+	}
+}
@@ -0,0 +1,88 @@
+package signup
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlePreview(t *testing.T) {
+	server := &signupServer{logger: slog.Default()}
+
+	t.Run("renders a template against a sample signup", func(t *testing.T) {
+		body := `{
+			"template": "signup-confirmation",
+			"signup": {"nameFirst": "Henri", "startDateTime": "2022-10-31T17:00:00Z"}
+		}`
+		req := httptest.NewRequest(http.MethodPost, "/admin/templates/preview", strings.NewReader(body))
+		res := httptest.NewRecorder()
+
+		server.HandlePreview(res, req)
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+
+		var got previewResponse
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+		require.Contains(t, got.Body, "Mon Oct 31 @ 12:00p CDT")
+		require.Contains(t, got.Body, previewSampleURL)
+		require.Equal(t, "GSM-7", got.Encoding)
+		require.Equal(t, 1, got.Segments)
+		require.True(t, got.WithinBudget)
+	})
+
+	t.Run("renders a template against a sample participant", func(t *testing.T) {
+		body := `{
+			"template": "reminder",
+			"language": "es",
+			"url": "https://oprk.org/abc",
+			"participant": {"nameFirst": "Henri", "sessionDate": "2022-10-31T17:00:00Z"}
+		}`
+		req := httptest.NewRequest(http.MethodPost, "/admin/templates/preview", strings.NewReader(body))
+		res := httptest.NewRecorder()
+
+		server.HandlePreview(res, req)
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+
+		var got previewResponse
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+		require.Contains(t, got.Body, "lunes 31/10")
+		require.Contains(t, got.Body, "https://oprk.org/abc")
+		require.Equal(t, "UCS-2", got.Encoding)
+	})
+
+	t.Run("requires sample data for the template", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/admin/templates/preview", strings.NewReader(`{"template": "reminder"}`))
+		res := httptest.NewRecorder()
+
+		server.HandlePreview(res, req)
+		require.Equal(t, http.StatusBadRequest, res.Code)
+	})
+
+	t.Run("rejects unknown templates", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/admin/templates/preview", strings.NewReader(`{"template": "nope"}`))
+		res := httptest.NewRecorder()
+
+		server.HandlePreview(res, req)
+		require.Equal(t, http.StatusBadRequest, res.Code)
+	})
+
+	t.Run("is served on the admin API with the API key", func(t *testing.T) {
+		admin := NewAdminServer(adminServerOptions{apiKey: "admin-key", store: &mockAdminStore{}, preview: server.HandlePreview, logger: slog.Default()})
+		body := `{"template": "signup-confirmation", "signup": {"nameFirst": "Henri"}}`
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/templates/preview", strings.NewReader(body))
+		res := httptest.NewRecorder()
+		admin.ServeHTTP(res, req)
+		require.Equal(t, http.StatusUnauthorized, res.Code)
+
+		req = httptest.NewRequest(http.MethodPost, "/admin/templates/preview", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer admin-key")
+		res = httptest.NewRecorder()
+		admin.ServeHTTP(res, req)
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+	})
+}
@@ -0,0 +1,153 @@
+package signup
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/operationspark/service-signup/notify"
+	"github.com/operationspark/service-signup/templates"
+)
+
+type (
+	// program holds the settings used to confirm and remind signups for one Greenlight program.
+	program struct {
+		id string
+		// Mailgun welcome email template. Defaults to "info-session-signup".
+		welcomeTemplate string
+		// Message Template Renderer templates for the pages linked from the confirmation and reminder SMS messages. Both default to InfoSessionTemplate.
+		rendererTemplate         osRendererTemplate
+		reminderRendererTemplate osRendererTemplate
+		// Key-value map with the Central Time meeting start hour (int) as the keys, and Zoom Meeting ID as the values.
+		// Ex: {17: "86935241734"} denotes meeting with ID, "86935241734", starts at 5pm central.
+		// Signups are not registered for Zoom when empty.
+		meetings map[int]string
+		// SMS templates. Default to templates.SignupConfirmation and templates.Reminder.
+		confirmationTemplate templates.Name
+		reminderTemplate     templates.Name
+		// Reminder periods. Empty sends every scheduled reminder.
+		reminders []notify.Period
+	}
+
+	// programs maps Greenlight program IDs to their settings.
+	programs map[string]program
+)
+
+const defaultWelcomeTemplate = "info-session-signup"
+
+// InfoSessionPrograms returns the programs with only the Info Session program, using the given Zoom meetings.
+func infoSessionPrograms(meetings map[int]string) programs {
+	return programs{
+		notify.InfoSessionProgramID: {id: notify.InfoSessionProgramID, meetings: meetings},
+	}
+}
+
+// Get returns the settings of the program with the given ID. Signups without a program ID are Info Session signups. Unknown programs get the default templates and no Zoom meetings, so their signups aren't registered for another program's meetings. Empty templates are set to the Info Session defaults.
+func (ps programs) get(id string) program {
+	if id == "" {
+		id = notify.InfoSessionProgramID
+	}
+	p, ok := ps[id]
+	if !ok {
+		p = program{id: id}
+	}
+	if p.welcomeTemplate == "" {
+		p.welcomeTemplate = defaultWelcomeTemplate
+	}
+	if p.rendererTemplate == "" {
+		p.rendererTemplate = InfoSessionTemplate
+	}
+	if p.reminderRendererTemplate == "" {
+		p.reminderRendererTemplate = InfoSessionTemplate
+	}
+	if p.confirmationTemplate == "" {
+		p.confirmationTemplate = templates.SignupConfirmation
+	}
+	if p.reminderTemplate == "" {
+		p.reminderTemplate = templates.Reminder
+	}
+	return p
+}
+
+// IDs returns the sorted program IDs.
+func (ps programs) ids() []string {
+	ids := make([]string, 0, len(ps))
+	for id := range ps {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// NotifyPrograms returns the reminder settings of every program, ordered by ID.
+func (ps programs) notifyPrograms() []notify.Program {
+	ids := ps.ids()
+	out := make([]notify.Program, len(ids))
+	for i, id := range ids {
+		p := ps.get(id)
+		out[i] = notify.Program{ID: p.id, ReminderTemplate: p.reminderTemplate, Reminders: p.reminders}
+	}
+	return out
+}
+
+// Programs returns the configured programs. The Info Session program uses the ZOOM_MEETING_12 and ZOOM_MEETING_17 meetings unless the config file sets its meetings. Programs are assumed to be valid (see Validate).
+func (c Config) programs() programs {
+	ps := infoSessionPrograms(map[int]string{
+		12: c.Zoom.Meeting12,
+		17: c.Zoom.Meeting17,
+	})
+	for _, pc := range c.Programs {
+		p := ps[pc.ID]
+		p.id = pc.ID
+		if pc.WelcomeTemplate != "" {
+			p.welcomeTemplate = pc.WelcomeTemplate
+		}
+		if pc.RendererTemplate != "" {
+			p.rendererTemplate = osRendererTemplate(pc.RendererTemplate)
+		}
+		if pc.ReminderRendererTemplate != "" {
+			p.reminderRendererTemplate = osRendererTemplate(pc.ReminderRendererTemplate)
+		}
+		if pc.ConfirmationSMSTemplate != "" {
+			p.confirmationTemplate = templates.Name(pc.ConfirmationSMSTemplate)
+		}
+		if pc.ReminderSMSTemplate != "" {
+			p.reminderTemplate = templates.Name(pc.ReminderSMSTemplate)
+		}
+		if len(pc.ZoomMeetings) > 0 {
+			p.meetings, _ = parseZoomMeetings(pc.ZoomMeetings)
+		}
+		p.reminders = nil
+		for _, r := range pc.Reminders {
+			p.reminders = append(p.reminders, notify.Period(r))
+		}
+		ps[pc.ID] = p
+	}
+	return ps
+}
+
+// ParseZoomMeetings parses "hour=meetingID" pairs into a map of Central Time start hours to Zoom meeting IDs.
+func parseZoomMeetings(pairs []string) (map[int]string, error) {
+	meetings := map[int]string{}
+	var errs []error
+	for _, pair := range pairs {
+		rawHour, id, ok := strings.Cut(pair, "=")
+		if !ok {
+			errs = append(errs, fmt.Errorf("%q: want hour=meetingID", pair))
+			continue
+		}
+		hour, err := strconv.Atoi(rawHour)
+		if err != nil || hour < 0 || hour > 23 {
+			errs = append(errs, fmt.Errorf("%q: invalid hour", pair))
+			continue
+		}
+		if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+			errs = append(errs, fmt.Errorf("%q: invalid meeting ID", pair))
+			continue
+		}
+		meetings[hour] = id
+	}
+	return meetings, errors.Join(errs...)
+}
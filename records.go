@@ -0,0 +1,293 @@
+package signup
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/operationspark/service-signup/greenlight"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type (
+	signupStatus string
+
+	// signupRecord is a signup saved so staff can act on it later. Ex: resend the confirmation from Slack.
+	signupRecord struct {
+		ID                string                 `bson:"_id"`
+		Status            signupStatus           `bson:"status"`
+		AttendingLocation string                 `bson:"attendingLocation"`
+		Cell              string                 `bson:"cell"`
+		Cohort            string                 `bson:"cohort"`
+		Email             string                 `bson:"email"`
+		GooglePlace       greenlight.GooglePlace `bson:"googlePlace"`
+		Language          string                 `bson:"language"`
+		LocationType      string                 `bson:"locationType"`
+		JoinCode          string                 `bson:"joinCode"`
+		NameFirst         string                 `bson:"nameFirst"`
+		NameLast          string                 `bson:"nameLast"`
+		ProgramID         string                 `bson:"programId"`
+		Referrer          string                 `bson:"referrer"`
+		ReferrerResponse  string                 `bson:"referrerResponse"`
+		SessionID         string                 `bson:"sessionId"`
+		SMSOptIn          bool                   `bson:"smsOptIn"`
+		StartDateTime     time.Time              `bson:"startDateTime"`
+		UserLocation      string                 `bson:"userLocation"`
+		ShortLink         string                 `bson:"shortLink"`
+		UTMSource         string                 `bson:"utmSource"`
+		UTMMedium         string                 `bson:"utmMedium"`
+		UTMCampaign       string                 `bson:"utmCampaign"`
+		UTMTerm           string                 `bson:"utmTerm"`
+		UTMContent        string                 `bson:"utmContent"`
+		LandingPage       string                 `bson:"landingPage"`
+		// Greenlight signup ID.
+		GreenlightID   string    `bson:"greenlightId"`
+		ConversationID string    `bson:"conversationId"`
+		UserJoinCode   string    `bson:"userJoinCode"`
+		ZoomMeetingID  int64     `bson:"zoomMeetingId"`
+		ZoomJoinURL    string    `bson:"zoomJoinUrl"`
+		CreatedAt      time.Time `bson:"createdAt"`
+		UpdatedAt      time.Time `bson:"updatedAt"`
+		// Set when the welcome email hard bounced.
+		EmailInvalid bool `bson:"emailInvalid,omitempty"`
+		// Why the signup guard held the signup. Ex: "disposable email domain".
+		QuarantineReason string `bson:"quarantineReason,omitempty"`
+		// Result of each signup task.
+		Tasks []taskOutcome `bson:"tasks,omitempty"`
+	}
+
+	// signupStore saves signup records. Implemented by mongodb.MongodbService.
+	signupStore interface {
+		SaveSignup(ctx context.Context, id string, record any) error
+		// GetSignup decodes the record into dst.
+		GetSignup(ctx context.Context, id string, dst any) error
+		SetSignupStatus(ctx context.Context, id string, status string) error
+		// SwapSignupStatus sets the status only if it is currently from. It returns mongo.ErrNoDocuments otherwise.
+		SwapSignupStatus(ctx context.Context, id string, from, to string) error
+	}
+)
+
+const (
+	signupStatusActive   signupStatus = "active"
+	signupStatusSpam     signupStatus = "spam"
+	signupStatusCanceled signupStatus = "canceled"
+	// Held for review by the signup guard. No signup tasks have run.
+	signupStatusQuarantined signupStatus = "quarantined"
+	// A required signup task failed. The tasks' outcomes show how far the registration got.
+	signupStatusFailed signupStatus = "failed"
+	// Quarantined, then registered by staff. The registration has its own record.
+	signupStatusReleased signupStatus = "released"
+)
+
+var errNotQuarantined = errors.New("signup is not quarantined")
+
+// NewRecordID creates a random ID for a signup record.
+func newRecordID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("rand: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func newSignupRecord(su Signup) signupRecord {
+	r := signupRecord{
+		ID:                su.recordID,
+		Status:            signupStatusActive,
+		AttendingLocation: su.AttendingLocation,
+		Cell:              su.Cell,
+		Cohort:            su.Cohort,
+		Email:             su.Email,
+		GooglePlace:       su.GooglePlace,
+		Language:          su.Language,
+		LocationType:      su.LocationType,
+		JoinCode:          su.JoinCode,
+		NameFirst:         su.NameFirst,
+		NameLast:          su.NameLast,
+		ProgramID:         su.ProgramID,
+		Referrer:          su.Referrer,
+		ReferrerResponse:  su.ReferrerResponse,
+		SessionID:         su.SessionID,
+		SMSOptIn:          su.SMSOptIn,
+		StartDateTime:     su.StartDateTime,
+		UserLocation:      su.UserLocation,
+		ShortLink:         su.ShortLink,
+		UTMSource:         su.UTMSource,
+		UTMMedium:         su.UTMMedium,
+		UTMCampaign:       su.UTMCampaign,
+		UTMTerm:           su.UTMTerm,
+		UTMContent:        su.UTMContent,
+		LandingPage:       su.LandingPage,
+		UserJoinCode:      su.userJoinCode,
+		ZoomMeetingID:     su.zoomMeetingID,
+		ZoomJoinURL:       su.zoomMeetingURL,
+		Tasks:             su.taskOutcomes,
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+	}
+	if su.id != nil {
+		r.GreenlightID = *su.id
+	}
+	if su.conversationID != nil {
+		r.ConversationID = *su.conversationID
+	}
+	return r
+}
+
+// Signup restores the Signup from the record.
+func (r signupRecord) signup() Signup {
+	su := Signup{
+		AttendingLocation: r.AttendingLocation,
+		Cell:              r.Cell,
+		Cohort:            r.Cohort,
+		Email:             r.Email,
+		GooglePlace:       r.GooglePlace,
+		Language:          r.Language,
+		LocationType:      r.LocationType,
+		JoinCode:          r.JoinCode,
+		NameFirst:         r.NameFirst,
+		NameLast:          r.NameLast,
+		ProgramID:         r.ProgramID,
+		Referrer:          r.Referrer,
+		ReferrerResponse:  r.ReferrerResponse,
+		SessionID:         r.SessionID,
+		SMSOptIn:          r.SMSOptIn,
+		StartDateTime:     r.StartDateTime,
+		UserLocation:      r.UserLocation,
+		ShortLink:         r.ShortLink,
+		UTMSource:         r.UTMSource,
+		UTMMedium:         r.UTMMedium,
+		UTMCampaign:       r.UTMCampaign,
+		UTMTerm:           r.UTMTerm,
+		UTMContent:        r.UTMContent,
+		LandingPage:       r.LandingPage,
+		recordID:          r.ID,
+		userJoinCode:      r.UserJoinCode,
+		zoomMeetingID:     r.ZoomMeetingID,
+		zoomMeetingURL:    r.ZoomJoinURL,
+	}
+	if r.GreenlightID != "" {
+		su.id = &r.GreenlightID
+	}
+	if r.ConversationID != "" {
+		su.conversationID = &r.ConversationID
+	}
+	return su
+}
+
+// SaveRecord saves the signup with the given status. Failures are logged so they don't change the result of the signup.
+func (s *SignupService) saveRecord(ctx context.Context, su Signup, status signupStatus, logger *slog.Logger) {
+	if s.store == nil || su.recordID == "" {
+		return
+	}
+	r := newSignupRecord(su)
+	r.Status = status
+	if err := s.store.SaveSignup(ctx, su.recordID, r); err != nil {
+		logger.ErrorContext(ctx, fmt.Errorf("saveSignup: %w", err).Error())
+	}
+}
+
+func (s *SignupService) getRecord(ctx context.Context, id string) (signupRecord, error) {
+	if s.store == nil {
+		return signupRecord{}, errors.New("signup store is not configured")
+	}
+	var r signupRecord
+	if err := s.store.GetSignup(ctx, id, &r); err != nil {
+		return signupRecord{}, fmt.Errorf("getSignup: %w", err)
+	}
+	return r, nil
+}
+
+// ResendConfirmation sends the welcome email and SMS confirmation again.
+func (s *SignupService) resendConfirmation(ctx context.Context, id string, logger *slog.Logger) (Signup, error) {
+	r, err := s.getRecord(ctx, id)
+	if err != nil {
+		return Signup{}, err
+	}
+	if r.Status != signupStatusActive {
+		return Signup{}, fmt.Errorf("signup is %s", r.Status)
+	}
+
+	su := r.signup()
+	for _, t := range s.confirmationTasks {
+		if err := t.run(ctx, &su, logger); err != nil {
+			return su, fmt.Errorf("%s: %w", t.name(), err)
+		}
+	}
+	return su, nil
+}
+
+// MarkSpam flags the signup as spam.
+func (s *SignupService) markSpam(ctx context.Context, id string) (Signup, error) {
+	return s.setStatus(ctx, id, signupStatusSpam)
+}
+
+// Cancel cancels the signup.
+func (s *SignupService) cancel(ctx context.Context, id string) (Signup, error) {
+	return s.setStatus(ctx, id, signupStatusCanceled)
+}
+
+// Release registers a quarantined signup, running the signup tasks the guard skipped. The quarantined record is kept with the "released" status.
+func (s *SignupService) release(ctx context.Context, id string, logger *slog.Logger) (Signup, error) {
+	// Claim the record first so two concurrent releases (Ex: a double-click) don't register the person twice.
+	r, err := s.claimQuarantined(ctx, id, signupStatusReleased)
+	if err != nil {
+		return Signup{}, err
+	}
+	su, err := s.register(ctx, r.signup(), logger)
+	if err != nil {
+		if err := s.store.SwapSignupStatus(ctx, id, string(signupStatusReleased), string(signupStatusQuarantined)); err != nil {
+			logger.ErrorContext(ctx, fmt.Errorf("swapSignupStatus: %w", err).Error())
+		}
+		return su, fmt.Errorf("register: %w", err)
+	}
+	return su, nil
+}
+
+// Reject marks a quarantined signup as spam.
+func (s *SignupService) reject(ctx context.Context, id string) (Signup, error) {
+	r, err := s.claimQuarantined(ctx, id, signupStatusSpam)
+	if err != nil {
+		return Signup{}, err
+	}
+	return r.signup(), nil
+}
+
+// ClaimQuarantined moves a quarantined record to the status and returns it. Only one caller can claim a record; the others get errNotQuarantined.
+func (s *SignupService) claimQuarantined(ctx context.Context, id string, status signupStatus) (signupRecord, error) {
+	if s.store == nil {
+		return signupRecord{}, errors.New("signup store is not configured")
+	}
+	err := s.store.SwapSignupStatus(ctx, id, string(signupStatusQuarantined), string(status))
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// The record is missing or was already reviewed. Report which.
+		r, err := s.getRecord(ctx, id)
+		if err != nil {
+			return signupRecord{}, err
+		}
+		return signupRecord{}, fmt.Errorf("%w: signup is %s", errNotQuarantined, r.Status)
+	}
+	if err != nil {
+		return signupRecord{}, fmt.Errorf("swapSignupStatus: %w", err)
+	}
+	r, err := s.getRecord(ctx, id)
+	if err != nil {
+		return signupRecord{}, errors.Join(err, s.store.SwapSignupStatus(ctx, id, string(status), string(signupStatusQuarantined)))
+	}
+	return r, nil
+}
+
+func (s *SignupService) setStatus(ctx context.Context, id string, status signupStatus) (Signup, error) {
+	r, err := s.getRecord(ctx, id)
+	if err != nil {
+		return Signup{}, err
+	}
+	if err := s.store.SetSignupStatus(ctx, id, string(status)); err != nil {
+		return Signup{}, fmt.Errorf("setSignupStatus: %w", err)
+	}
+	return r.signup(), nil
+}
@@ -0,0 +1,177 @@
+package signup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mockSignupStore struct {
+	mu      sync.Mutex
+	records map[string]signupRecord
+}
+
+func (m *mockSignupStore) SaveSignup(ctx context.Context, id string, record any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records[id] = record.(signupRecord)
+	return nil
+}
+
+func (m *mockSignupStore) GetSignup(ctx context.Context, id string, dst any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.records[id]
+	if !ok {
+		return errors.New("not found")
+	}
+	*(dst.(*signupRecord)) = r
+	return nil
+}
+
+func (m *mockSignupStore) SetSignupStatus(ctx context.Context, id string, status string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r := m.records[id]
+	r.Status = signupStatus(status)
+	m.records[id] = r
+	return nil
+}
+
+func (m *mockSignupStore) SwapSignupStatus(ctx context.Context, id string, from, to string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.records[id]
+	if !ok || string(r.Status) != from {
+		return fmt.Errorf("signup %q with status %q: %w", id, from, mongo.ErrNoDocuments)
+	}
+	r.Status = signupStatus(to)
+	m.records[id] = r
+	return nil
+}
+
+func TestSignupRecords(t *testing.T) {
+	signup := Signup{
+		NameFirst:     "Henri",
+		NameLast:      "Testaroni",
+		Email:         "henri@email.com",
+		Cell:          "555-123-4567",
+		StartDateTime: mustMakeTime(t, time.RFC822, "16 Nov 22 18:00 UTC"), // 12 central
+	}
+
+	newService := func() (*SignupService, *mockSignupStore, *MockMailgunService) {
+		store := &mockSignupStore{records: map[string]signupRecord{}}
+		mailService := &MockMailgunService{
+			WelcomeFunc: func(ctx context.Context, su Signup) error { return nil },
+		}
+		svc, err := newSignupService(signupServiceOptions{
+			tasks:             []mutationTask{mailService},
+			confirmationTasks: []mutationTask{mailService},
+			zoomService:       &MockZoomService{},
+			programs:          infoSessionPrograms(map[int]string{12: "983782"}),
+			gldbService:       &MockGreenlightDBService{},
+			store:             store,
+		})
+		require.NoError(t, err)
+		return svc, store, mailService
+	}
+
+	t.Run("saves completed signups", func(t *testing.T) {
+		svc, store, _ := newService()
+
+		su, err := svc.register(context.Background(), signup, slog.Default())
+		require.NoError(t, err)
+		require.NotEmpty(t, su.recordID)
+
+		r, ok := store.records[su.recordID]
+		require.True(t, ok)
+		require.Equal(t, signupStatusActive, r.Status)
+		require.Equal(t, "henri@email.com", r.Email)
+		require.Equal(t, su.ZoomMeetingID(), r.ZoomMeetingID)
+	})
+
+	t.Run("saves failed signups with their task outcomes", func(t *testing.T) {
+		svc, store, mailService := newService()
+		mailService.WelcomeFunc = func(ctx context.Context, su Signup) error {
+			return errors.New("mailgun is down")
+		}
+
+		su, err := svc.register(context.Background(), signup, slog.Default())
+		require.Error(t, err)
+
+		r, ok := store.records[su.recordID]
+		require.True(t, ok)
+		require.Equal(t, signupStatusFailed, r.Status)
+		var failed []string
+		for _, o := range r.Tasks {
+			if o.Status == taskFailed {
+				failed = append(failed, o.Error)
+			}
+		}
+		require.Equal(t, []string{"mailgun is down"}, failed)
+	})
+
+	t.Run("resends the confirmation", func(t *testing.T) {
+		svc, _, mailService := newService()
+
+		su, err := svc.register(context.Background(), signup, slog.Default())
+		require.NoError(t, err)
+		mailService.called = false
+
+		resent, err := svc.resendConfirmation(context.Background(), su.recordID, slog.Default())
+		require.NoError(t, err)
+		require.True(t, mailService.called)
+		require.Equal(t, "Henri", resent.NameFirst)
+	})
+
+	t.Run("does not resend to canceled signups", func(t *testing.T) {
+		svc, store, _ := newService()
+
+		su, err := svc.register(context.Background(), signup, slog.Default())
+		require.NoError(t, err)
+
+		_, err = svc.cancel(context.Background(), su.recordID)
+		require.NoError(t, err)
+		require.Equal(t, signupStatusCanceled, store.records[su.recordID].Status)
+
+		_, err = svc.resendConfirmation(context.Background(), su.recordID, slog.Default())
+		require.ErrorContains(t, err, "canceled")
+	})
+
+	t.Run("registers a quarantined signup once when it is released twice at the same time", func(t *testing.T) {
+		svc, store, mailService := newService()
+		store.records["held"] = signupRecord{ID: "held", Status: signupStatusQuarantined, NameFirst: "Halle", Email: "halle@email.com"}
+		var registered atomic.Int32
+		mailService.WelcomeFunc = func(ctx context.Context, su Signup) error {
+			registered.Add(1)
+			return nil
+		}
+
+		var wg sync.WaitGroup
+		errs := make([]error, 2)
+		for i := range errs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, errs[i] = svc.release(context.Background(), "held", slog.Default())
+			}()
+		}
+		wg.Wait()
+
+		require.Equal(t, int32(1), registered.Load())
+		require.Equal(t, signupStatusReleased, store.records["held"].Status)
+		if errs[0] == nil {
+			errs[0], errs[1] = errs[1], errs[0]
+		}
+		require.ErrorIs(t, errs[0], errNotQuarantined, "the second release must fail")
+		require.NoError(t, errs[1])
+	})
+}
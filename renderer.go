@@ -0,0 +1,262 @@
+package signup
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/operationspark/service-signup/i18n"
+	"github.com/operationspark/service-signup/notify"
+)
+
+type (
+	Location struct {
+		Name         string `json:"name"`
+		Line1        string `json:"line1"`
+		CityStateZip string `json:"cityStateZip"`
+		MapURL       string `json:"mapUrl"`
+	}
+
+	// rendererParams are the details shown on one Operation Spark Message Template Renderer page. They are encoded into the page URL.
+	rendererParams interface {
+		// TemplateName returns the renderer template that shows the params.
+		templateName() osRendererTemplate
+		// Validate returns an error if the page would be missing details.
+		validate() error
+	}
+
+	// Request params for the Info Session details page, linked from the signup confirmation SMS.
+	infoSessionParams struct {
+		Template      osRendererTemplate `json:"template"`
+		ZoomLink      string             `json:"zoomLink"`
+		Date          time.Time          `json:"date"`
+		Name          string             `json:"name"`
+		LocationType  string             `json:"locationType"`
+		Location      Location           `json:"location"`
+		JoinCode      string             `json:"joinCode,omitempty"`
+		IsGmail       bool               `json:"isGmail"`
+		GreenlightURL string             `json:"greenlightUrl"`
+		Language      i18n.Language      `json:"language,omitempty"`
+	}
+
+	// Request params for the upcoming session page, linked from reminder SMS messages.
+	reminderParams struct {
+		Template     osRendererTemplate `json:"template"`
+		ZoomLink     string             `json:"zoomLink"`
+		Date         time.Time          `json:"date"`
+		Name         string             `json:"name"`
+		LocationType string             `json:"locationType"`
+		Location     Location           `json:"location"`
+		Language     i18n.Language      `json:"language,omitempty"`
+	}
+
+	// Request params for the page telling someone their session moved.
+	rescheduleParams struct {
+		Template     osRendererTemplate `json:"template"`
+		Name         string             `json:"name"`
+		PreviousDate time.Time          `json:"previousDate"`
+		Date         time.Time          `json:"date"`
+		ZoomLink     string             `json:"zoomLink"`
+		LocationType string             `json:"locationType"`
+		Location     Location           `json:"location"`
+		Language     i18n.Language      `json:"language,omitempty"`
+	}
+
+	// Request params for the page telling someone they are on the waitlist for a full session.
+	waitlistParams struct {
+		Template osRendererTemplate `json:"template"`
+		Name     string             `json:"name"`
+		Date     time.Time          `json:"date"`
+		// Place on the waitlist, starting at 1. 0 if unknown.
+		Position int `json:"position,omitempty"`
+		// Link to pick another session.
+		SignupURL string        `json:"signupUrl"`
+		Language  i18n.Language `json:"language,omitempty"`
+	}
+
+	// Request params for the page telling someone their session was canceled.
+	cancellationParams struct {
+		Template osRendererTemplate `json:"template"`
+		Name     string             `json:"name"`
+		Date     time.Time          `json:"date"`
+		// Link to sign up for another session.
+		SignupURL string        `json:"signupUrl"`
+		Language  i18n.Language `json:"language,omitempty"`
+	}
+
+	osRenderer struct {
+		// OpSpark Message Template Renderer Service base URL.
+		// Defaults to https://sms.operationspark.org
+		baseURL string
+		// Renderer templates by Greenlight program ID.
+		programs programs
+	}
+
+	osRendererTemplate string
+)
+
+const (
+	// Info Session details page. Params: infoSessionParams.
+	InfoSessionTemplate osRendererTemplate = "InfoSession"
+	// Upcoming session page. Params: reminderParams.
+	ReminderTemplate osRendererTemplate = "Reminder"
+	// Session moved page. Params: rescheduleParams.
+	RescheduleTemplate osRendererTemplate = "Reschedule"
+	// Session full page. Params: waitlistParams.
+	WaitlistTemplate osRendererTemplate = "Waitlist"
+	// Session canceled page. Params: cancellationParams.
+	CancellationTemplate osRendererTemplate = "Cancellation"
+)
+
+// RendererTemplates maps the built-in renderer templates to a constructor for their params. Templates not listed here (Ex: a program's own confirmation page) take the params of the page they replace.
+var rendererTemplates = map[osRendererTemplate]func() rendererParams{
+	InfoSessionTemplate:  func() rendererParams { return &infoSessionParams{} },
+	ReminderTemplate:     func() rendererParams { return &reminderParams{} },
+	RescheduleTemplate:   func() rendererParams { return &rescheduleParams{} },
+	WaitlistTemplate:     func() rendererParams { return &waitlistParams{} },
+	CancellationTemplate: func() rendererParams { return &cancellationParams{} },
+}
+
+func (p *infoSessionParams) templateName() osRendererTemplate  { return p.Template }
+func (p *reminderParams) templateName() osRendererTemplate     { return p.Template }
+func (p *rescheduleParams) templateName() osRendererTemplate   { return p.Template }
+func (p *waitlistParams) templateName() osRendererTemplate     { return p.Template }
+func (p *cancellationParams) templateName() osRendererTemplate { return p.Template }
+
+// Validate requires a name. The date and location can be empty because a failed confirmation link fails the signup.
+func (p *infoSessionParams) validate() error {
+	return required("name", p.Name != "")
+}
+
+func (p *reminderParams) validate() error {
+	return errors.Join(
+		required("name", p.Name != ""),
+		required("date", !p.Date.IsZero()),
+		p.Location.validate(p.LocationType),
+	)
+}
+
+func (p *rescheduleParams) validate() error {
+	err := errors.Join(
+		required("name", p.Name != ""),
+		required("previousDate", !p.PreviousDate.IsZero()),
+		required("date", !p.Date.IsZero()),
+		p.Location.validate(p.LocationType),
+	)
+	if err == nil && p.Date.Equal(p.PreviousDate) {
+		return errors.New("date must differ from previousDate")
+	}
+	return err
+}
+
+func (p *waitlistParams) validate() error {
+	return errors.Join(
+		required("name", p.Name != ""),
+		required("date", !p.Date.IsZero()),
+		required("signupUrl", p.SignupURL != ""),
+	)
+}
+
+func (p *cancellationParams) validate() error {
+	return errors.Join(
+		required("name", p.Name != ""),
+		required("date", !p.Date.IsZero()),
+		required("signupUrl", p.SignupURL != ""),
+	)
+}
+
+// Validate requires an address for in-person sessions.
+func (l Location) validate(locationType string) error {
+	return required("location", locationType != "IN_PERSON" || l.Line1 != "")
+}
+
+// Required returns an error naming the field if it is not set.
+func required(field string, ok bool) error {
+	if ok {
+		return nil
+	}
+	return fmt.Errorf("%s is required", field)
+}
+
+// RendererURL validates the params and encodes them into a renderer page URL.
+func rendererURL(baseURL string, p rendererParams) (string, error) {
+	if p.templateName() == "" {
+		return "", errors.New("template is required")
+	}
+	if err := p.validate(); err != nil {
+		return "", fmt.Errorf("invalid %s params: %w", p.templateName(), err)
+	}
+	encoded, err := encodeRendererParams(p)
+	if err != nil {
+		return "", fmt.Errorf("encodeRendererParams: %w", err)
+	}
+	return fmt.Sprintf("%s/m/%s", baseURL, encoded), nil
+}
+
+// EncodeRendererParams marshals the params to JSON then encodes the string to base64.
+func encodeRendererParams(p rendererParams) (string, error) {
+	j, err := json.Marshal(p)
+	if err != nil {
+		return "", fmt.Errorf("marshall: %w", err)
+	}
+
+	return base64.URLEncoding.EncodeToString(j), nil
+}
+
+// DecodeRendererParams decodes base64 encoded params into the params of their template. Templates that aren't built in decode as infoSessionParams.
+func decodeRendererParams(encoded string) (rendererParams, error) {
+	jsonBytes, err := base64.URLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	var head struct {
+		Template osRendererTemplate `json:"template"`
+	}
+	if err := json.Unmarshal(jsonBytes, &head); err != nil {
+		return nil, err
+	}
+
+	var p rendererParams = &infoSessionParams{}
+	if newParams, ok := rendererTemplates[head.Template]; ok {
+		p = newParams()
+	}
+	if err := json.Unmarshal(jsonBytes, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Ping returns an error if the renderer service cannot be reached.
+func (osm *osRenderer) ping(ctx context.Context) error {
+	return checkReachable(ctx, http.DefaultClient, osm.baseURL)
+}
+
+// CreateMessageURL creates a URL to the upcoming session page on Operation Spark's SMS Messaging Preview service, rendered with the participant's program reminder template. The Info Session page (the default) takes the confirmation page's params.
+func (osm *osRenderer) CreateMessageURL(p notify.Participant) (string, error) {
+	tmpl := osm.programs.get(p.ProgramID).reminderRendererTemplate
+	if tmpl == InfoSessionTemplate {
+		return rendererURL(osm.baseURL, &infoSessionParams{
+			Template:     tmpl,
+			ZoomLink:     p.ZoomJoinURL,
+			Name:         p.NameFirst,
+			Date:         p.SessionDate,
+			LocationType: p.SessionLocationType,
+			Location:     Location(p.SessionLocation),
+			Language:     i18n.Parse(p.Language),
+		})
+	}
+	return rendererURL(osm.baseURL, &reminderParams{
+		Template:     tmpl,
+		ZoomLink:     p.ZoomJoinURL,
+		Name:         p.NameFirst,
+		Date:         p.SessionDate,
+		LocationType: p.SessionLocationType,
+		Location:     Location(p.SessionLocation),
+		Language:     i18n.Parse(p.Language),
+	})
+}
@@ -0,0 +1,54 @@
+package signup
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRendererURL(t *testing.T) {
+	date := mustMakeTime(t, time.RFC3339, "2022-10-05T17:00:00.000Z")
+
+	t.Run("encodes each template's params and decodes them by template", func(t *testing.T) {
+		for _, params := range []rendererParams{
+			&infoSessionParams{Template: InfoSessionTemplate, Name: "Halle"},
+			&reminderParams{Template: ReminderTemplate, Name: "Halle", Date: date, LocationType: "VIRTUAL"},
+			&rescheduleParams{Template: RescheduleTemplate, Name: "Halle", PreviousDate: date, Date: date.AddDate(0, 0, 7)},
+			&waitlistParams{Template: WaitlistTemplate, Name: "Halle", Date: date, Position: 2, SignupURL: "https://operationspark.org/info-session"},
+			&cancellationParams{Template: CancellationTemplate, Name: "Halle", Date: date, SignupURL: "https://operationspark.org/info-session"},
+		} {
+			u, err := rendererURL("https://sms.operationspark.org", params)
+			require.NoError(t, err, params.templateName())
+
+			got, err := decodeRendererParams(strings.TrimPrefix(u, "https://sms.operationspark.org/m/"))
+			require.NoError(t, err)
+			require.Equal(t, params, got)
+		}
+	})
+
+	t.Run("decodes unknown templates as Info Session params", func(t *testing.T) {
+		encoded, err := encodeRendererParams(&infoSessionParams{Template: "Workshop", Name: "Halle"})
+		require.NoError(t, err)
+
+		got, err := decodeRendererParams(encoded)
+		require.NoError(t, err)
+		require.Equal(t, &infoSessionParams{Template: "Workshop", Name: "Halle"}, got)
+	})
+
+	t.Run("rejects params missing details", func(t *testing.T) {
+		for want, params := range map[string]rendererParams{
+			"template is required":                 &infoSessionParams{Name: "Halle"},
+			"name is required":                     &infoSessionParams{Template: InfoSessionTemplate},
+			"location is required":                 &reminderParams{Template: ReminderTemplate, Name: "Halle", Date: date, LocationType: "IN_PERSON"},
+			"previousDate is required":             &rescheduleParams{Template: RescheduleTemplate, Name: "Halle", Date: date},
+			"date must differ from previousDate":   &rescheduleParams{Template: RescheduleTemplate, Name: "Halle", PreviousDate: date, Date: date},
+			"signupUrl is required":                &waitlistParams{Template: WaitlistTemplate, Name: "Halle", Date: date},
+			"invalid Cancellation params: date is": &cancellationParams{Template: CancellationTemplate, Name: "Halle", SignupURL: "https://operationspark.org"},
+		} {
+			_, err := rendererURL("https://sms.operationspark.org", params)
+			require.ErrorContains(t, err, want)
+		}
+	})
+}
@@ -0,0 +1,267 @@
+package signup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/operationspark/service-signup/metrics"
+)
+
+type (
+	// TaskPolicy limits how long a task can run and how it is retried.
+	taskPolicy struct {
+		// Time allowed for each attempt. 0 means no limit.
+		timeout time.Duration
+		// Extra attempts for idempotent tasks.
+		retries int
+		// Delay before the first retry. It doubles for each retry, with jitter.
+		backoff time.Duration
+	}
+
+	// BreakerOptions configure the circuit breaker for each integration.
+	breakerOptions struct {
+		// Consecutive failures that open the circuit. 0 disables circuit breakers.
+		failures int
+		// Time the circuit stays open before a trial request is allowed.
+		cooldown time.Duration
+	}
+
+	circuitState int
+
+	// CircuitBreaker stops calling an integration after repeated failures so signups fail (or skip the task) fast instead of waiting on a vendor that is down.
+	circuitBreaker struct {
+		integration string
+		opts        breakerOptions
+		now         func() time.Time
+
+		mu       sync.Mutex
+		state    circuitState
+		failures int
+		openedAt time.Time
+		// Set while the half-open trial request is in flight.
+		trial bool
+	}
+)
+
+const (
+	circuitClosed circuitState = iota
+	circuitHalfOpen
+	circuitOpen
+)
+
+// ErrCircuitOpen is returned for tasks that are not run because their integration's circuit is open.
+var errCircuitOpen = errors.New("circuit open")
+
+// AbandonGrace is how long a task has to return after its timeout before the pipeline stops waiting for it.
+var abandonGrace = 250 * time.Millisecond
+
+func (s circuitState) String() string {
+	switch s {
+	case circuitHalfOpen:
+		return "half-open"
+	case circuitOpen:
+		return "open"
+	default:
+		return "closed"
+	}
+}
+
+func newCircuitBreaker(integration string, o breakerOptions) *circuitBreaker {
+	metrics.CircuitState.WithLabelValues(integration).Set(float64(circuitClosed))
+	return &circuitBreaker{integration: integration, opts: o, now: time.Now}
+}
+
+// Allow reports whether a request can be sent to the integration. Once the cooldown has passed, an open circuit lets one trial request through.
+func (b *circuitBreaker) allow(logger *slog.Logger) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case circuitOpen:
+		if b.now().Sub(b.openedAt) < b.opts.cooldown {
+			return false
+		}
+		b.setState(circuitHalfOpen, logger)
+		b.trial = true
+		return true
+	case circuitHalfOpen:
+		if b.trial {
+			return false
+		}
+		b.trial = true
+		return true
+	default:
+		return true
+	}
+}
+
+// Record updates the circuit with the result of a request.
+func (b *circuitBreaker) record(err error, logger *slog.Logger) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+	if err == nil {
+		b.failures = 0
+		if b.state != circuitClosed {
+			b.setState(circuitClosed, logger)
+		}
+		return
+	}
+
+	b.failures++
+	if b.state == circuitHalfOpen || b.failures >= b.opts.failures {
+		b.openedAt = b.now()
+		if b.state != circuitOpen {
+			b.setState(circuitOpen, logger)
+		}
+	}
+}
+
+// Release ends a request without counting its result, letting another trial request through if the circuit is half-open.
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
+
+func (b *circuitBreaker) setState(s circuitState, logger *slog.Logger) {
+	b.state = s
+	metrics.CircuitState.WithLabelValues(b.integration).Set(float64(s))
+	logger.Warn("circuit state changed",
+		slog.String("integration", b.integration),
+		slog.String("state", s.String()),
+		slog.Int("failures", b.failures))
+}
+
+// RunWithPolicy runs the task with the step's timeout, retries, and circuit breaker.
+// Attempts run on a copy of the signup that is copied back when the attempt returns, so a task that ignores its context can be abandoned without racing the pipeline.
+func runWithPolicy(ctx context.Context, step pipelineStep, su *Signup, logger *slog.Logger) error {
+	attempts := 1
+	if step.spec.idempotent {
+		attempts += step.policy.retries
+	}
+	base := *su
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			metrics.TaskRetries.WithLabelValues(step.task.name()).Inc()
+			logger.InfoContext(ctx, "retrying task",
+				slog.String("task", step.task.name()),
+				slog.Int("attempt", attempt+1),
+				slog.String("error", err.Error()))
+			if sleepErr := sleepContext(ctx, retryDelay(step.policy.backoff, attempt)); sleepErr != nil {
+				return err
+			}
+		}
+
+		if step.breaker != nil && !step.breaker.allow(logger) {
+			metrics.CircuitRejections.WithLabelValues(step.breaker.integration).Inc()
+			return fmt.Errorf("%s: %w", step.breaker.integration, errCircuitOpen)
+		}
+
+		attemptSignup := base
+		err = runAttempt(ctx, step, &attemptSignup, logger)
+		if step.breaker != nil {
+			// Failures caused by the signup request ending say nothing about the integration.
+			// Neither do errors the vendor would return again, like an invalid phone number: the vendor is up.
+			if ctx.Err() != nil {
+				step.breaker.release()
+			} else if isPermanent(err) {
+				step.breaker.record(nil, logger)
+			} else {
+				step.breaker.record(err, logger)
+			}
+		}
+		*su = attemptSignup
+		if err == nil || ctx.Err() != nil || isPermanent(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// IsPermanent reports whether err is an integration error response that would be the same if the request were sent again.
+func isPermanent(err error) bool {
+	var httpErr *HTTPError
+	return errors.As(err, &httpErr) && !httpErr.Retryable()
+}
+
+// RunAttempt runs the task once. If the step has a timeout and the task does not return shortly after it, the attempt is abandoned and su is left unchanged.
+func runAttempt(ctx context.Context, step pipelineStep, su *Signup, logger *slog.Logger) error {
+	if step.policy.timeout <= 0 {
+		return step.task.run(ctx, su, logger)
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, step.policy.timeout)
+	defer cancel()
+
+	taskSignup := *su
+	errc := make(chan error, 1)
+	go func() {
+		errc <- step.task.run(attemptCtx, &taskSignup, logger)
+	}()
+
+	select {
+	case err := <-errc:
+		*su = taskSignup
+		return err
+	case <-attemptCtx.Done():
+	}
+
+	// Give tasks that respect their context a chance to return their own error (or fallback).
+	select {
+	case err := <-errc:
+		*su = taskSignup
+		return err
+	case <-time.After(abandonGrace):
+		return fmt.Errorf("timed out after %s: %w", step.policy.timeout, attemptCtx.Err())
+	}
+}
+
+// RetryDelay returns the exponential backoff for the retry, with jitter so concurrent signups don't retry in lockstep.
+func retryDelay(backoff time.Duration, retry int) time.Duration {
+	if backoff <= 0 {
+		return 0
+	}
+	d := backoff << (retry - 1)
+	// Between half and all of the backoff.
+	return d/2 + rand.N(d/2+1)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ParseTaskTimeouts parses per-task timeouts in the form "key=seconds". Ex: ["zoom=5", "sms=15"].
+func parseTaskTimeouts(pairs []string) (map[string]time.Duration, error) {
+	timeouts := map[string]time.Duration{}
+	var errs []error
+	for _, pair := range pairs {
+		key, raw, ok := strings.Cut(pair, "=")
+		if !ok {
+			errs = append(errs, fmt.Errorf("%q: want key=seconds", pair))
+			continue
+		}
+		secs, err := strconv.ParseFloat(raw, 64)
+		if err != nil || secs < 0 {
+			errs = append(errs, fmt.Errorf("%q: invalid seconds", pair))
+			continue
+		}
+		timeouts[key] = time.Duration(secs * float64(time.Second))
+	}
+	return timeouts, errors.Join(errs...)
+}
@@ -0,0 +1,127 @@
+package signup
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskPolicy(t *testing.T) {
+	t.Run("abandons tasks that outlive their timeout", func(t *testing.T) {
+		abandonGrace = 10 * time.Millisecond
+		defer func() { abandonGrace = 250 * time.Millisecond }()
+
+		release := make(chan struct{})
+		defer close(release)
+		hung := funcTask{
+			taskSpec: taskSpec{key: "greenlight", produces: []signupField{fieldSignupID}},
+			required: true,
+			fn: func(ctx context.Context, su *Signup, logger *slog.Logger) error {
+				// Ignores its context, like a client without a timeout.
+				<-release
+				id := "too late"
+				su.id = &id
+				return nil
+			},
+		}
+		p, err := newPipeline([]mutationTask{hung}, pipelineOptions{policy: taskPolicy{timeout: 20 * time.Millisecond}})
+		require.NoError(t, err)
+
+		su := Signup{}
+		err = p.run(context.Background(), &su, slog.Default())
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Nil(t, su.id)
+	})
+
+	t.Run("retries idempotent tasks", func(t *testing.T) {
+		calls := map[bool]int{}
+		newTask := func(idempotent bool) funcTask {
+			return funcTask{
+				taskSpec: taskSpec{key: "zoom", idempotent: idempotent},
+				required: true,
+				fn: func(ctx context.Context, su *Signup, logger *slog.Logger) error {
+					calls[idempotent]++
+					if calls[idempotent] < 3 {
+						return errors.New("502 Bad Gateway")
+					}
+					return nil
+				},
+			}
+		}
+		o := pipelineOptions{policy: taskPolicy{retries: 2, backoff: time.Millisecond}}
+
+		p, err := newPipeline([]mutationTask{newTask(true)}, o)
+		require.NoError(t, err)
+		require.NoError(t, p.run(context.Background(), &Signup{}, slog.Default()))
+		require.Equal(t, 3, calls[true])
+
+		p, err = newPipeline([]mutationTask{newTask(false)}, o)
+		require.NoError(t, err)
+		require.ErrorContains(t, p.run(context.Background(), &Signup{}, slog.Default()), "502 Bad Gateway")
+		require.Equal(t, 1, calls[false])
+	})
+
+	t.Run("opens the circuit after repeated failures", func(t *testing.T) {
+		vendorUp := false
+		calls := 0
+		newTask := func(key string, required bool) funcTask {
+			return funcTask{
+				taskSpec: taskSpec{key: key, integration: "twilio"},
+				required: required,
+				fn: func(ctx context.Context, su *Signup, logger *slog.Logger) error {
+					calls++
+					if !vendorUp {
+						return errors.New("503 Service Unavailable")
+					}
+					return nil
+				},
+			}
+		}
+		p, err := newPipeline([]mutationTask{newTask("sms", true)}, pipelineOptions{
+			breaker: breakerOptions{failures: 2, cooldown: time.Minute},
+		})
+		require.NoError(t, err)
+		breaker := p.steps[0].breaker
+		now := time.Now()
+		breaker.now = func() time.Time { return now }
+
+		for range 2 {
+			require.ErrorContains(t, p.run(context.Background(), &Signup{}, slog.Default()), "503")
+		}
+		require.Equal(t, circuitOpen, breaker.state)
+
+		// Fails fast without calling the vendor.
+		err = p.run(context.Background(), &Signup{}, slog.Default())
+		require.ErrorIs(t, err, errCircuitOpen)
+		require.Equal(t, 2, calls)
+
+		// Non-required tasks are skipped.
+		p.steps[0].required = false
+		require.NoError(t, p.run(context.Background(), &Signup{}, slog.Default()))
+		require.Equal(t, 2, calls)
+
+		// A trial request closes the circuit after the cooldown.
+		vendorUp = true
+		now = now.Add(time.Minute)
+		require.NoError(t, p.run(context.Background(), &Signup{}, slog.Default()))
+		require.Equal(t, 3, calls)
+		require.Equal(t, circuitClosed, breaker.state)
+	})
+
+	t.Run("parses per-task timeouts", func(t *testing.T) {
+		timeouts, err := parseTaskTimeouts([]string{"zoom=5", "sms=0.5"})
+		require.NoError(t, err)
+		require.Equal(t, map[string]time.Duration{"zoom": 5 * time.Second, "sms": 500 * time.Millisecond}, timeouts)
+
+		_, err = parseTaskTimeouts([]string{"zoom", "sms=soon"})
+		require.ErrorContains(t, err, `"zoom": want key=seconds`)
+		require.ErrorContains(t, err, `"sms=soon": invalid seconds`)
+
+		_, err = newPipeline(nil, pipelineOptions{timeouts: map[string]time.Duration{"fax": time.Second}})
+		require.ErrorContains(t, err, `unknown task "fax"`)
+	})
+}
@@ -0,0 +1,300 @@
+package signup
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/operationspark/service-signup/greenlight"
+	"github.com/operationspark/service-signup/notify"
+)
+
+type (
+	// RosterQuery selects the sessions to export. Either SessionID, or From (and optionally To) as inclusive dates (YYYY-MM-DD) in Central Time.
+	RosterQuery struct {
+		SessionID string
+		From      string
+		To        string
+	}
+
+	// sessionLoader loads sessions with their participants. Implemented by notify.MongoService.
+	sessionLoader interface {
+		GetSessions(ctx context.Context, q notify.SessionQuery) ([]*notify.UpcomingSession, error)
+	}
+
+	// joinCodeStore reads Greenlight user join codes. Implemented by mongodb.MongodbService.
+	joinCodeStore interface {
+		GetUserJoinCode(ctx context.Context, id string, dst any) error
+	}
+
+	// attendanceChecker looks up who joined a Zoom meeting. Implemented by zoomService.
+	attendanceChecker interface {
+		meetingAttendees(ctx context.Context, meetingID int64, sessionStart time.Time) (map[string]bool, error)
+	}
+
+	// rosterExporter builds the list of people signed up for sessions.
+	rosterExporter struct {
+		sessions sessionLoader
+		codes    joinCodeStore
+		// Optional. Zoom attendance is left unknown when nil.
+		attendance attendanceChecker
+		// Zoom meetings by Greenlight program ID.
+		programs programs
+		logger   *slog.Logger
+	}
+
+	rosterRow struct {
+		SessionID    string    `json:"sessionId"`
+		SessionStart time.Time `json:"sessionStart"`
+		Cohort       string    `json:"cohort"`
+		NameFirst    string    `json:"nameFirst"`
+		NameLast     string    `json:"nameLast"`
+		Email        string    `json:"email"`
+		Phone        string    `json:"phone"`
+		// "IN_PERSON" | "VIRTUAL"
+		AttendingLocation string `json:"attendingLocation"`
+		SMSOptIn          bool   `json:"smsOptIn"`
+		// Set when the person used their join code in Greenlight.
+		JoinCodeUsedAt string `json:"joinCodeUsedAt,omitempty"`
+		// Nil when attendance is unknown. Ex: in-person attendees, upcoming sessions.
+		ZoomAttended *bool `json:"zoomAttended"`
+	}
+)
+
+// Roster export formats.
+const (
+	rosterCSV  = "csv"
+	rosterJSON = "json"
+)
+
+// ExportRoster writes the roster of the queried sessions to w in the given format ("csv" or "json").
+func ExportRoster(ctx context.Context, cfg Config, w io.Writer, q RosterQuery, format string) error {
+	if format != rosterCSV && format != rosterJSON {
+		return fmt.Errorf("unknown roster format: %q", format)
+	}
+	mongoClient, dbName, err := getMongoClient(cfg)
+	if err != nil {
+		return fmt.Errorf("getMongoClient: %w", err)
+	}
+	defer func() { _ = mongoClient.Disconnect(context.Background()) }()
+
+	r := newRosterExporterFromConfig(cfg, slog.Default(), mongoClient, dbName)
+
+	rows, err := r.roster(ctx, q)
+	if err != nil {
+		return fmt.Errorf("roster: %w", err)
+	}
+	return writeRoster(w, rows, format)
+}
+
+// Roster returns a row for each participant of the queried sessions, ordered by session start, then last and first name.
+func (r *rosterExporter) roster(ctx context.Context, q RosterQuery) ([]rosterRow, error) {
+	sq, err := q.sessionQuery()
+	if err != nil {
+		return nil, err
+	}
+	if sq.ID == "" {
+		sq.ProgramIDs = r.programs.ids()
+	}
+	sessions, err := r.sessions.GetSessions(ctx, sq)
+	if err != nil {
+		return nil, fmt.Errorf("getSessions: %w", err)
+	}
+
+	rows := []rosterRow{}
+	for _, session := range sessions {
+		attendees := r.zoomAttendees(ctx, session)
+
+		for _, p := range session.Participants {
+			row := rosterRow{
+				SessionID:         session.ID,
+				SessionStart:      session.Times.Start.DateTime,
+				Cohort:            session.Cohort,
+				NameFirst:         p.NameFirst,
+				NameLast:          p.NameLast,
+				Email:             p.Email,
+				Phone:             p.Cell,
+				AttendingLocation: p.AttendingLocation,
+				SMSOptIn:          !p.SMSOptOut,
+			}
+			if row.AttendingLocation == "" && session.LocationType != "HYBRID" {
+				row.AttendingLocation = session.LocationType
+			}
+
+			if p.UserJoinCodeID != "" {
+				var code greenlight.UserJoinCode
+				if err := optional(r.codes.GetUserJoinCode(ctx, p.UserJoinCodeID, &code)); err != nil {
+					return nil, fmt.Errorf("getUserJoinCode: %w", err)
+				}
+				row.JoinCodeUsedAt = code.UsedAt
+			}
+
+			if attendees != nil && row.AttendingLocation != "IN_PERSON" {
+				attended := attendees[strings.ToLower(p.Email)]
+				row.ZoomAttended = &attended
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if !a.SessionStart.Equal(b.SessionStart) {
+			return a.SessionStart.Before(b.SessionStart)
+		}
+		if a.NameLast != b.NameLast {
+			return a.NameLast < b.NameLast
+		}
+		return a.NameFirst < b.NameFirst
+	})
+	return rows, nil
+}
+
+// ZoomAttendees returns the emails of the people who joined the session's Zoom meeting, or nil if attendance can't be known. Lookup failures are logged, not returned, so the rest of the roster can still be exported.
+func (r *rosterExporter) zoomAttendees(ctx context.Context, session *notify.UpcomingSession) map[string]bool {
+	start := session.Times.Start.DateTime
+	if r.attendance == nil || session.LocationType == "IN_PERSON" || start.After(time.Now()) {
+		return nil
+	}
+
+	loc, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		r.logger.ErrorContext(ctx, fmt.Errorf("loadLocation: %w", err).Error())
+		return nil
+	}
+	meetings := r.programs.get(session.ProgramID).meetings
+	meetingID, err := strconv.ParseInt(meetings[start.In(loc).Hour()], 10, 64)
+	if err != nil {
+		// No Zoom meeting for this session time.
+		return nil
+	}
+
+	attendees, err := r.attendance.meetingAttendees(ctx, meetingID, start)
+	if err != nil {
+		r.logger.ErrorContext(ctx, fmt.Errorf("meetingAttendees: %w", err).Error(), slog.String("sessionId", session.ID))
+		return nil
+	}
+	return attendees
+}
+
+// SessionQuery parses the roster query's dates.
+func (q RosterQuery) sessionQuery() (notify.SessionQuery, error) {
+	if q.SessionID != "" {
+		return notify.SessionQuery{ID: q.SessionID}, nil
+	}
+	if q.From == "" {
+		return notify.SessionQuery{}, errors.New("a session ID or 'from' date is required")
+	}
+
+	tz, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		return notify.SessionQuery{}, fmt.Errorf("loadLocation: %w", err)
+	}
+	const layout = "2006-01-02"
+	from, err := time.ParseInLocation(layout, q.From, tz)
+	if err != nil {
+		return notify.SessionQuery{}, fmt.Errorf("invalid 'from' date: %q", q.From)
+	}
+	to := from
+	if q.To != "" {
+		if to, err = time.ParseInLocation(layout, q.To, tz); err != nil {
+			return notify.SessionQuery{}, fmt.Errorf("invalid 'to' date: %q", q.To)
+		}
+	}
+	if to.Before(from) {
+		return notify.SessionQuery{}, errors.New("'from' must be on or before 'to'")
+	}
+	return notify.SessionQuery{From: from, To: to.AddDate(0, 0, 1)}, nil
+}
+
+// WriteRoster writes the rows as CSV with a header row, or as a JSON array.
+func writeRoster(w io.Writer, rows []rosterRow, format string) error {
+	switch format {
+	case rosterJSON:
+		if err := json.NewEncoder(w).Encode(rows); err != nil {
+			return fmt.Errorf("encode: %w", err)
+		}
+		return nil
+	case rosterCSV:
+	default:
+		return fmt.Errorf("unknown roster format: %q", format)
+	}
+
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{
+		"Session ID", "Session Start", "Cohort", "First Name", "Last Name", "Email", "Phone",
+		"Attending Location", "SMS Opt-In", "Join Code Used At", "Zoom Attended",
+	})
+	for _, row := range rows {
+		attended := ""
+		if row.ZoomAttended != nil {
+			attended = strconv.FormatBool(*row.ZoomAttended)
+		}
+		_ = cw.Write([]string{
+			row.SessionID,
+			row.SessionStart.Format(time.RFC3339),
+			row.Cohort,
+			row.NameFirst,
+			row.NameLast,
+			row.Email,
+			row.Phone,
+			row.AttendingLocation,
+			strconv.FormatBool(row.SMSOptIn),
+			row.JoinCodeUsedAt,
+			attended,
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("csv: %w", err)
+	}
+	return nil
+}
+
+// HandleRoster exports the roster of a session, or of the sessions starting in a date range.
+func (s *adminServer) handleRoster(w http.ResponseWriter, r *http.Request) {
+	if s.roster == nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	q := r.URL.Query()
+	format := q.Get("format")
+	if format == "" {
+		format = rosterCSV
+	}
+	if format != rosterCSV && format != rosterJSON {
+		http.Error(w, fmt.Sprintf("invalid 'format': %q", format), http.StatusBadRequest)
+		return
+	}
+
+	query := RosterQuery{SessionID: q.Get("sessionId"), From: q.Get("from"), To: q.Get("to")}
+	if _, err := query.sessionQuery(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rows, err := s.roster.roster(r.Context(), query)
+	if err != nil {
+		s.serverError(w, r, fmt.Errorf("roster: %w", err))
+		return
+	}
+
+	if format == rosterCSV {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="roster.csv"`)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	if err := writeRoster(w, rows, format); err != nil {
+		s.logError(r.Context(), fmt.Errorf("writeRoster: %w", err))
+	}
+}
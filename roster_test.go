@@ -0,0 +1,121 @@
+package signup
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/operationspark/service-signup/greenlight"
+	"github.com/operationspark/service-signup/notify"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSessionLoader struct {
+	query    notify.SessionQuery
+	sessions []*notify.UpcomingSession
+}
+
+func (m *mockSessionLoader) GetSessions(ctx context.Context, q notify.SessionQuery) ([]*notify.UpcomingSession, error) {
+	m.query = q
+	return m.sessions, nil
+}
+
+type mockAttendance struct {
+	meetingID int64
+	attendees map[string]bool
+}
+
+func (m *mockAttendance) meetingAttendees(ctx context.Context, meetingID int64, sessionStart time.Time) (map[string]bool, error) {
+	m.meetingID = meetingID
+	return m.attendees, nil
+}
+
+func TestRoster(t *testing.T) {
+	// Noon Central
+	start := mustMakeTime(t, time.RFC822, "16 Nov 22 18:00 UTC")
+	session := &notify.UpcomingSession{
+		ID:           "session-1",
+		Cohort:       "is-nov-16-22-12pm",
+		LocationType: "HYBRID",
+		Participants: []notify.Participant{
+			{NameFirst: "Halle", NameLast: "Bot", Email: "Halle@Email.com", Cell: "555-987-6543", AttendingLocation: "VIRTUAL", UserJoinCodeID: "65a000000000000000000002"},
+			{NameFirst: "Henri", NameLast: "Testaroni", Email: "henri@email.com", Cell: "555-123-4567", AttendingLocation: "IN_PERSON", SMSOptOut: true, UserJoinCodeID: "65a000000000000000000001"},
+			{NameFirst: "Ada", NameLast: "Bot", Email: "ada@email.com", AttendingLocation: "VIRTUAL"},
+		},
+	}
+	session.Times.Start.DateTime = start
+
+	sessions := &mockSessionLoader{sessions: []*notify.UpcomingSession{session}}
+	attendance := &mockAttendance{attendees: map[string]bool{"halle@email.com": true}}
+	r := &rosterExporter{
+		sessions: sessions,
+		codes: &mockAdminStore{codes: map[string]greenlight.UserJoinCode{
+			"65a000000000000000000001": {SessionID: "session-1", UsedAt: "2022-11-16T18:05:00Z"},
+			"65a000000000000000000002": {SessionID: "session-1"},
+		}},
+		attendance: attendance,
+		programs:   infoSessionPrograms(map[int]string{12: "89012345678"}),
+		logger:     slog.Default(),
+	}
+
+	t.Run("lists participants with join code use and Zoom attendance", func(t *testing.T) {
+		rows, err := r.roster(context.Background(), RosterQuery{SessionID: "session-1"})
+		require.NoError(t, err)
+
+		require.Equal(t, "session-1", sessions.query.ID)
+		require.Equal(t, int64(89012345678), attendance.meetingID)
+
+		yes, no := true, false
+		require.Equal(t, []rosterRow{
+			{SessionID: "session-1", SessionStart: start, Cohort: "is-nov-16-22-12pm", NameFirst: "Ada", NameLast: "Bot", Email: "ada@email.com", AttendingLocation: "VIRTUAL", SMSOptIn: true, ZoomAttended: &no},
+			{SessionID: "session-1", SessionStart: start, Cohort: "is-nov-16-22-12pm", NameFirst: "Halle", NameLast: "Bot", Email: "Halle@Email.com", Phone: "555-987-6543", AttendingLocation: "VIRTUAL", SMSOptIn: true, ZoomAttended: &yes},
+			{SessionID: "session-1", SessionStart: start, Cohort: "is-nov-16-22-12pm", NameFirst: "Henri", NameLast: "Testaroni", Email: "henri@email.com", Phone: "555-123-4567", AttendingLocation: "IN_PERSON", SMSOptIn: false, JoinCodeUsedAt: "2022-11-16T18:05:00Z"},
+		}, rows)
+	})
+
+	t.Run("queries sessions by inclusive Central Time dates", func(t *testing.T) {
+		_, err := r.roster(context.Background(), RosterQuery{From: "2022-11-01", To: "2022-11-30"})
+		require.NoError(t, err)
+		require.Equal(t, "2022-11-01T00:00:00-05:00", sessions.query.From.Format(time.RFC3339))
+		require.Equal(t, "2022-12-01T00:00:00-06:00", sessions.query.To.Format(time.RFC3339))
+	})
+
+	t.Run("serves CSV and JSON exports", func(t *testing.T) {
+		s := NewAdminServer(adminServerOptions{apiKey: "admin-key", store: &mockAdminStore{}, roster: r, logger: slog.Default()})
+		get := func(target string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			req.Header.Set("Authorization", "Bearer admin-key")
+			res := httptest.NewRecorder()
+			s.ServeHTTP(res, req)
+			return res
+		}
+
+		res := get("/admin/rosters?sessionId=session-1")
+		require.Equal(t, http.StatusOK, res.Code)
+		require.Equal(t, "text/csv", res.Header().Get("Content-Type"))
+		records, err := csv.NewReader(res.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 4)
+		require.Equal(t, "Email", records[0][5])
+		require.Equal(t, []string{
+			"session-1", "2022-11-16T18:00:00Z", "is-nov-16-22-12pm", "Henri", "Testaroni", "henri@email.com", "555-123-4567",
+			"IN_PERSON", "false", "2022-11-16T18:05:00Z", "",
+		}, records[3])
+
+		res = get("/admin/rosters?from=2022-11-16&format=json")
+		require.Equal(t, http.StatusOK, res.Code)
+		var rows []rosterRow
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&rows))
+		require.Len(t, rows, 3)
+
+		for _, query := range []string{"", "from=yesterday", "from=2022-12-01&to=2022-11-01", "sessionId=session-1&format=xml"} {
+			res := get("/admin/rosters?" + query)
+			require.Equal(t, http.StatusBadRequest, res.Code, query)
+		}
+	})
+}
@@ -8,10 +8,11 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/gorilla/schema"
+	"github.com/operationspark/service-signup/metrics"
+	"github.com/operationspark/service-signup/templates"
 )
 
 type registerer interface {
@@ -21,6 +22,10 @@ type registerer interface {
 type signupServer struct {
 	service registerer
 	logger  *slog.Logger
+	// SMS templates used by the preview endpoint.
+	templates *templates.Registry
+	// Screens signups for bots and spam. Optional.
+	guard *signupGuard
 }
 
 // badReqBodyResp is the response body for a bad request. This is used for an invalid SignUp request.
@@ -38,6 +43,12 @@ func (ss *signupServer) HandleSignUp(w http.ResponseWriter, r *http.Request) {
 
 	var su Signup
 
+	// Counted once the request is handled. Requests that fail before registration are invalid.
+	outcome := metrics.OutcomeInvalid
+	defer func() {
+		metrics.Signups.WithLabelValues(metrics.LocationType(su.LocationType), outcome).Inc()
+	}()
+
 	// Parse JSON or URL Encoded Signup Form
 	switch r.Header.Get("Content-Type") {
 	case "application/json":
@@ -85,28 +96,54 @@ func (ss *signupServer) HandleSignUp(w http.ResponseWriter, r *http.Request) {
 		),
 	)
 
+	if ss.guard != nil {
+		err := ss.guard.check(r.Context(), su, clientIP(r))
+		if errors.Is(err, errRateLimited) {
+			outcome = metrics.OutcomeRateLimited
+			signupLogger.WarnContext(r.Context(), err.Error())
+			ss.errorResponse(w, r, http.StatusTooManyRequests, "Too many signups. Please try again later.")
+			return
+		}
+
+		var suspicious *suspiciousSignupError
+		if errors.As(err, &suspicious) {
+			signupLogger.WarnContext(r.Context(), err.Error())
+			if !ss.guard.quarantine {
+				outcome = metrics.OutcomeRejected
+				ss.badRequestResponse(w, r, "Signup rejected")
+				return
+			}
+			outcome = metrics.OutcomeQuarantined
+			if err := ss.guard.hold(r.Context(), su, suspicious.Reason); err != nil {
+				ss.logError(r.Context(), r, fmt.Errorf("hold: %w", err))
+			}
+			// Respond like any other signup so bots don't learn they were caught.
+			if err := ss.writeJSON(w, http.StatusCreated, response{}); err != nil {
+				ss.serverErrorResponse(w, r, fmt.Errorf("write 'created' response: %w", err))
+			}
+			return
+		}
+	}
+
 	postRegistration, err := ss.service.register(r.Context(), su, signupLogger)
 	// depending on what we get back, respond accordingly
 	if err != nil {
-		// handle invalid phone number error
-		if strings.Contains(err.Error(), "invalid number") {
-			// marshall error response
-			errResp := badReqBodyResp{
-				Message: "Invalid Phone Number",
-				Field:   "phone",
-			}
-
+		// Tell the person which value to fix when an integration rejects it (Ex: an invalid phone number)
+		if errResp, ok := userError(err); ok {
+			signupLogger.InfoContext(r.Context(), "signup rejected by integration", slog.String("error", err.Error()))
 			if err := ss.writeJSON(w, http.StatusBadRequest, errResp); err != nil {
 				ss.serverErrorResponse(w, r, fmt.Errorf("write 'bad request' response: %w", err))
 			}
 			return
 		}
 
+		outcome = metrics.OutcomeFailed
 		signupLogger.ErrorContext(r.Context(), "signup failed")
 		ss.serverErrorResponse(w, r, fmt.Errorf("user registration: %w", err))
 		return
 	}
 
+	outcome = metrics.OutcomeCreated
 	w.WriteHeader(http.StatusCreated)
 	if err := json.NewEncoder(w).Encode(response{URL: postRegistration.ShortLink}); err != nil {
 		ss.serverErrorResponse(w, r, fmt.Errorf("write 'created' response: %w", err))
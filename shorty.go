@@ -57,6 +57,11 @@ func NewURLShortener(o ShortenerOpts) *Shortener {
 	}
 }
 
+// Ping returns an error if the shortener API cannot be reached.
+func (s Shortener) ping(ctx context.Context) error {
+	return checkReachable(ctx, &s.client, s.baseAPIEndpoint)
+}
+
 // ShortenURL POSTs a URL to Operation Spark's URL shortener service and returns the shortened URL result.
 // Ex:
 //
@@ -84,7 +89,7 @@ func (s Shortener) ShortenURL(ctx context.Context, url string) (string, error) {
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode >= 300 {
-		return url, fmt.Errorf("post: %w", handleHTTPError(resp))
+		return url, fmt.Errorf("post: %w", newHTTPError("shortener", resp))
 	}
 
 	d := json.NewDecoder(resp.Body)
@@ -3,19 +3,18 @@ package signup
 
 import (
 	"context"
-	"encoding/base64"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log/slog"
-	"os"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/operationspark/service-signup/email"
 	"github.com/operationspark/service-signup/greenlight"
-	"github.com/operationspark/service-signup/notify"
-	"golang.org/x/sync/errgroup"
+	"github.com/operationspark/service-signup/i18n"
+	"github.com/operationspark/service-signup/metrics"
+	"github.com/operationspark/service-signup/templates"
 )
 
 type (
@@ -31,6 +30,8 @@ type (
 		Email string `json:"email" schema:"email"`
 		// The session's location's Google Place details.
 		GooglePlace greenlight.GooglePlace `json:"googlePlace" schema:"googlePlace"`
+		// The person's preferred language for messages. "en" | "es". Defaults to English.
+		Language string `json:"language" schema:"language"`
 		// Session's set location type. One of "IN_PERSON" | "VIRTUAL" | "IN_PERSON". If the session's location type is "HYBRID", a student can attend "IN_PERSON" or "VIRTUAL"ly.
 		LocationType string `json:"locationType" schema:"locationType"`
 		// A legacy 4-character join code for a Greenlight session.
@@ -47,6 +48,16 @@ type (
 		Token         string    `json:"token" schema:"token"`
 		// State or country where the person resides.
 		UserLocation string `json:"userLocation" schema:"userLocation"`
+		// UTM parameters from the marketing link the person followed. Filled from the landing page URL when not sent.
+		UTMSource   string `json:"utmSource" schema:"utmSource"`
+		UTMMedium   string `json:"utmMedium" schema:"utmMedium"`
+		UTMCampaign string `json:"utmCampaign" schema:"utmCampaign"`
+		UTMTerm     string `json:"utmTerm" schema:"utmTerm"`
+		UTMContent  string `json:"utmContent" schema:"utmContent"`
+		// URL of the first page the person visited on the website. Ex: "https://www.operationspark.org/?utm_source=facebook".
+		LandingPage string `json:"landingPage" schema:"landingPage"`
+		// Honeypot field. Hidden on the signup form, so only bots fill it in.
+		Website string `json:"website" schema:"website"`
 
 		// URL linking the user to an post-signup information page.
 		ShortLink string
@@ -54,10 +65,14 @@ type (
 		id *string
 		// Unique identifier for Twilio SMS messaging conversation. Set by the Twilio service.
 		conversationID *string
+		// Unique identifier for the saved signup record. Used by Slack actions.
+		recordID string
 		// A user specific join code for a Greenlight session.
 		userJoinCode   string
 		zoomMeetingID  int64
 		zoomMeetingURL string
+		// Results of the signup tasks. Set by the pipeline.
+		taskOutcomes []taskOutcome
 	}
 
 	SignupAlias Signup
@@ -82,13 +97,20 @@ type (
 	}
 
 	SignupService struct {
-		// Key-value map with the Central Time meeting start hour (int) as the keys, and Zoom Meeting ID as the values.
-		// Ex: {17: "86935241734"} denotes meeting with ID, "86935241734", starts at 5pm central.
-		meetings        map[int]string // Map of Zoom meeting IDs to Central Time meeting start hours.
-		tasks           []mutationTask // List of tasks to run on submission of a signup.
-		postSignupTasks []Runner       // List of tasks to run after a successful signup.
-		zoomService     mutationTask   // Zoom service.
-		gldbService     codeCreator    // Greenlight service.
+		programs    programs     // Zoom meetings and templates by Greenlight program ID.
+		pipeline    *pipeline    // Tasks to run on submission of a signup.
+		zoomService mutationTask // Zoom service.
+		gldbService codeCreator  // Greenlight service.
+		store       signupStore  // Saves completed signups. Optional.
+		// Tasks to run again when staff resend a confirmation.
+		confirmationTasks []mutationTask
+		greenlightHost    string       // Base URL for Greenlight links in the messaging URL.
+		rendererURL       string       // Message Template Renderer Service base URL.
+		shortener         urlShortener // Shortens the messaging URL.
+	}
+
+	urlShortener interface {
+		ShortenURL(ctx context.Context, url string) (string, error)
 	}
 
 	// codeCreator creates a Session join code for a user.
@@ -120,70 +142,30 @@ type (
 	}
 
 	signupServiceOptions struct {
-		// Key-value map with the Central Time meeting start hour (int) as the keys, and Zoom Meeting ID as the values.
-		// Ex: {17: "86935241734"} denotes meeting with ID, "86935241734", starts at 5pm central.
-		meetings        map[int]string
-		tasks           []mutationTask
+		// Zoom meetings and templates by Greenlight program ID. Signups for unknown programs use the Info Session program.
+		programs programs
+		// Tasks to run on submission of a signup, after the built-in Zoom, join code, and short link tasks. Each task runs once the fields it consumes are set (see taskSpec).
+		tasks []mutationTask
+		// Tasks to run once the Greenlight signup ID and SMS conversation ID are set.
 		postSignupTasks []Runner
-		// The Zoom Service needs to mutate the Signup struct with a meeting join URL. Due to this mutation, we need to pull the zoom service out of the task flow and use it before running the tasks.
+		// Disables tasks or changes whether they are required.
+		taskOptions pipelineOptions
+		// Registers the person for the session's Zoom meeting and sets the join URL.
 		zoomService mutationTask
 		gldbService codeCreator
-		logger      *slog.Logger
-	}
-
-	Location struct {
-		Name         string `json:"name"`
-		Line1        string `json:"line1"`
-		CityStateZip string `json:"cityStateZip"`
-		MapURL       string `json:"mapUrl"`
-	}
-
-	// Request params for the Operation Spark Message Template Renderer service.
-	rendererReqParams struct {
-		Template      osRendererTemplate `json:"template"`
-		ZoomLink      string             `json:"zoomLink"`
-		Date          time.Time          `json:"date"`
-		Name          string             `json:"name"`
-		LocationType  string             `json:"locationType"`
-		Location      Location           `json:"location"`
-		JoinCode      string             `json:"joinCode,omitempty"`
-		IsGmail       bool               `json:"isGmail"`
-		GreenlightURL string             `json:"greenlightUrl"`
-	}
-
-	osRenderer struct {
-		// OpSpark Message Template Renderer Service base URL.
-		// Defaults to https://sms.operationspark.org
-		baseURL string
+		store       signupStore
+		// Tasks to run again when staff resend a confirmation. Ex: welcome email, SMS confirmation.
+		confirmationTasks []mutationTask
+		// Base URL for Greenlight links in the messaging URL.
+		greenlightHost string
+		// Operation Spark Message Template Renderer Service base URL.
+		rendererURL string
+		// Shortens the messaging URL. Defaults to the Operation Spark URL shortener without an API key.
+		shortener urlShortener
+		logger    *slog.Logger
 	}
-
-	osRendererTemplate string
 )
 
-const (
-	InfoSessionTemplate osRendererTemplate = "InfoSession"
-)
-
-// StructToBase64 marshals a struct to JSON then encodes the string to base64.
-func (m *rendererReqParams) toBase64() (string, error) {
-	j, err := json.Marshal(m)
-	if err != nil {
-		return "", fmt.Errorf("marshall: %w", err)
-	}
-
-	return base64.URLEncoding.EncodeToString(j), nil
-}
-
-// FromBase64 decodes a base64 string into a messagingReqParams struct.
-func (m *rendererReqParams) fromBase64(encoded string) error {
-	jsonBytes, err := base64.URLEncoding.DecodeString(encoded)
-	if err != nil {
-		return err
-	}
-
-	return json.Unmarshal(jsonBytes, m)
-}
-
 func (su Signup) MarshalJSON() ([]byte, error) {
 	return json.Marshal(SignupJSON{
 		SignupAlias(su),
@@ -191,8 +173,8 @@ func (su Signup) MarshalJSON() ([]byte, error) {
 	})
 }
 
-// WelcomeData takes a Signup and prepares template variables for use in the Welcome email template.
-func (su *Signup) welcomeData() (welcomeVariables, error) {
+// WelcomeData takes a Signup and prepares template variables for use in the Welcome email template. The enroll link points to greenlightHost.
+func (su *Signup) welcomeData(greenlightHost string) (welcomeVariables, error) {
 	if su.StartDateTime.IsZero() {
 		return welcomeVariables{
 			FirstName: su.NameFirst,
@@ -208,15 +190,15 @@ func (su *Signup) welcomeData() (welcomeVariables, error) {
 	return welcomeVariables{
 		FirstName:            su.NameFirst,
 		LastName:             su.NameLast,
-		SessionTime:          su.StartDateTime.In(ctz).Format("3:04 PM MST"),
-		SessionDate:          su.StartDateTime.In(ctz).Format("Monday, Jan 02"),
+		SessionTime:          i18n.FormatTime(su.lang(), su.StartDateTime.In(ctz), i18n.KeyLayoutTime),
+		SessionDate:          i18n.FormatTime(su.lang(), su.StartDateTime.In(ctz), i18n.KeyLayoutLongDate),
 		ZoomURL:              su.ZoomMeetingURL(),
 		LocationLine1:        line1,
 		LocationCityStateZip: cityStateZip,
 		LocationMapURL:       greenlight.GoogleLocationLink(su.GooglePlace.Address),
 		JoinCode:             su.JoinCode,
 		IsGmail:              su.isGmail(),
-		GreenlightEnrollURL:  su.greenlightAutoEnrollURL("https://greenlight.operationspark.org"),
+		GreenlightEnrollURL:  su.greenlightAutoEnrollURL(greenlightHost),
 	}, nil
 }
 
@@ -251,34 +233,45 @@ func (su Signup) ZoomMeetingURL() string {
 	return su.zoomMeetingURL
 }
 
-// ShortMessage creates a signup confirmation message in 160 characters or less.
-func (su Signup) shortMessage(infoURL string) (string, error) {
-	// Handle "None of these fit my schedule"
-	if su.StartDateTime.IsZero() {
-		return fmt.Sprintf("Hello from Operation Spark!\nView this link for details:\n%s", infoURL), nil
-	}
-
+// ShortMessage renders the named signup confirmation SMS template. English messages fit in 160 characters or less.
+func (su Signup) shortMessage(tmpls *templates.Registry, name templates.Name, infoURL string) (string, error) {
 	// Set times to Central time
 	ctz, err := time.LoadLocation("America/Chicago")
 	if err != nil {
 		return "", fmt.Errorf("loadLocation: %w", err)
 	}
-	infoTime := su.StartDateTime.In(ctz).Format("3:04p MST")
-	infoDate := su.StartDateTime.In(ctz).Format("Mon Jan 02")
 
-	msg := fmt.Sprintf(
-		"You've signed up for an info session with Operation Spark!\nThe session is %s @ %s.",
-		infoDate,
-		infoTime,
-	)
+	return tmpls.Render(name, su.lang(), ctz, templates.SignupData{
+		NameFirst:     su.NameFirst,
+		StartDateTime: su.StartDateTime,
+		URL:           infoURL,
+	})
+}
 
-	// Refer to email if the Information Link is not set for some reason.
-	if len(infoURL) == 0 {
-		return msg + "\nCheck your email for confirmation.", nil
-	}
-	// Append the Information Short Link
-	return msg + fmt.Sprintf("\nView this link for details:\n%s", infoURL), nil
+// Lang returns the signup's preferred language, defaulting to English.
+func (su Signup) lang() i18n.Language {
+	return i18n.Parse(su.Language)
+}
 
+// Delivery creates the delivery record for the signup's welcome email.
+func (su Signup) delivery(messageID string) email.Delivery {
+	d := email.Delivery{
+		MessageID:     messageID,
+		Email:         su.Email,
+		NameFirst:     su.NameFirst,
+		NameLast:      su.NameLast,
+		Cell:          su.Cell,
+		SMSOptIn:      su.SMSOptIn,
+		Language:      string(su.lang()),
+		SessionID:     su.SessionID,
+		StartDateTime: su.StartDateTime,
+		ShortLink:     su.ShortLink,
+		Status:        email.StatusSent,
+	}
+	if su.id != nil {
+		d.SignupID = *su.id
+	}
+	return d
 }
 
 // GreenlightAutoEnrollURL returns a URL that auto-enrolls a user into a Greenlight session.
@@ -296,11 +289,11 @@ func (su Signup) isGmail() bool {
 
 // ShortMessagingURL produces a custom URL for use on Operation Spark's SMS Messaging Preview service.
 // https://github.com/OperationSpark/sms.opspark.org
-func (su Signup) shortMessagingURL(greenlightHost, baseURL string) (string, error) {
+func (su Signup) shortMessagingURL(tmpl osRendererTemplate, greenlightHost, baseURL string) (string, error) {
 	line1, cityStateZip := greenlight.ParseAddress(su.GooglePlace.Address)
 
-	p := rendererReqParams{
-		Template:      InfoSessionTemplate,
+	return rendererURL(baseURL, &infoSessionParams{
+		Template:      tmpl,
 		ZoomLink:      su.zoomMeetingURL,
 		Date:          su.StartDateTime,
 		Name:          su.NameFirst,
@@ -308,20 +301,14 @@ func (su Signup) shortMessagingURL(greenlightHost, baseURL string) (string, erro
 		JoinCode:      su.JoinCode,
 		IsGmail:       su.isGmail(),
 		GreenlightURL: su.greenlightAutoEnrollURL(greenlightHost),
+		Language:      su.lang(),
 		Location: Location{
 			Name:         su.GooglePlace.Name,
 			Line1:        line1,
 			CityStateZip: cityStateZip,
 			MapURL:       greenlight.GoogleLocationLink(su.GooglePlace.Address),
 		},
-	}
-
-	encoded, err := p.toBase64()
-	if err != nil {
-		return "", fmt.Errorf("structToBase64: %w", err)
-	}
-
-	return fmt.Sprintf("%s/m/%s", baseURL, encoded), nil
+	})
 }
 
 // String creates a human-readable Signup for debugging purposes.
@@ -337,124 +324,156 @@ func (su Signup) String() string {
 	)
 }
 
-func newSignupService(o signupServiceOptions) *SignupService {
-	return &SignupService{
-		meetings:        o.meetings,
-		tasks:           o.tasks,
-		zoomService:     o.zoomService,
-		gldbService:     o.gldbService,
-		postSignupTasks: o.postSignupTasks,
+func newSignupService(o signupServiceOptions) (*SignupService, error) {
+	if o.shortener == nil {
+		o.shortener = NewURLShortener(ShortenerOpts{})
+	}
+	s := &SignupService{
+		programs:          o.programs,
+		zoomService:       o.zoomService,
+		gldbService:       o.gldbService,
+		store:             o.store,
+		confirmationTasks: o.confirmationTasks,
+		greenlightHost:    strings.TrimSuffix(orDefault(o.greenlightHost, greenlightBaseURL), "/"),
+		rendererURL:       o.rendererURL,
+		shortener:         o.shortener,
 	}
-}
 
-// Register concurrently executes a list of tasks. Completion of tasks are not dependent on each other.
-func (s *SignupService) register(ctx context.Context, su Signup, logger *slog.Logger) (Signup, error) {
-	// TODO: Create specific errors for each handler
-	err := s.attachZoomMeetingID(&su)
-	if err != nil {
-		return su, fmt.Errorf("attachZoomMeetingID: %w", err)
+	tasks := []mutationTask{s.zoomTask(), s.joinCodeTask(), s.shortLinkTask()}
+	tasks = append(tasks, o.tasks...)
+	for _, r := range o.postSignupTasks {
+		tasks = append(tasks, runnerTask(r))
 	}
-	err = s.zoomService.run(ctx, &su, logger)
+	p, err := newPipeline(tasks, o.taskOptions)
 	if err != nil {
-		return su, fmt.Errorf("zoomService.run: %w", err)
+		return nil, fmt.Errorf("newPipeline: %w", err)
 	}
+	s.pipeline = p
+	return s, nil
+}
+
+// Register runs the signup tasks. Tasks run concurrently, except that each waits for the tasks producing the fields it consumes.
+func (s *SignupService) register(ctx context.Context, su Signup, logger *slog.Logger) (Signup, error) {
+	return s.registerWithout(ctx, su, logger, nil)
+}
 
-	if su.SessionID != "" {
-		joinCodeID, sessionJoinCode, err := s.gldbService.CreateUserJoinCode(ctx, su.SessionID)
+// RegisterWithout registers the signup without running the tasks with the given keys. Ex: no Slack message for imported signups.
+func (s *SignupService) registerWithout(ctx context.Context, su Signup, logger *slog.Logger, skip []string) (Signup, error) {
+	su.parseLandingPageUTM()
+
+	// Slack actions refer to the saved record.
+	if s.store != nil {
+		var err error
+		su.recordID, err = newRecordID()
 		if err != nil {
-			return su, fmt.Errorf("userJoinCode Create: %w", err)
+			return su, fmt.Errorf("newRecordID: %w", err)
 		}
-
-		su.userJoinCode = joinCodeID
-		su.JoinCode = sessionJoinCode
 	}
 
-	// create user-specific info session details URL
-	msgngURL, err := su.shortMessagingURL(os.Getenv("GREENLIGHT_HOST"), os.Getenv("OS_RENDERING_SERVICE_URL"))
-	if err != nil {
-		return su, fmt.Errorf("shortMessagingURL: %w", err)
+	if err := s.pipeline.run(ctx, &su, logger, skip...); err != nil {
+		// Staff can look up what failed.
+		s.saveRecord(ctx, su, signupStatusFailed, logger)
+		return su, err
 	}
 
-	shorty := NewURLShortener(ShortenerOpts{apiKey: os.Getenv("URL_SHORTENER_API_KEY")})
-	shortLink, err := shorty.ShortenURL(ctx, msgngURL)
-	if err != nil {
-		logger.ErrorContext(ctx,
-			fmt.Errorf("shortenURL: %w", err).Error(),
-			slog.String("url", msgngURL),
-		)
-		// Don't early return. ShortenURL returns the original URL if there is a failure
-		// Fallback to long URL if shortener fails
-	}
+	s.saveRecord(ctx, su, signupStatusActive, logger)
+	return su, nil
+}
 
-	su.ShortLink = shortLink
-
-	// Creating a new context because the errgroup will cancel the context when Wait() is returned,
-	// even with a nil error.
-	var cancel context.CancelCauseFunc
-	ctx, cancel = context.WithCancelCause(ctx)
-	g, gCtx := errgroup.WithContext(ctx)
-	for _, task := range s.tasks {
-		func(t mutationTask) {
-			g.Go(func() error {
-				err := t.run(gCtx, &su, logger)
-				if err != nil {
-					if t.isRequired() {
-						return fmt.Errorf("task failed: %q: %w", t.name(), err)
-					}
-					logger.InfoContext(ctx,
-						"non-mandatory task failed",
-						slog.String("task", t.name()),
-						slog.String("error", err.Error()))
-				}
+// ZoomTask registers the person for the Zoom meeting of the session they signed up for.
+func (s *SignupService) zoomTask() funcTask {
+	t := funcTask{
+		taskSpec: taskSpec{
+			key:         taskZoom,
+			produces:    []signupField{fieldZoomJoinURL},
+			integration: "zoom",
+			// Zoom returns the existing registrant when someone registers twice.
+			idempotent: true,
+		},
+		taskName: "zoom service",
+		required: true,
+		fn: func(ctx context.Context, su *Signup, logger *slog.Logger) error {
+			if err := s.attachZoomMeetingID(su); err != nil {
+				return fmt.Errorf("attachZoomMeetingID: %w", err)
+			}
+			// Programs without Zoom meetings don't register people for Zoom.
+			if s.zoomService == nil || su.ZoomMeetingID() == 0 {
 				return nil
-			})
-		}(task)
-	}
-	if err := g.Wait(); err != nil {
-		cancel(err)
-		return su, err
+			}
+			if err := s.zoomService.run(ctx, su, logger); err != nil {
+				return fmt.Errorf("zoomService.run: %w", err)
+			}
+			return nil
+		},
 	}
-
-	if err := s.runPostSignupTasks(ctx, su, logger); err != nil {
-		logger.ErrorContext(
-			ctx,
-			fmt.Errorf("required post-signup tasks failed: %w", err).Error())
-		// Don't return early. Continue with the signup process.
+	if s.zoomService != nil {
+		t.taskName = s.zoomService.name()
+		t.required = s.zoomService.isRequired()
 	}
-	cancel(nil)
-	return su, nil
+	return t
 }
 
-func (s *SignupService) runPostSignupTasks(ctx context.Context, su Signup, logger *slog.Logger) error {
-	if !su.SMSOptIn {
-		return errors.New("user opt-out")
+// JoinCodeTask creates a Greenlight join code for the person's session.
+func (s *SignupService) joinCodeTask() funcTask {
+	return funcTask{
+		taskSpec: taskSpec{key: taskJoinCode, produces: []signupField{fieldJoinCode}, integration: "mongodb"},
+		taskName: "join code",
+		required: true,
+		fn: func(ctx context.Context, su *Signup, logger *slog.Logger) error {
+			if su.SessionID == "" {
+				return nil
+			}
+			joinCodeID, sessionJoinCode, err := s.gldbService.CreateUserJoinCode(ctx, su.SessionID)
+			if err != nil {
+				return fmt.Errorf("userJoinCode Create: %w", err)
+			}
+			su.userJoinCode = joinCodeID
+			su.JoinCode = sessionJoinCode
+			return nil
+		},
 	}
+}
 
-	logger.InfoContext(
-		ctx,
-		"Running post-signup tasks",
-		slog.Int("numTasks", len(s.postSignupTasks)))
-
-	for _, task := range s.postSignupTasks {
-		if ctx.Err() != nil {
-			return ctx.Err()
-		}
-		if su.conversationID == nil || su.id == nil {
-			return fmt.Errorf("conversationID (%v) or signup ID (%v) is nil", su.conversationID, su.id)
-		}
-
-		err := task.Run(ctx, *su.conversationID, *su.id)
-		if err != nil {
-			return fmt.Errorf("task %q: %v", task.Name(), err)
-		}
+// ShortLinkTask creates the short link to the person's session details page, rendered with their program's template. The long URL is used if the shortener fails.
+func (s *SignupService) shortLinkTask() funcTask {
+	return funcTask{
+		taskSpec: taskSpec{
+			key:         taskShortLink,
+			consumes:    []signupField{fieldZoomJoinURL, fieldJoinCode},
+			produces:    []signupField{fieldShortLink},
+			integration: "shortener",
+			idempotent:  true,
+		},
+		taskName: "short link",
+		required: true,
+		fn: func(ctx context.Context, su *Signup, logger *slog.Logger) error {
+			// create user-specific info session details URL
+			tmpl := s.programs.get(su.ProgramID).rendererTemplate
+			msgngURL, err := su.shortMessagingURL(tmpl, s.greenlightHost, s.rendererURL)
+			if err != nil {
+				return fmt.Errorf("shortMessagingURL: %w", err)
+			}
+
+			shortLink, err := s.shortener.ShortenURL(ctx, msgngURL)
+			if err != nil {
+				metrics.ShortenerFallbacks.Inc()
+				logger.ErrorContext(ctx,
+					fmt.Errorf("shortenURL: %w", err).Error(),
+					slog.String("url", msgngURL),
+				)
+				// Fallback to long URL if shortener fails. ShortenURL returns the original URL if there is a failure.
+			}
+			su.ShortLink = shortLink
+			return nil
+		},
 	}
-	return nil
 }
 
-// AttachZoomMeetingID sets the Zoom meeting ID on the Signup based on the Signup's StartDateTime and the SignService's Zoom sessions.
+// AttachZoomMeetingID sets the Zoom meeting ID on the Signup based on the Signup's StartDateTime and the Zoom meetings of the Signup's program.
 func (s *SignupService) attachZoomMeetingID(su *Signup) error {
-	// Do nothing if the user has not signed up for a specific session
-	if su.StartDateTime.IsZero() {
+	meetings := s.programs.get(su.ProgramID).meetings
+	// Do nothing if the user has not signed up for a specific session, or the program has no Zoom meetings.
+	if su.StartDateTime.IsZero() || len(meetings) == 0 {
 		return nil
 	}
 	loc, err := time.LoadLocation("America/Chicago")
@@ -464,30 +483,13 @@ func (s *SignupService) attachZoomMeetingID(su *Signup) error {
 	sessionStart := su.StartDateTime
 	centralStart := sessionStart.In(loc)
 
-	if _, ok := s.meetings[centralStart.Hour()]; !ok {
+	if _, ok := meetings[centralStart.Hour()]; !ok {
 		return fmt.Errorf("no zoom meeting found with start hour: %d", centralStart.Hour())
 	}
-	id, err := strconv.Atoi(s.meetings[centralStart.Hour()])
+	id, err := strconv.Atoi(meetings[centralStart.Hour()])
 	if err != nil {
 		return fmt.Errorf("convert string to int: %w", err)
 	}
 	su.SetZoomMeetingID(int64(id))
 	return nil
 }
-
-// CreateMessageURL creates a custom URL for use on Operation Spark's SMS Messaging Preview service.
-func (osm *osRenderer) CreateMessageURL(p notify.Participant) (string, error) {
-	params := rendererReqParams{
-		Template:     InfoSessionTemplate,
-		ZoomLink:     p.ZoomJoinURL,
-		Name:         p.NameFirst,
-		Date:         p.SessionDate,
-		LocationType: p.SessionLocationType,
-		Location:     Location(p.SessionLocation),
-	}
-	encoded, err := params.toBase64()
-	if err != nil {
-		return "", fmt.Errorf("structToBase64: %w", err)
-	}
-	return fmt.Sprintf("%s/m/%s", osm.baseURL, encoded), nil
-}
@@ -19,7 +19,9 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/operationspark/service-signup/greenlight"
+	"github.com/operationspark/service-signup/i18n"
 	"github.com/operationspark/service-signup/notify"
+	"github.com/operationspark/service-signup/templates"
 	"github.com/stretchr/testify/require"
 )
 
@@ -96,15 +98,16 @@ func TestRegister(t *testing.T) {
 			},
 		}
 
-		signupService := newSignupService(signupServiceOptions{
+		signupService, err := newSignupService(signupServiceOptions{
 			tasks:       []mutationTask{mailService},
 			zoomService: &MockZoomService{},
 			// zoom meeting id for 12 central
-			meetings:    map[int]string{12: "983782"},
+			programs:    infoSessionPrograms(map[int]string{12: "983782"}),
 			gldbService: &MockGreenlightDBService{},
 		})
+		assertNilError(t, err)
 
-		_, err := signupService.register(context.Background(), signup, slog.Default())
+		_, err = signupService.register(context.Background(), signup, slog.Default())
 		if err != nil {
 			t.Fatalf("register: %v", err)
 		}
@@ -133,13 +136,14 @@ func TestRegister(t *testing.T) {
 
 		zoomService := &MockZoomService{}
 
-		signupService := newSignupService(signupServiceOptions{
+		signupService, err := newSignupService(signupServiceOptions{
 			tasks:       []mutationTask{mailService},
 			zoomService: zoomService,
 			gldbService: &MockGreenlightDBService{},
 		})
+		assertNilError(t, err)
 
-		_, err := signupService.register(context.Background(), signup, slog.Default())
+		_, err = signupService.register(context.Background(), signup, slog.Default())
 		if err != nil {
 			t.Fatalf("register: %v", err)
 		}
@@ -160,12 +164,13 @@ func TestRegister(t *testing.T) {
 			StartDateTime:    time.Time{}, // Empty session start time
 		}
 
-		signupService := newSignupService(signupServiceOptions{
+		signupService, err := newSignupService(signupServiceOptions{
 			tasks:       []mutationTask{notRequiredTask{}},
 			zoomService: &MockZoomService{},
 		})
+		assertNilError(t, err)
 
-		_, err := signupService.register(context.Background(), signup, slog.Default())
+		_, err = signupService.register(context.Background(), signup, slog.Default())
 		if err != nil {
 			t.Fatalf("register: %v", err)
 		}
@@ -308,19 +313,20 @@ func TestWelcomeData(t *testing.T) {
 		},
 	}
 
-	suSvc := newSignupService(signupServiceOptions{
-		meetings: map[int]string{
+	suSvc, err := newSignupService(signupServiceOptions{
+		programs: infoSessionPrograms(map[int]string{
 			12: "12121212121",
 			17: "17171717171",
-		},
+		}),
 	})
+	assertNilError(t, err)
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			err := suSvc.attachZoomMeetingID(&test.signup)
 			assertNilError(t, err)
 
 			test.signup.SetZoomJoinURL("https://us06web.zoom.us/w/fakemeetingid?tk=faketoken")
-			got, err := test.signup.welcomeData()
+			got, err := test.signup.welcomeData(greenlightBaseURL)
 			if err != nil {
 				t.Errorf("Unexpected error for input date %v.\n%v", test.signup.StartDateTime, err)
 			}
@@ -332,20 +338,21 @@ func TestWelcomeData(t *testing.T) {
 
 func TestAttachZoomMeetingID(t *testing.T) {
 	t.Run("generates the correct Zoom URL for a given session start time", func(t *testing.T) {
-		suSvc := newSignupService(signupServiceOptions{
-			meetings: map[int]string{
+		suSvc, err := newSignupService(signupServiceOptions{
+			programs: infoSessionPrograms(map[int]string{
 				// Noon Central
 				12: "12123456789",
 				// 5p Central
 				17: "17123456789",
-			},
+			}),
 		})
+		assertNilError(t, err)
 		sessionStartDate, _ := time.Parse(time.RFC822, "14 Mar 22 17:00 UTC")
 		su := Signup{
 			StartDateTime: sessionStartDate,
 		}
 
-		err := suSvc.attachZoomMeetingID(&su)
+		err = suSvc.attachZoomMeetingID(&su)
 		assertNilError(t, err)
 
 		gotID := su.ZoomMeetingID()
@@ -354,6 +361,31 @@ func TestAttachZoomMeetingID(t *testing.T) {
 		assertEqual(t, gotID, wantID)
 
 	})
+
+	t.Run("uses the Zoom meetings of the signup's program", func(t *testing.T) {
+		ps := infoSessionPrograms(map[int]string{12: "12123456789"})
+		ps["workshop"] = program{id: "workshop", meetings: map[int]string{12: "99123456789"}}
+		ps["in-person"] = program{id: "in-person"}
+		suSvc, err := newSignupService(signupServiceOptions{programs: ps})
+		assertNilError(t, err)
+		start := mustMakeTime(t, time.RFC822, "14 Mar 22 17:00 UTC")
+
+		workshop := Signup{ProgramID: "workshop", StartDateTime: start}
+		assertNilError(t, suSvc.attachZoomMeetingID(&workshop))
+		assertEqual(t, workshop.ZoomMeetingID(), int64(99123456789))
+
+		inPerson := Signup{ProgramID: "in-person", StartDateTime: start}
+		assertNilError(t, suSvc.attachZoomMeetingID(&inPerson))
+		assertEqual(t, inPerson.ZoomMeetingID(), int64(0))
+
+		unknown := Signup{ProgramID: "unknown", StartDateTime: start}
+		assertNilError(t, suSvc.attachZoomMeetingID(&unknown))
+		assertEqual(t, unknown.ZoomMeetingID(), int64(0))
+
+		noProgram := Signup{StartDateTime: start}
+		assertNilError(t, suSvc.attachZoomMeetingID(&noProgram))
+		assertEqual(t, noProgram.ZoomMeetingID(), int64(12123456789))
+	})
 }
 
 func TestSummary(t *testing.T) {
@@ -415,7 +447,7 @@ func TestShortMessage(t *testing.T) {
 			StartDateTime: mustMakeTime(t, time.RFC3339, "2022-10-31T17:00:00.000Z"),
 		}
 
-		msg, err := su.shortMessage(mockShortLink)
+		msg, err := su.shortMessage(templates.Default(), templates.SignupConfirmation, mockShortLink)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -429,7 +461,7 @@ func TestShortMessage(t *testing.T) {
 			StartDateTime: mustMakeTime(t, time.RFC3339, "2022-10-31T17:00:00.000Z"),
 		}
 
-		got, err := su.shortMessage(mockShortLink)
+		got, err := su.shortMessage(templates.Default(), templates.SignupConfirmation, mockShortLink)
 		assertNilError(t, err)
 
 		want := `You've signed up for an info session with Operation Spark!
@@ -448,17 +480,34 @@ https://oprk.org/kRds5MKvKI`
 			Email:     "jramet0@narod.ru",
 		}
 
-		got, err := su.shortMessage(mockShortLink)
+		got, err := su.shortMessage(templates.Default(), templates.SignupConfirmation, mockShortLink)
 		assertNilError(t, err)
 		want := "Hello from Operation Spark!\nView this link for details:\nhttps://oprk.org/kRds5MKvKI"
 		assertEqual(t, got, want)
 
 	})
+
+	t.Run("creates a message in the signup's language", func(t *testing.T) {
+		su := Signup{
+			Language:      "es",
+			StartDateTime: mustMakeTime(t, time.RFC3339, "2022-10-31T17:00:00.000Z"),
+		}
+
+		got, err := su.shortMessage(templates.Default(), templates.SignupConfirmation, mockShortLink)
+		assertNilError(t, err)
+
+		want := `¡Te inscribiste en una sesión informativa con Operation Spark!
+La sesión es el lun 31 oct a las 12:00p CDT.
+Mira este enlace para más detalles:
+https://oprk.org/kRds5MKvKI`
+
+		assertEqual(t, got, want)
+	})
 }
 
 func TestStructToBase64(t *testing.T) {
 	t.Run("serializes a struct to base 64 encoding", func(t *testing.T) {
-		params := rendererReqParams{
+		params := infoSessionParams{
 			Template:      "InfoSession",
 			ZoomLink:      "https://us06web.zoom.us/j/12345678910",
 			Date:          mustMakeTime(t, time.RFC3339, "2022-10-05T17:00:00.000Z"),
@@ -476,7 +525,7 @@ func TestStructToBase64(t *testing.T) {
 
 		want := "eyJ0ZW1wbGF0ZSI6IkluZm9TZXNzaW9uIiwiem9vbUxpbmsiOiJodHRwczovL3VzMDZ3ZWIuem9vbS51cy9qLzEyMzQ1Njc4OTEwIiwiZGF0ZSI6IjIwMjItMTAtMDVUMTc6MDA6MDBaIiwibmFtZSI6IkZpcnN0TmFtZSIsImxvY2F0aW9uVHlwZSI6Ikh5YnJpZCIsImxvY2F0aW9uIjp7Im5hbWUiOiJTb21lIFBsYWNlIiwibGluZTEiOiIxMjMgTWFpbiBTdCIsImNpdHlTdGF0ZVppcCI6IkNpdHksIFN0YXRlIDEyMzQ1IiwibWFwVXJsIjoiaHR0cHM6Ly93d3cuZ29vZ2xlLmNvbS9tYXBzL3BsYWNlLzEyMytNYWluK1N0LCtDaXR5LCtTdGF0ZSsxMjM0NSJ9LCJpc0dtYWlsIjpmYWxzZSwiZ3JlZW5saWdodFVybCI6Imh0dHBzOi8vZ3JlZW5saWdodC5vcGVyYXRpb25zcGFyay5vcmcvc2Vzc2lvbnMva3l2WWl0TG9GZlRpY2tiUDIvP3VzZXJKb2luQ29kZT0xMjM0MTVcdTAwMjZqb2luQ29kZT0xMjMxNCJ9"
 
-		got, err := params.toBase64()
+		got, err := encodeRendererParams(&params)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -487,7 +536,7 @@ func TestStructToBase64(t *testing.T) {
 
 func TestFromBase64(t *testing.T) {
 	t.Run("decodes", func(t *testing.T) {
-		wantParams := rendererReqParams{
+		wantParams := infoSessionParams{
 			Template:     "InfoSession",
 			ZoomLink:     "https://us06web.zoom.us/j/12345678910",
 			Date:         mustMakeTime(t, "January 02, 2006 3pm MST", "December 25, 2022 1pm CST"),
@@ -501,17 +550,17 @@ func TestFromBase64(t *testing.T) {
 			},
 		}
 
-		encoded, err := wantParams.toBase64()
+		encoded, err := encodeRendererParams(&wantParams)
 		if err != nil {
 			t.Fatal(err)
 		}
 
-		var gotParams rendererReqParams
-
-		err = gotParams.fromBase64(encoded)
+		decoded, err := decodeRendererParams(encoded)
 		if err != nil {
 			t.Fatal(err)
 		}
+		gotParams, ok := decoded.(*infoSessionParams)
+		require.True(t, ok)
 
 		assertEqual(t, gotParams.Name, wantParams.Name)
 		assertEqual(t, gotParams.Date.Equal(wantParams.Date), true)
@@ -526,13 +575,13 @@ func TestFromBase64(t *testing.T) {
 	})
 
 	t.Run("decodes pre-encoded info session details link", func(t *testing.T) {
-		var params rendererReqParams
-
-		err := params.fromBase64("eyJ0ZW1wbGF0ZSI6IkluZm9TZXNzaW9uIiwiem9vbUxpbmsiOiJodHRwczovL3VzMDZ3ZWIuem9vbS51cy9qLzEyMzQ1Njc4OTEwIiwiZGF0ZSI6IjIwMjItMTAtMDVUMTc6MDA6MDAuMDAwWiIsIm5hbWUiOiJGaXJzdE5hbWUiLCJsb2NhdGlvblR5cGUiOiJIWUJSSUQiLCJsb2NhdGlvbiI6eyJuYW1lIjoiU29tZSBQbGFjZSIsImxpbmUxIjoiMTIzIE1haW4gU3QiLCJjaXR5U3RhdGVaaXAiOiJDaXR5LCBTdGF0ZSAxMjM0NSIsIm1hcFVybCI6Imh0dHBzOi8vd3d3Lmdvb2dsZS5jb20vbWFwcy9wbGFjZS8xMjMrTWFpbitTdCwrQ2l0eSwrU3RhdGUrMTIzNDUifX0=")
+		decoded, err := decodeRendererParams("eyJ0ZW1wbGF0ZSI6IkluZm9TZXNzaW9uIiwiem9vbUxpbmsiOiJodHRwczovL3VzMDZ3ZWIuem9vbS51cy9qLzEyMzQ1Njc4OTEwIiwiZGF0ZSI6IjIwMjItMTAtMDVUMTc6MDA6MDAuMDAwWiIsIm5hbWUiOiJGaXJzdE5hbWUiLCJsb2NhdGlvblR5cGUiOiJIWUJSSUQiLCJsb2NhdGlvbiI6eyJuYW1lIjoiU29tZSBQbGFjZSIsImxpbmUxIjoiMTIzIE1haW4gU3QiLCJjaXR5U3RhdGVaaXAiOiJDaXR5LCBTdGF0ZSAxMjM0NSIsIm1hcFVybCI6Imh0dHBzOi8vd3d3Lmdvb2dsZS5jb20vbWFwcy9wbGFjZS8xMjMrTWFpbitTdCwrQ2l0eSwrU3RhdGUrMTIzNDUifX0=")
 
 		if err != nil {
 			t.Fatal(err)
 		}
+		params, ok := decoded.(*infoSessionParams)
+		require.True(t, ok)
 
 		assertEqual(t, params.Name, "FirstName")
 		assertEqual(t, params.Template, InfoSessionTemplate)
@@ -641,7 +690,7 @@ func TestShortMessagingURL(t *testing.T) {
 		wantURLPrefix := "https://sms.operationspark.org/m/"
 
 		// method under test
-		gotURL, err := s.shortMessagingURL("https://greenlight.operationspark.org", "https://sms.operationspark.org")
+		gotURL, err := s.shortMessagingURL(InfoSessionTemplate, "https://greenlight.example.org", "https://sms.operationspark.org")
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -656,11 +705,12 @@ func TestShortMessagingURL(t *testing.T) {
 		encoded := strings.TrimPrefix(gotURL, wantURLPrefix)
 
 		// decode the params
-		var gotParams rendererReqParams
-		err = gotParams.fromBase64(encoded)
+		decoded, err := decodeRendererParams(encoded)
 		if err != nil {
 			t.Fatal(err)
 		}
+		gotParams, ok := decoded.(*infoSessionParams)
+		require.True(t, ok)
 
 		assertEqual(t, gotParams.Name, s.NameFirst)
 		assertEqual(t, gotParams.Date.Equal(s.StartDateTime), true)
@@ -673,7 +723,8 @@ func TestShortMessagingURL(t *testing.T) {
 		assertEqual(t, gotParams.Location.MapURL, "https://www.google.com/maps/place/2723+Guess+Rd%2CDurham%2C+NC+27705")
 		// should be true because "gmail.com" should be the signup's email address domain
 		assertEqual(t, gotParams.IsGmail, true)
-		assertEqual(t, gotParams.GreenlightURL, "https://greenlight.operationspark.org/sessions/WpkB3jcw6gCw2uEMf/?subview=overview&userJoinCode=6421ecaa903dc77763e51829&joinCode=hqy0")
+		assertEqual(t, gotParams.GreenlightURL, "https://greenlight.example.org/sessions/WpkB3jcw6gCw2uEMf/?subview=overview&userJoinCode=6421ecaa903dc77763e51829&joinCode=hqy0")
+		assertEqual(t, gotParams.Language, i18n.English)
 
 	})
 }
@@ -715,10 +766,12 @@ func TestCreateMessageURL(t *testing.T) {
 		require.NoError(t, err)
 
 		// Unmarshal the decoded JSON into a messaging request params struct
-		var params rendererReqParams
+		var params reminderParams
 		err = json.NewDecoder(bytes.NewReader(decodedJSON)).Decode(&params)
 		require.NoError(t, err)
 
+		// Reminders link to the Info Session page unless the program sets its own reminder page.
+		require.Equal(t, InfoSessionTemplate, params.Template)
 		// Verify the location data matches the input from the Participant
 		require.Equal(t, "HYBRID", params.LocationType)
 		require.Equal(t, osLoc, params.Location)
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strings"
+	"time"
 )
 
 type slackService struct {
@@ -15,32 +17,228 @@ type slackService struct {
 	// Can be found on the App's Incoming Webhooks page.
 	// https://api.slack.com/apps/A0338E8UFFV/incoming-webhooks?
 	webhookURL string
+	// Base URL for links to Greenlight sessions. Defaults to production Greenlight.
+	greenlightHost string
 }
 
+type slackOption func(*slackService)
+
 func (sl slackService) run(ctx context.Context, su *Signup, logger *slog.Logger) error {
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
-	return sendWebhook(ctx, sl.webhookURL, message{Text: su.Summary()})
+	blocks, err := signupBlocks(*su, sl.greenlightHost)
+	if err != nil {
+		return fmt.Errorf("signupBlocks: %w", err)
+	}
+	// Text is the fallback for notifications and clients that can't show blocks.
+	return sendWebhook(ctx, sl.webhookURL, message{Text: su.Summary(), Blocks: blocks})
 }
 
 func (sl slackService) name() string {
 	return "slack service"
 }
 
-func NewSlackService(webhookURL string) *slackService {
-	return &slackService{
-		webhookURL: webhookURL,
+func (sl slackService) spec() taskSpec {
+	return taskSpec{key: taskSlack, integration: "slack"}
+}
+
+func NewSlackService(webhookURL string, opts ...slackOption) *slackService {
+	sl := &slackService{
+		webhookURL:     webhookURL,
+		greenlightHost: greenlightBaseURL,
+	}
+	for _, opt := range opts {
+		opt(sl)
+	}
+	return sl
+}
+
+// WithGreenlightHost links signup messages to a different Greenlight (Ex: staging, local).
+func WithGreenlightHost(host string) slackOption {
+	return func(sl *slackService) {
+		if host != "" {
+			sl.greenlightHost = strings.TrimSuffix(host, "/")
+		}
 	}
 }
 
+// Notify posts a plain text message to the #signups channel.
+func (sl slackService) Notify(ctx context.Context, text string) error {
+	return sendWebhook(ctx, sl.webhookURL, message{Text: text})
+}
+
 // IsRequired returns false because the slack message notification is just nice to have.
 func (sl slackService) isRequired() bool {
 	return false
 }
 
-type message struct {
-	Text string `json:"text"`
+type (
+	// Message is a Slack message. Blocks are optional.
+	// https://api.slack.com/reference/block-kit/blocks
+	message struct {
+		Text   string       `json:"text"`
+		Blocks []slackBlock `json:"blocks,omitempty"`
+		// Only used when responding to an interaction through its response_url.
+		ReplaceOriginal bool   `json:"replace_original,omitempty"`
+		ResponseType    string `json:"response_type,omitempty"`
+	}
+
+	slackBlock struct {
+		Type     string         `json:"type"`
+		BlockID  string         `json:"block_id,omitempty"`
+		Text     *slackText     `json:"text,omitempty"`
+		Fields   []slackText    `json:"fields,omitempty"`
+		Elements []slackElement `json:"elements,omitempty"`
+	}
+
+	// SlackText is a Block Kit text object. Type is "plain_text" or "mrkdwn".
+	slackText struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+
+	// SlackElement is a Block Kit button, or a text object in a context block.
+	slackElement struct {
+		Type     string        `json:"type"`
+		Text     any           `json:"text,omitempty"`
+		ActionID string        `json:"action_id,omitempty"`
+		Value    string        `json:"value,omitempty"`
+		Style    string        `json:"style,omitempty"`
+		Confirm  *slackConfirm `json:"confirm,omitempty"`
+	}
+
+	slackConfirm struct {
+		Title   slackText `json:"title"`
+		Text    slackText `json:"text"`
+		Confirm slackText `json:"confirm"`
+		Deny    slackText `json:"deny"`
+		Style   string    `json:"style,omitempty"`
+	}
+)
+
+// Slack button action IDs. Handled by slackActionServer.
+const (
+	actionResendConfirmation = "resend_confirmation"
+	actionMarkSpam           = "mark_spam"
+	actionCancelSignup       = "cancel_signup"
+)
+
+// Block ID of the signup action buttons. Removed once the signup is marked as spam or canceled.
+const signupActionsBlockID = "signup_actions"
+
+// Production Greenlight. Used for links when GREENLIGHT_HOST is not set.
+const greenlightBaseURL = "https://greenlight.operationspark.org"
+
+// SignupBlocks creates a Block Kit message with the signup's details and links to the given Greenlight host. Action buttons are included if the signup was saved.
+func signupBlocks(su Signup, greenlightHost string) ([]slackBlock, error) {
+	ctz, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		return nil, fmt.Errorf("loadLocation: %w", err)
+	}
+
+	title := "New Info Session Signup"
+	session := "None of these fit my schedule"
+	if !su.StartDateTime.IsZero() {
+		session = fmt.Sprintf("%s (%s)", su.StartDateTime.In(ctz).Format("Mon Jan 02 @ 3:04 PM MST"), slackEscape(su.Cohort))
+	} else {
+		title = "New Info Session Request"
+	}
+
+	location := orDash(su.LocationType)
+	if su.AttendingLocation != "" {
+		location = fmt.Sprintf("%s (attending %s)", location, su.AttendingLocation)
+	}
+
+	referrer := orDash(su.Referrer)
+	if su.ReferrerResponse != "" {
+		referrer = fmt.Sprintf("%s: %s", referrer, su.ReferrerResponse)
+	}
+
+	smsOptIn := "No"
+	if su.SMSOptIn {
+		smsOptIn = "Yes"
+	}
+
+	blocks := []slackBlock{
+		{Type: "header", Text: &slackText{Type: "plain_text", Text: title}},
+		{Type: "section", Text: mrkdwn(fmt.Sprintf("*%s %s*", slackEscape(su.NameFirst), slackEscape(su.NameLast)))},
+		{Type: "section", Fields: []slackText{
+			*mrkdwn("*Session:*\n" + session),
+			*mrkdwn("*Location:*\n" + slackEscape(location)),
+			*mrkdwn("*Email:*\n" + slackEscape(su.Email)),
+			*mrkdwn("*Phone:*\n" + slackEscape(su.Cell)),
+			*mrkdwn("*Referrer:*\n" + slackEscape(referrer)),
+			*mrkdwn("*SMS Opt-in:*\n" + smsOptIn),
+		}},
+	}
+
+	var links []string
+	if url := su.ZoomMeetingURL(); url != "" {
+		links = append(links, fmt.Sprintf("<%s|Zoom>", url))
+	}
+	if su.SessionID != "" {
+		links = append(links, fmt.Sprintf("<%s/sessions/%s|Greenlight Session>", greenlightHost, su.SessionID))
+	}
+	if su.ShortLink != "" {
+		links = append(links, fmt.Sprintf("<%s|Info Page>", su.ShortLink))
+	}
+	if len(links) > 0 {
+		blocks = append(blocks, slackBlock{Type: "section", Text: mrkdwn(strings.Join(links, " | "))})
+	}
+
+	if su.recordID != "" {
+		blocks = append(blocks, slackBlock{
+			Type:    "actions",
+			BlockID: signupActionsBlockID,
+			Elements: []slackElement{
+				button(actionResendConfirmation, "Resend Confirmation", su.recordID, "primary", nil),
+				button(actionMarkSpam, "Mark as Spam", su.recordID, "danger", &slackConfirm{
+					Title:   slackText{Type: "plain_text", Text: "Mark as spam?"},
+					Text:    *mrkdwn("The signup will be flagged as spam."),
+					Confirm: slackText{Type: "plain_text", Text: "Mark as Spam"},
+					Deny:    slackText{Type: "plain_text", Text: "Never mind"},
+					Style:   "danger",
+				}),
+				button(actionCancelSignup, "Cancel Signup", su.recordID, "danger", &slackConfirm{
+					Title:   slackText{Type: "plain_text", Text: "Cancel signup?"},
+					Text:    *mrkdwn("The signup will be canceled."),
+					Confirm: slackText{Type: "plain_text", Text: "Cancel Signup"},
+					Deny:    slackText{Type: "plain_text", Text: "Never mind"},
+					Style:   "danger",
+				}),
+			},
+		})
+	}
+	return blocks, nil
+}
+
+func mrkdwn(text string) *slackText {
+	return &slackText{Type: "mrkdwn", Text: text}
+}
+
+func button(actionID, text, value, style string, confirm *slackConfirm) slackElement {
+	return slackElement{
+		Type:     "button",
+		Text:     slackText{Type: "plain_text", Text: text},
+		ActionID: actionID,
+		Value:    value,
+		Style:    style,
+		Confirm:  confirm,
+	}
+}
+
+// SlackEscape escapes the characters Slack uses for formatting links and mentions.
+// https://api.slack.com/reference/surfaces/formatting#escaping
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
 }
 
 // SendWebhook POSTs a message to the OS Signups Slack App webhook.
@@ -65,7 +263,7 @@ func sendWebhook(ctx context.Context, url string, msg message) error {
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode >= 300 {
-		return handleHTTPError(resp)
+		return newHTTPError("slack", resp)
 	}
 
 	return nil
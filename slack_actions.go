@@ -0,0 +1,232 @@
+package signup
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/operationspark/service-signup/logging"
+	"github.com/operationspark/service-signup/signing"
+)
+
+type (
+	// signupActioner is the part of the signup service Slack buttons call back into.
+	signupActioner interface {
+		resendConfirmation(ctx context.Context, id string, logger *slog.Logger) (Signup, error)
+		markSpam(ctx context.Context, id string) (Signup, error)
+		cancel(ctx context.Context, id string) (Signup, error)
+	}
+
+	// slackActionServer handles Slack interactivity requests from the signup message buttons.
+	// https://api.slack.com/interactivity/handling
+	slackActionServer struct {
+		// Slack App signing secret. Found on the App's Basic Information page.
+		signingSecret string
+		service       signupActioner
+		logger        *slog.Logger
+		// Actions still running after their request was acknowledged.
+		pending sync.WaitGroup
+	}
+
+	// slackActionPayload is the subset of the "block_actions" payload we use.
+	// https://api.slack.com/reference/interaction-payloads/block-actions
+	slackActionPayload struct {
+		Type string `json:"type"`
+		User struct {
+			ID       string `json:"id"`
+			Username string `json:"username"`
+		} `json:"user"`
+		Actions []struct {
+			ActionID string `json:"action_id"`
+			Value    string `json:"value"`
+		} `json:"actions"`
+		ResponseURL string `json:"response_url"`
+		Message     struct {
+			Text   string       `json:"text"`
+			Blocks []slackBlock `json:"blocks"`
+		} `json:"message"`
+	}
+)
+
+const (
+	// Requests with a timestamp older than this are rejected to prevent replay attacks.
+	maxSlackRequestAge = 5 * time.Minute
+	// Actions run after Slack's 3 second acknowledgement deadline, so they get their own timeout.
+	slackActionTimeout = 2 * time.Minute
+)
+
+func NewSlackActionServer(signingSecret string, service signupActioner, logger *slog.Logger) *slackActionServer {
+	return &slackActionServer{
+		signingSecret: signingSecret,
+		service:       service,
+		logger:        logger.With("service", "slack-actions"),
+	}
+}
+
+func (s *slackActionServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	defer func() { _ = r.Body.Close() }()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "could not read body", http.StatusBadRequest)
+		return
+	}
+
+	err = s.verifySignature(r.Header.Get("X-Slack-Request-Timestamp"), r.Header.Get("X-Slack-Signature"), body, time.Now())
+	if err != nil {
+		s.logError(r.Context(), fmt.Errorf("verifySignature: %w", err))
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "invalid form body", http.StatusBadRequest)
+		return
+	}
+
+	var payload slackActionPayload
+	if err := json.Unmarshal([]byte(form.Get("payload")), &payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	if payload.Type != "block_actions" || len(payload.Actions) == 0 {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Slack shows an error if the request is not acknowledged within 3 seconds, and resending a confirmation can take longer. The action runs after the acknowledgement and posts its result to the response_url.
+	// Work after the response needs the instance to keep its CPU allocated (Cloud Run's --no-cpu-throttling); otherwise the action can stall until the next request.
+	ctx := context.WithoutCancel(r.Context())
+	s.pending.Add(1)
+	go func() {
+		defer s.pending.Done()
+		ctx, cancel := context.WithTimeout(ctx, slackActionTimeout)
+		defer cancel()
+
+		resp := s.handleAction(ctx, payload)
+		if err := sendWebhook(ctx, payload.ResponseURL, resp); err != nil {
+			s.logError(ctx, fmt.Errorf("respond to slack action: %w", err))
+		}
+	}()
+	w.WriteHeader(http.StatusOK)
+}
+
+// Wait blocks until the acknowledged actions have finished or ctx is done.
+func (s *slackActionServer) wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("wait: %w", ctx.Err())
+	}
+}
+
+// VerifySignature checks the request was sent by Slack. The signature is the hex-encoded HMAC-SHA256 of "v0:[timestamp]:[body]", signed with the App's signing secret.
+// https://api.slack.com/authentication/verifying-requests-from-slack
+func (s *slackActionServer) verifySignature(timestamp, signature string, body []byte, now time.Time) error {
+	if s.signingSecret == "" {
+		return errors.New("slack signing secret is not configured")
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("parse timestamp: %w", err)
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > maxSlackRequestAge || age < -maxSlackRequestAge {
+		return fmt.Errorf("stale timestamp: %s", timestamp)
+	}
+
+	base := fmt.Sprintf("v0:%s:%s", timestamp, body)
+	want, err := signing.Sign([]byte(base), []byte(s.signingSecret), crypto.SHA256, signing.EncodingHex)
+	if err != nil {
+		return fmt.Errorf("sign: %w", err)
+	}
+	// Slack prefixes the signature with the version ("v0=") instead of the algorithm.
+	if len(signature) < 3 || !hmac.Equal(want, []byte("sha256="+signature[3:])) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+// HandleAction runs the clicked button's action and returns the message to post back to the channel.
+// Marking a signup as spam or canceling it replaces the original message without the buttons.
+func (s *slackActionServer) handleAction(ctx context.Context, p slackActionPayload) message {
+	action := p.Actions[0]
+	logger := s.logger.With(
+		slog.String("action", action.ActionID),
+		slog.String("signupRecordId", action.Value),
+		slog.String("slackUser", p.User.Username),
+	)
+
+	var (
+		su     Signup
+		err    error
+		done   string
+		remove bool
+	)
+	switch action.ActionID {
+	case actionResendConfirmation:
+		su, err = s.service.resendConfirmation(ctx, action.Value, logger)
+		done = "resent the confirmation to"
+	case actionMarkSpam:
+		su, err = s.service.markSpam(ctx, action.Value)
+		done, remove = "marked as spam the signup for", true
+	case actionCancelSignup:
+		su, err = s.service.cancel(ctx, action.Value)
+		done, remove = "canceled the signup for", true
+	default:
+		err = fmt.Errorf("unknown action: %q", action.ActionID)
+	}
+
+	if err != nil {
+		s.logError(ctx, fmt.Errorf("%s: %w", action.ActionID, err))
+		return message{
+			ResponseType: "ephemeral",
+			Text:         fmt.Sprintf(":warning: Could not %s: %s", action.ActionID, err),
+		}
+	}
+
+	logger.InfoContext(ctx, "slack action completed")
+	note := fmt.Sprintf("<@%s> %s %s %s", p.User.ID, done, slackEscape(su.NameFirst), slackEscape(su.NameLast))
+	if !remove {
+		return message{ResponseType: "in_channel", Text: note}
+	}
+
+	blocks := make([]slackBlock, 0, len(p.Message.Blocks)+1)
+	for _, b := range p.Message.Blocks {
+		if b.BlockID != signupActionsBlockID {
+			blocks = append(blocks, b)
+		}
+	}
+	blocks = append(blocks, slackBlock{
+		Type:     "context",
+		Elements: []slackElement{{Type: "mrkdwn", Text: note}},
+	})
+	return message{ReplaceOriginal: true, Text: p.Message.Text, Blocks: blocks}
+}
+
+func (s *slackActionServer) logError(ctx context.Context, err error) {
+	logging.Error(ctx, s.logger, err)
+}
@@ -0,0 +1,150 @@
+package signup
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/operationspark/service-signup/signing"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSignupActioner struct {
+	calls []string
+	// Resending the confirmation blocks until release is closed, if set.
+	release chan struct{}
+}
+
+func (m *mockSignupActioner) resendConfirmation(ctx context.Context, id string, logger *slog.Logger) (Signup, error) {
+	if m.release != nil {
+		<-m.release
+	}
+	m.calls = append(m.calls, actionResendConfirmation+":"+id)
+	return Signup{NameFirst: "Henri", NameLast: "Testaroni"}, nil
+}
+
+func (m *mockSignupActioner) markSpam(ctx context.Context, id string) (Signup, error) {
+	m.calls = append(m.calls, actionMarkSpam+":"+id)
+	return Signup{NameFirst: "Henri", NameLast: "Testaroni"}, nil
+}
+
+func (m *mockSignupActioner) cancel(ctx context.Context, id string) (Signup, error) {
+	m.calls = append(m.calls, actionCancelSignup+":"+id)
+	return Signup{NameFirst: "Henri", NameLast: "Testaroni"}, nil
+}
+
+func TestSlackActionServer(t *testing.T) {
+	secret := "slack-signing-secret"
+
+	// Captures the message posted back to the response_url.
+	newResponseServer := func(t *testing.T) (*httptest.Server, *message) {
+		var got message
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		}))
+		t.Cleanup(srv.Close)
+		return srv, &got
+	}
+
+	t.Run("resends the confirmation", func(t *testing.T) {
+		respSrv, got := newResponseServer(t)
+		actioner := &mockSignupActioner{}
+		s := NewSlackActionServer(secret, actioner, slog.Default())
+
+		res := httptest.NewRecorder()
+		s.ServeHTTP(res, slackActionRequest(t, secret, actionResendConfirmation, respSrv.URL))
+		s.pending.Wait()
+
+		require.Equal(t, http.StatusOK, res.Code)
+		require.Equal(t, []string{actionResendConfirmation + ":65f1c0ffee"}, actioner.calls)
+		require.Equal(t, "in_channel", got.ResponseType)
+		require.Contains(t, got.Text, "resent the confirmation to Henri Testaroni")
+	})
+
+	t.Run("removes the buttons after marking as spam", func(t *testing.T) {
+		respSrv, got := newResponseServer(t)
+		actioner := &mockSignupActioner{}
+		s := NewSlackActionServer(secret, actioner, slog.Default())
+
+		res := httptest.NewRecorder()
+		s.ServeHTTP(res, slackActionRequest(t, secret, actionMarkSpam, respSrv.URL))
+		s.pending.Wait()
+
+		require.Equal(t, http.StatusOK, res.Code)
+		require.Equal(t, []string{actionMarkSpam + ":65f1c0ffee"}, actioner.calls)
+		require.True(t, got.ReplaceOriginal)
+		for _, b := range got.Blocks {
+			require.NotEqual(t, signupActionsBlockID, b.BlockID)
+		}
+		require.Equal(t, "context", got.Blocks[len(got.Blocks)-1].Type)
+	})
+
+	t.Run("acknowledges the request before the action finishes", func(t *testing.T) {
+		respSrv, got := newResponseServer(t)
+		actioner := &mockSignupActioner{release: make(chan struct{})}
+		s := NewSlackActionServer(secret, actioner, slog.Default())
+
+		res := httptest.NewRecorder()
+		s.ServeHTTP(res, slackActionRequest(t, secret, actionResendConfirmation, respSrv.URL))
+
+		require.Equal(t, http.StatusOK, res.Code)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, s.wait(ctx), context.DeadlineExceeded, "the action is still running")
+
+		close(actioner.release)
+		require.NoError(t, s.wait(context.Background()))
+		require.Equal(t, []string{actionResendConfirmation + ":65f1c0ffee"}, actioner.calls)
+		require.Contains(t, got.Text, "resent the confirmation to Henri Testaroni")
+	})
+
+	t.Run("rejects invalid signatures", func(t *testing.T) {
+		actioner := &mockSignupActioner{}
+		s := NewSlackActionServer(secret, actioner, slog.Default())
+
+		res := httptest.NewRecorder()
+		s.ServeHTTP(res, slackActionRequest(t, "wrong-secret", actionCancelSignup, "http://localhost"))
+
+		require.Equal(t, http.StatusUnauthorized, res.Code)
+		require.Empty(t, actioner.calls)
+	})
+}
+
+// SlackActionRequest creates a Slack interactivity request for the action, signed with the secret.
+func slackActionRequest(t *testing.T, secret, actionID, responseURL string) *http.Request {
+	t.Helper()
+
+	payload, err := json.Marshal(map[string]any{
+		"type":         "block_actions",
+		"user":         map[string]any{"id": "U123", "username": "halle"},
+		"actions":      []map[string]any{{"action_id": actionID, "value": "65f1c0ffee"}},
+		"response_url": responseURL,
+		"message": map[string]any{
+			"text": "Henri Testaroni has signed up",
+			"blocks": []map[string]any{
+				{"type": "section", "text": map[string]any{"type": "mrkdwn", "text": "*Henri Testaroni*"}},
+				{"type": "actions", "block_id": signupActionsBlockID},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	body := url.Values{"payload": {string(payload)}}.Encode()
+	timestamp := fmt.Sprint(time.Now().Unix())
+	sig, err := signing.Sign([]byte("v0:"+timestamp+":"+body), []byte(secret), crypto.SHA256, signing.EncodingHex)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/slack/actions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", "v0="+strings.TrimPrefix(string(sig), "sha256="))
+	return req
+}
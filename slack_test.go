@@ -5,7 +5,11 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
 )
 
 func TestSendWebhook(t *testing.T) {
@@ -28,3 +32,59 @@ func TestSendWebhook(t *testing.T) {
 		}
 	})
 }
+
+func TestSignupBlocks(t *testing.T) {
+	su := Signup{
+		NameFirst:         "Henri",
+		NameLast:          "<Testaroni>",
+		Email:             "henri@email.com",
+		Cell:              "555-123-4567",
+		Cohort:            "is-oct-31-22-12pm",
+		LocationType:      "HYBRID",
+		AttendingLocation: "IN_PERSON",
+		Referrer:          "instagram",
+		SMSOptIn:          true,
+		SessionID:         "WpkB3jcw6gCw2uEMf",
+		ShortLink:         "https://oprk.org/kRds5MKvKI",
+		StartDateTime:     mustMakeTime(t, time.RFC3339, "2022-10-31T17:00:00Z"),
+		zoomMeetingURL:    "https://us06web.zoom.us/j/123",
+	}
+
+	t.Run("includes the signup details and links", func(t *testing.T) {
+		blocks, err := signupBlocks(su, "https://greenlight.example.org")
+		require.NoError(t, err)
+
+		var b strings.Builder
+		for _, block := range blocks {
+			if block.Text != nil {
+				b.WriteString(block.Text.Text + "\n")
+			}
+			for _, f := range block.Fields {
+				b.WriteString(f.Text + "\n")
+			}
+		}
+		got := b.String()
+
+		require.Contains(t, got, "Henri &lt;Testaroni&gt;")
+		require.Contains(t, got, "Mon Oct 31 @ 12:00 PM CDT (is-oct-31-22-12pm)")
+		require.Contains(t, got, "HYBRID (attending IN_PERSON)")
+		require.Contains(t, got, "*SMS Opt-in:*\nYes")
+		require.Contains(t, got, "<https://us06web.zoom.us/j/123|Zoom>")
+		require.Contains(t, got, "https://greenlight.example.org/sessions/WpkB3jcw6gCw2uEMf")
+		require.NotContains(t, got, actionResendConfirmation, "buttons need a saved signup record")
+	})
+
+	t.Run("includes action buttons for saved signups", func(t *testing.T) {
+		saved := su
+		saved.recordID = "65f1c0ffee"
+		blocks, err := signupBlocks(saved, "https://greenlight.example.org")
+		require.NoError(t, err)
+
+		actions := blocks[len(blocks)-1]
+		require.Equal(t, "actions", actions.Type)
+		require.Len(t, actions.Elements, 3)
+		for _, e := range actions.Elements {
+			require.Equal(t, "65f1c0ffee", e.Value)
+		}
+	})
+}
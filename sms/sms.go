@@ -0,0 +1,159 @@
+// Package sms provides throttling and quiet-hours scheduling for outbound SMS messages.
+package sms
+
+//lint:file-ignore SA1029 Our string context keys are unique to this package.
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+type (
+	// Limiter throttles outbound messages per sending phone number.
+	// A single Limiter should be shared by every service that sends SMS messages from the same number(s).
+	Limiter struct {
+		mu        sync.Mutex
+		perSecond rate.Limit
+		burst     int
+		limiters  map[string]*rate.Limiter
+	}
+
+	// QuietHours is a daily window, in the recipient's time zone (RecipientTZ), when non-urgent messages should not be sent.
+	// Ex: QuietHours{Start: 21, End: 8} holds messages from 9pm until 8am.
+	QuietHours struct {
+		// Hour of the day (0-23) quiet hours begin.
+		Start int
+		// Hour of the day (0-23) quiet hours end.
+		End int
+	}
+
+	// Message is an SMS message deferred until after quiet hours.
+	Message struct {
+		ID string
+		// Recipient phone number. Ex: "+15045551234".
+		To   string
+		Body string
+		// Earliest time the message can be sent.
+		SendAfter time.Time
+		CreatedAt time.Time
+	}
+
+	// Queue stores deferred messages until they are due.
+	Queue interface {
+		EnqueueSMS(ctx context.Context, msg Message) error
+		// DueSMS returns all queued messages with a SendAfter time before the given time.
+		DueSMS(ctx context.Context, now time.Time) ([]Message, error)
+		// DeleteSMS removes a message from the queue. It returns an error if the message is not queued.
+		DeleteSMS(ctx context.Context, id string) error
+	}
+
+	contextKey string
+)
+
+const (
+	contextKeyRecipientTZ contextKey = "recipient_tz"
+	contextKeyUrgent      contextKey = "urgent"
+)
+
+func (c contextKey) String() string {
+	return "sms__" + string(c)
+}
+
+// DefaultTZName is the time zone used when the recipient's time zone is unknown. Signups don't record a time zone, so every recipient is currently in DefaultTZName.
+const DefaultTZName = "America/Chicago"
+
+// NewLimiter creates a Limiter that allows perSecond messages per sending number, with bursts of up to burst messages.
+// Twilio 10DLC throughput is set per sending number, so each number gets its own token bucket.
+func NewLimiter(perSecond float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{
+		perSecond: rate.Limit(perSecond),
+		burst:     burst,
+		limiters:  map[string]*rate.Limiter{},
+	}
+}
+
+// Wait blocks until fromNum is allowed to send another message or the context is done.
+// A nil Limiter never blocks.
+func (l *Limiter) Wait(ctx context.Context, fromNum string) error {
+	if l == nil {
+		return ctx.Err()
+	}
+	return l.limiter(fromNum).Wait(ctx)
+}
+
+func (l *Limiter) limiter(fromNum string) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lim, ok := l.limiters[fromNum]
+	if !ok {
+		lim = rate.NewLimiter(l.perSecond, l.burst)
+		l.limiters[fromNum] = lim
+	}
+	return lim
+}
+
+// Enabled returns false if the quiet hours window is empty.
+func (q QuietHours) Enabled() bool {
+	return q.Start != q.End
+}
+
+// IsQuiet checks if the given time falls within quiet hours. The time should already be in the recipient's time zone.
+func (q QuietHours) IsQuiet(t time.Time) bool {
+	if !q.Enabled() {
+		return false
+	}
+	h := t.Hour()
+	// Window wraps past midnight. Ex: 21 -> 8
+	if q.Start > q.End {
+		return h >= q.Start || h < q.End
+	}
+	return h >= q.Start && h < q.End
+}
+
+// NextSendTime returns the end of the current quiet hours window, or t if t is not within quiet hours.
+func (q QuietHours) NextSendTime(t time.Time) time.Time {
+	if !q.IsQuiet(t) {
+		return t
+	}
+	end := time.Date(t.Year(), t.Month(), t.Day(), q.End, 0, 0, 0, t.Location())
+	if !end.After(t) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+// EndsAfter checks if the quiet hours window that now falls in ends after start. Messages about something starting before then can't wait for quiet hours to end.
+func (q QuietHours) EndsAfter(start, now time.Time) bool {
+	return start.Before(q.NextSendTime(now))
+}
+
+// WithRecipientTZ returns a copy of the context with the recipient's time zone attached.
+func WithRecipientTZ(ctx context.Context, tz *time.Location) context.Context {
+	return context.WithValue(ctx, contextKeyRecipientTZ.String(), tz)
+}
+
+// RecipientTZ returns the recipient's time zone from the context, falling back to DefaultTZName.
+func RecipientTZ(ctx context.Context) (*time.Location, error) {
+	if tz, ok := ctx.Value(contextKeyRecipientTZ.String()).(*time.Location); ok && tz != nil {
+		return tz, nil
+	}
+	return time.LoadLocation(DefaultTZName)
+}
+
+// WithUrgent marks messages sent with the returned context as urgent. Urgent messages ignore quiet hours.
+func WithUrgent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKeyUrgent.String(), true)
+}
+
+// IsUrgent checks if the context was marked with WithUrgent.
+func IsUrgent(ctx context.Context) bool {
+	urgent, _ := ctx.Value(contextKeyUrgent.String()).(bool)
+	return urgent
+}
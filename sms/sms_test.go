@@ -0,0 +1,116 @@
+package sms
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	t.Run("throttles messages from the same number", func(t *testing.T) {
+		l := NewLimiter(10, 1)
+		ctx := context.Background()
+
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			require.NoError(t, l.Wait(ctx, "+15045550000"))
+		}
+		// 1 burst token, then 2 more at 10/sec
+		require.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+	})
+
+	t.Run("each sending number has its own limit", func(t *testing.T) {
+		l := NewLimiter(0.1, 1)
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		require.NoError(t, l.Wait(ctx, "+15045550000"))
+		require.NoError(t, l.Wait(ctx, "+15045550001"))
+		// Next token for the first number is 10 seconds away
+		require.Error(t, l.Wait(ctx, "+15045550000"))
+	})
+
+	t.Run("nil limiter does not block", func(t *testing.T) {
+		var l *Limiter
+		require.NoError(t, l.Wait(context.Background(), "+15045550000"))
+	})
+}
+
+func TestQuietHours(t *testing.T) {
+	ctz, err := time.LoadLocation(DefaultTZName)
+	require.NoError(t, err)
+	q := QuietHours{Start: 21, End: 8}
+
+	tests := []struct {
+		name      string
+		t         time.Time
+		wantQuiet bool
+		wantNext  time.Time
+	}{
+		{
+			name:      "late night is quiet",
+			t:         time.Date(2023, 3, 14, 23, 30, 0, 0, ctz),
+			wantQuiet: true,
+			wantNext:  time.Date(2023, 3, 15, 8, 0, 0, 0, ctz),
+		},
+		{
+			name:      "early morning is quiet",
+			t:         time.Date(2023, 3, 14, 6, 0, 0, 0, ctz),
+			wantQuiet: true,
+			wantNext:  time.Date(2023, 3, 14, 8, 0, 0, 0, ctz),
+		},
+		{
+			name:      "afternoon is not quiet",
+			t:         time.Date(2023, 3, 14, 13, 0, 0, 0, ctz),
+			wantQuiet: false,
+			wantNext:  time.Date(2023, 3, 14, 13, 0, 0, 0, ctz),
+		},
+		{
+			name:      "quiet hours end on the hour",
+			t:         time.Date(2023, 3, 14, 8, 0, 0, 0, ctz),
+			wantQuiet: false,
+			wantNext:  time.Date(2023, 3, 14, 8, 0, 0, 0, ctz),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.wantQuiet, q.IsQuiet(tt.t))
+			require.True(t, tt.wantNext.Equal(q.NextSendTime(tt.t)), "got %s", q.NextSendTime(tt.t))
+		})
+	}
+
+	t.Run("ends after sessions starting before the window ends", func(t *testing.T) {
+		now := time.Date(2023, 3, 14, 6, 0, 0, 0, ctz)
+		require.True(t, q.EndsAfter(time.Date(2023, 3, 14, 7, 0, 0, 0, ctz), now))
+		require.False(t, q.EndsAfter(time.Date(2023, 3, 14, 12, 0, 0, 0, ctz), now))
+
+		afternoon := time.Date(2023, 3, 14, 13, 0, 0, 0, ctz)
+		require.False(t, q.EndsAfter(time.Date(2023, 3, 14, 14, 0, 0, 0, ctz), afternoon))
+	})
+
+	t.Run("disabled when start and end are equal", func(t *testing.T) {
+		require.False(t, QuietHours{}.IsQuiet(time.Date(2023, 3, 14, 0, 0, 0, 0, ctz)))
+	})
+}
+
+func TestContext(t *testing.T) {
+	t.Run("falls back to the default time zone", func(t *testing.T) {
+		tz, err := RecipientTZ(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, DefaultTZName, tz.String())
+	})
+
+	t.Run("reads the recipient time zone", func(t *testing.T) {
+		tz, err := RecipientTZ(WithRecipientTZ(context.Background(), time.UTC))
+		require.NoError(t, err)
+		require.Equal(t, time.UTC, tz)
+	})
+
+	t.Run("marks messages as urgent", func(t *testing.T) {
+		require.False(t, IsUrgent(context.Background()))
+		require.True(t, IsUrgent(WithUrgent(context.Background())))
+	})
+}
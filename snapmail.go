@@ -29,6 +29,13 @@ type Payload struct {
 	SessionID     string    `json:"sessionId"`
 	StartDateTime time.Time `json:"startDateTime,omitempty"`
 	Mobile        string    `json:"mobile"`
+	// Marketing attribution.
+	UTMSource   string `json:"utmSource,omitempty"`
+	UTMMedium   string `json:"utmMedium,omitempty"`
+	UTMCampaign string `json:"utmCampaign,omitempty"`
+	UTMTerm     string `json:"utmTerm,omitempty"`
+	UTMContent  string `json:"utmContent,omitempty"`
+	LandingPage string `json:"landingPage,omitempty"`
 }
 
 type signupEvent struct {
@@ -58,6 +65,10 @@ func (sm *SnapMail) name() string {
 	return "SNAP Mailer"
 }
 
+func (sm *SnapMail) spec() taskSpec {
+	return taskSpec{key: taskSnapMail, integration: "snapmail"}
+}
+
 // IsRequired returns false because the snapMail webhook data can be retrieved from elsewhere and is not required for most students.
 func (sm *SnapMail) isRequired() bool {
 	return false
@@ -78,6 +89,12 @@ func (sm *SnapMail) run(ctx context.Context, signup *Signup, logger *slog.Logger
 			SessionCohort: signup.Cohort,
 			StartDateTime: signup.StartDateTime,
 			Mobile:        signup.Cell,
+			UTMSource:     signup.UTMSource,
+			UTMMedium:     signup.UTMMedium,
+			UTMCampaign:   signup.UTMCampaign,
+			UTMTerm:       signup.UTMTerm,
+			UTMContent:    signup.UTMContent,
+			LandingPage:   signup.LandingPage,
 		},
 	}
 
@@ -105,7 +122,7 @@ func (sm *SnapMail) run(ctx context.Context, signup *Signup, logger *slog.Logger
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode >= 300 {
-		return handleHTTPError(resp)
+		return newHTTPError("snapmail", resp)
 	}
 	return nil
 }
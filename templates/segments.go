@@ -0,0 +1,56 @@
+package templates
+
+import (
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	// EncodingGSM7 is the default SMS alphabet. 160 characters fit in a single segment.
+	EncodingGSM7 = "GSM-7"
+	// EncodingUCS2 is used when a message contains any character outside the GSM-7 alphabet. 70 characters fit in a single segment.
+	EncodingUCS2 = "UCS-2"
+)
+
+// GSM 03.38 basic character set.
+const gsm7Basic = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+
+// GSM 03.38 extension characters. Each takes two septets.
+const gsm7Extended = "^{}\\[~]|€\f"
+
+// Encoding returns the SMS encoding needed for the message.
+func Encoding(msg string) string {
+	for _, r := range msg {
+		if !strings.ContainsRune(gsm7Basic, r) && !strings.ContainsRune(gsm7Extended, r) {
+			return EncodingUCS2
+		}
+	}
+	return EncodingGSM7
+}
+
+// Segments returns the number of SMS segments needed to send the message.
+// Multi-segment messages reserve part of each segment for a concatenation header, so they fit fewer characters per segment.
+func Segments(msg string) int {
+	if len(msg) == 0 {
+		return 0
+	}
+
+	var units, single, multi int
+	if Encoding(msg) == EncodingUCS2 {
+		units = len(utf16.Encode([]rune(msg)))
+		single, multi = 70, 67
+	} else {
+		for _, r := range msg {
+			units++
+			if strings.ContainsRune(gsm7Extended, r) {
+				units++
+			}
+		}
+		single, multi = 160, 153
+	}
+
+	if units <= single {
+		return 1
+	}
+	return (units + multi - 1) / multi
+}
@@ -0,0 +1,286 @@
+// Package templates renders participant-facing SMS messages from text/template templates.
+//
+// Default templates are embedded from the sms/ directory, one file per language and template: sms/[language]/[name].tmpl.
+// Templates from a directory with the same layout, or from a database, can override the defaults without a deploy.
+package templates
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/operationspark/service-signup/i18n"
+)
+
+type (
+	// Name identifies an SMS template.
+	Name string
+
+	// Template is the raw source of one SMS template in one language.
+	Template struct {
+		Name     Name          `json:"name" bson:"name"`
+		Language i18n.Language `json:"language" bson:"language"`
+		Body     string        `json:"body" bson:"body"`
+	}
+
+	// Source loads template overrides.
+	Source interface {
+		LoadTemplates(ctx context.Context) ([]Template, error)
+	}
+
+	// FSSource loads templates from a file system laid out as [language]/[name].tmpl.
+	FSSource struct {
+		FS fs.FS
+	}
+
+	// Registry holds the parsed templates for every language.
+	Registry struct {
+		templates map[i18n.Language]map[Name]*template.Template
+		// Maximum number of SMS segments a rendered message can use.
+		segmentBudget int
+	}
+
+	Options struct {
+		// Maximum number of SMS segments a rendered message can use. Defaults to DefaultSegmentBudget.
+		SegmentBudget int
+		// Sources are loaded in order after the embedded defaults. Later sources override earlier ones.
+		Sources []Source
+	}
+
+	// SignupData is passed to signup templates.
+	SignupData struct {
+		NameFirst string
+		// Zero if the person did not pick a session.
+		StartDateTime time.Time
+		// Info session details short link. Can be empty.
+		URL string
+	}
+
+	// ReminderData is passed to the reminder template.
+	ReminderData struct {
+		NameFirst   string
+		SessionDate time.Time
+		// True if the session is later today.
+		Today bool
+		// Info session details short link. Can be empty.
+		URL string
+	}
+
+	// SegmentBudgetError is returned when a rendered message uses more SMS segments than allowed.
+	SegmentBudgetError struct {
+		Name     Name
+		Segments int
+		Budget   int
+	}
+)
+
+const (
+	// Sent after signing up. Data: SignupData.
+	SignupConfirmation Name = "signup-confirmation"
+	// Sent when someone opts in to text messages. Data: none.
+	OptInConfirmation Name = "opt-in-confirmation"
+	// Info Session reminder. Data: ReminderData.
+	Reminder Name = "reminder"
+	// Sent instead of the welcome email when it hard bounces. Data: SignupData.
+	EmailBounced Name = "email-bounced"
+)
+
+// DefaultSegmentBudget allows a message to span up to 4 segments. Messages with non-GSM characters (Ex: "á") are encoded as UCS-2 and only fit 67 characters per segment.
+const DefaultSegmentBudget = 4
+
+// Names lists every SMS template.
+var Names = []Name{SignupConfirmation, OptInConfirmation, Reminder, EmailBounced}
+
+//go:embed sms/*/*.tmpl
+var embedded embed.FS
+
+var (
+	defaultOnce     sync.Once
+	defaultRegistry *Registry
+	defaultErr      error
+)
+
+func (e *SegmentBudgetError) Error() string {
+	return fmt.Sprintf("template %q renders %d SMS segments, over the budget of %d", e.Name, e.Segments, e.Budget)
+}
+
+// New creates a Registry from the embedded default templates, overridden by any templates from the given sources.
+func New(ctx context.Context, o Options) (*Registry, error) {
+	budget := DefaultSegmentBudget
+	if o.SegmentBudget > 0 {
+		budget = o.SegmentBudget
+	}
+
+	r := &Registry{
+		templates:     map[i18n.Language]map[Name]*template.Template{},
+		segmentBudget: budget,
+	}
+
+	sub, err := fs.Sub(embedded, "sms")
+	if err != nil {
+		return nil, fmt.Errorf("sub: %w", err)
+	}
+
+	sources := append([]Source{FSSource{FS: sub}}, o.Sources...)
+	for _, src := range sources {
+		tmpls, err := src.LoadTemplates(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("loadTemplates: %w", err)
+		}
+		for _, t := range tmpls {
+			if err := r.add(t); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Every template needs an English version to fall back to.
+	for _, name := range Names {
+		if _, ok := r.templates[i18n.English][name]; !ok {
+			return nil, fmt.Errorf("missing English %q template", name)
+		}
+	}
+	return r, nil
+}
+
+// Default returns a Registry with only the embedded templates.
+func Default() *Registry {
+	defaultOnce.Do(func() {
+		defaultRegistry, defaultErr = New(context.Background(), Options{})
+	})
+	if defaultErr != nil {
+		// The embedded templates are covered by tests, so this should never happen.
+		panic(fmt.Errorf("default templates: %w", defaultErr))
+	}
+	return defaultRegistry
+}
+
+// DirSource loads templates from a directory laid out as [language]/[name].tmpl.
+func DirSource(dir string) FSSource {
+	return FSSource{FS: os.DirFS(dir)}
+}
+
+// LoadTemplates reads every [language]/[name].tmpl file in the file system.
+func (s FSSource) LoadTemplates(ctx context.Context) ([]Template, error) {
+	paths, err := fs.Glob(s.FS, "*/*.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("glob: %w", err)
+	}
+	sort.Strings(paths)
+
+	tmpls := make([]Template, 0, len(paths))
+	for _, p := range paths {
+		body, err := fs.ReadFile(s.FS, p)
+		if err != nil {
+			return nil, fmt.Errorf("readFile %q: %w", p, err)
+		}
+		tmpls = append(tmpls, Template{
+			Name:     Name(strings.TrimSuffix(path.Base(p), ".tmpl")),
+			Language: i18n.Language(path.Dir(p)),
+			Body:     string(body),
+		})
+	}
+	return tmpls, nil
+}
+
+func (r *Registry) add(t Template) error {
+	lang := i18n.Parse(string(t.Language))
+	// Parse with placeholder funcs. The real funcs depend on the language and time zone at render time.
+	parsed, err := template.New(string(t.Name)).
+		Option("missingkey=error").
+		Funcs(funcs(lang, time.UTC)).
+		Parse(t.Body)
+	if err != nil {
+		return fmt.Errorf("parse %s/%s: %w", lang, t.Name, err)
+	}
+
+	if r.templates[lang] == nil {
+		r.templates[lang] = map[Name]*template.Template{}
+	}
+	r.templates[lang][t.Name] = parsed
+	return nil
+}
+
+// Execute renders the named template in the given language without checking the segment budget. Dates are formatted in the given time zone. Templates missing from a language fall back to English.
+func (r *Registry) Execute(name Name, lang i18n.Language, tz *time.Location, data any) (string, error) {
+	if r == nil {
+		r = Default()
+	}
+	lang = i18n.Parse(string(lang))
+
+	t, ok := r.templates[lang][name]
+	if !ok {
+		t, ok = r.templates[i18n.English][name]
+	}
+	if !ok {
+		return "", fmt.Errorf("template %q not found", name)
+	}
+
+	t, err := t.Clone()
+	if err != nil {
+		return "", fmt.Errorf("clone: %w", err)
+	}
+
+	var b strings.Builder
+	if err := t.Funcs(funcs(lang, tz)).Execute(&b, data); err != nil {
+		return "", fmt.Errorf("execute %q: %w", name, err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// Render renders the named template and returns a *SegmentBudgetError if the message does not fit within the segment budget.
+func (r *Registry) Render(name Name, lang i18n.Language, tz *time.Location, data any) (string, error) {
+	if r == nil {
+		r = Default()
+	}
+
+	msg, err := r.Execute(name, lang, tz, data)
+	if err != nil {
+		return "", err
+	}
+
+	if n := Segments(msg); n > r.segmentBudget {
+		return msg, &SegmentBudgetError{Name: name, Segments: n, Budget: r.segmentBudget}
+	}
+	return msg, nil
+}
+
+// SegmentBudget returns the maximum number of SMS segments a rendered message can use.
+func (r *Registry) SegmentBudget() int {
+	if r == nil {
+		return DefaultSegmentBudget
+	}
+	return r.segmentBudget
+}
+
+// IsSegmentBudgetError checks if the error is caused by a message over the segment budget.
+func IsSegmentBudgetError(err error) bool {
+	var budgetErr *SegmentBudgetError
+	return errors.As(err, &budgetErr)
+}
+
+func funcs(lang i18n.Language, tz *time.Location) template.FuncMap {
+	layout := func(key i18n.Key) func(time.Time) string {
+		return func(t time.Time) string {
+			return i18n.FormatTime(lang, t.In(tz), key)
+		}
+	}
+	return template.FuncMap{
+		"weekday":     layout(i18n.KeyLayoutWeekday),
+		"shortDate":   layout(i18n.KeyLayoutShortDate),
+		"longDate":    layout(i18n.KeyLayoutLongDate),
+		"numericDate": layout(i18n.KeyLayoutNumericDate),
+		"shortTime":   layout(i18n.KeyLayoutShortTime),
+		"time":        layout(i18n.KeyLayoutTime),
+		"compactTime": layout(i18n.KeyLayoutCompactTime),
+	}
+}
@@ -0,0 +1,125 @@
+package templates
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/operationspark/service-signup/i18n"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultTemplates(t *testing.T) {
+	ctz, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+	halloween := time.Date(2022, 10, 31, 17, 0, 0, 0, time.UTC)
+
+	t.Run("every template is translated", func(t *testing.T) {
+		r := Default()
+		for _, lang := range i18n.Languages {
+			for _, name := range Names {
+				require.Contains(t, r.templates[lang], name, "%q is missing a %q translation", name, lang)
+			}
+		}
+	})
+
+	t.Run("renders the signup confirmation", func(t *testing.T) {
+		got, err := Default().Render(SignupConfirmation, i18n.English, ctz, SignupData{
+			StartDateTime: halloween,
+			URL:           "https://oprk.org/kRds5MKvKI",
+		})
+		require.NoError(t, err)
+
+		want := `You've signed up for an info session with Operation Spark!
+The session is Mon Oct 31 @ 12:00p CDT.
+View this link for details:
+https://oprk.org/kRds5MKvKI`
+		require.Equal(t, want, got)
+	})
+
+	t.Run("refers to email when there is no link", func(t *testing.T) {
+		got, err := Default().Render(SignupConfirmation, i18n.English, ctz, SignupData{StartDateTime: halloween})
+		require.NoError(t, err)
+		require.True(t, strings.HasSuffix(got, "CDT.\nCheck your email for confirmation."), got)
+	})
+
+	t.Run("renders the reminder in Spanish", func(t *testing.T) {
+		got, err := Default().Render(Reminder, i18n.Spanish, ctz, ReminderData{
+			SessionDate: halloween,
+			URL:         "https://oprk.org/kRds5MKvKI",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "¡Hola de parte de Operation Spark! Te recordamos que tienes una Sesión Informativa de Introducción a la Programación lunes 31/10 a las 12:00PM CDT.\nMás detalles: https://oprk.org/kRds5MKvKI", got)
+	})
+
+	t.Run("renders the email bounce fallback", func(t *testing.T) {
+		got, err := Default().Render(EmailBounced, i18n.English, ctz, SignupData{
+			NameFirst:     "Henri",
+			StartDateTime: halloween,
+			URL:           "https://oprk.org/kRds5MKvKI",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "Hi Henri, we couldn't deliver your Operation Spark info session email.\nYour session is Mon Oct 31 @ 12:00p CDT.\nDetails: https://oprk.org/kRds5MKvKI", got)
+	})
+}
+
+func TestOverrides(t *testing.T) {
+	t.Run("later sources override the defaults", func(t *testing.T) {
+		src := FSSource{FS: fstest.MapFS{
+			"en/opt-in-confirmation.tmpl": {Data: []byte("Thanks for opting in! Reply STOP to unsubscribe.\n")},
+		}}
+
+		r, err := New(context.Background(), Options{Sources: []Source{src}})
+		require.NoError(t, err)
+
+		got, err := r.Render(OptInConfirmation, i18n.English, time.UTC, nil)
+		require.NoError(t, err)
+		require.Equal(t, "Thanks for opting in! Reply STOP to unsubscribe.", got)
+
+		// Other templates are untouched
+		got, err = r.Render(OptInConfirmation, i18n.Spanish, time.UTC, nil)
+		require.NoError(t, err)
+		require.Contains(t, got, "Te suscribiste")
+	})
+
+	t.Run("fails on invalid templates", func(t *testing.T) {
+		src := FSSource{FS: fstest.MapFS{
+			"en/reminder.tmpl": {Data: []byte("{{if .Today}")},
+		}}
+
+		_, err := New(context.Background(), Options{Sources: []Source{src}})
+		require.Error(t, err)
+	})
+
+	t.Run("returns an error when a message is over the segment budget", func(t *testing.T) {
+		r, err := New(context.Background(), Options{SegmentBudget: 1})
+		require.NoError(t, err)
+
+		_, err = r.Render(OptInConfirmation, i18n.English, time.UTC, nil)
+		require.True(t, IsSegmentBudgetError(err), "got %v", err)
+	})
+}
+
+func TestSegments(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  string
+		want int
+	}{
+		{"empty", "", 0},
+		{"single GSM-7 segment", strings.Repeat("a", 160), 1},
+		{"two GSM-7 segments", strings.Repeat("a", 161), 2},
+		{"extension characters take two septets", strings.Repeat("{", 81), 2},
+		{"single UCS-2 segment", strings.Repeat("á", 70), 1},
+		{"two UCS-2 segments", strings.Repeat("á", 71), 2},
+		{"GSM-7 accents", strings.Repeat("é", 160), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, Segments(tt.msg))
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package signup
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type (
+	requestIDKey struct{}
+
+	// requestIDTransport forwards the request ID to downstream services in the X-Request-ID header.
+	requestIDTransport struct {
+		base http.RoundTripper
+	}
+
+	// contextLogHandler adds the request ID and trace IDs from the context to every log record.
+	// Only the *Context logging methods (Ex: logger.InfoContext) have a context to read from.
+	contextLogHandler struct {
+		slog.Handler
+	}
+)
+
+const (
+	serviceName = "service-signup"
+	tracerName  = "github.com/operationspark/service-signup"
+
+	requestIDHeader = "X-Request-ID"
+)
+
+var tracer = otel.Tracer(tracerName)
+
+// SetupTracing sets the global OpenTelemetry tracer provider. The exporter is one of:
+//   - "otlp": OTLP over HTTP. Configured with the standard OTEL_EXPORTER_OTLP_* env vars.
+//   - "stdout": pretty-printed spans for local development.
+//   - "" or "none": spans are not exported, but trace context is still propagated.
+//
+// Outbound HTTP requests using the default transport are traced and forward the request ID. The returned function flushes any buffered spans.
+func setupTracing(ctx context.Context, exporterName string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	// Every integration client uses the default transport, either directly or through http.DefaultClient.
+	if _, ok := http.DefaultTransport.(*otelhttp.Transport); !ok {
+		http.DefaultTransport = otelhttp.NewTransport(requestIDTransport{base: http.DefaultTransport},
+			otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+				return fmt.Sprintf("%s %s", r.Method, r.URL.Host)
+			}),
+		)
+	}
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch exporterName {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "otlp":
+		exporter, err = otlptracehttp.New(ctx)
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unknown trace exporter: %q", exporterName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("new exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(getGitRev()),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// WithTracing starts a server span for each request, continuing the caller's trace if a "traceparent" header is sent, and sets the request ID.
+func withTracing(next http.Handler) http.Handler {
+	return otelhttp.NewHandler(withRequestID(next), serviceName,
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return fmt.Sprintf("%s %s", r.Method, r.URL.Path)
+		}),
+	)
+}
+
+// WithRequestID uses the request's X-Request-ID header as its request ID. Without one, the trace ID is used if the request is traced, or a random ID.
+// The ID is sent back in the X-Request-ID response header and added to the request context for logging.
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if id == "" {
+			if sc := trace.SpanContextFromContext(r.Context()); sc.HasTraceID() {
+				id = sc.TraceID().String()
+			} else {
+				id = newRequestID()
+			}
+		}
+
+		trace.SpanFromContext(r.Context()).SetAttributes(attribute.String("request.id", id))
+		w.Header().Set(requestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func (t requestIDTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	id := requestIDFromContext(r.Context())
+	if id == "" || r.Header.Get(requestIDHeader) != "" {
+		return t.base.RoundTrip(r)
+	}
+	// RoundTrippers must not modify the original request.
+	r = r.Clone(r.Context())
+	r.Header.Set(requestIDHeader, id)
+	return t.base.RoundTrip(r)
+}
+
+// RequestIDFromContext returns the request ID set by withRequestID, or "".
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func (h contextLogHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := requestIDFromContext(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextLogHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextLogHandler) WithGroup(name string) slog.Handler {
+	return contextLogHandler{h.Handler.WithGroup(name)}
+}
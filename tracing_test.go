@@ -0,0 +1,87 @@
+package signup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func TestWithTracing(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var gotID string
+	handler := withTracing(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotID = requestIDFromContext(r.Context())
+	}))
+
+	t.Run("uses the X-Request-ID header", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("X-Request-ID", "website-req-123")
+		res := httptest.NewRecorder()
+
+		handler.ServeHTTP(res, req)
+
+		require.Equal(t, "website-req-123", gotID)
+		require.Equal(t, "website-req-123", res.Header().Get("X-Request-ID"))
+	})
+
+	t.Run("uses the trace ID from the traceparent header", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		res := httptest.NewRecorder()
+
+		handler.ServeHTTP(res, req)
+
+		require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", gotID)
+	})
+
+	t.Run("generates an ID", func(t *testing.T) {
+		res := httptest.NewRecorder()
+
+		handler.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/", nil))
+
+		require.Len(t, gotID, 32)
+		require.Equal(t, gotID, res.Header().Get("X-Request-ID"))
+	})
+}
+
+func TestRequestIDTransport(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("X-Request-ID")
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: requestIDTransport{base: http.DefaultTransport}}
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-abc")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	require.Equal(t, "req-abc", got)
+	require.Empty(t, req.Header.Get("X-Request-ID"), "should not modify the original request")
+}
+
+func TestContextLogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(contextLogHandler{slog.NewJSONHandler(&buf, nil)}).With("service", "signup")
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-abc")
+	logger.InfoContext(ctx, "signed up")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	require.Equal(t, "req-abc", record["request_id"])
+	require.Equal(t, "signup", record["service"])
+}
@@ -2,12 +2,18 @@ package signup
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
-	"os"
+	"net/url"
+	"path"
 	"strings"
+	"time"
 
+	"github.com/operationspark/service-signup/i18n"
+	"github.com/operationspark/service-signup/sms"
+	"github.com/operationspark/service-signup/templates"
 	"github.com/twilio/twilio-go"
 	"github.com/twilio/twilio-go/client"
 	conversations "github.com/twilio/twilio-go/rest/conversations/v1"
@@ -19,18 +25,32 @@ type (
 		apiBase string
 		// Client for making requests to Twilio's API.
 		client *twilio.RestClient
+		// Twilio account the messages are sent from.
+		accountSID string
 		// Phone number SMS messages are sent from.
 		fromPhoneNum string
 		// API base for Operation Spark's SMS Messaging interface.
 		// This URL is used for sending webhooks on SMS events from this service.
 		// Default: https://messenger.operationspark.org
 		opSparkMessagingSvcBaseURL string
+		// API key for Operation Spark's SMS Messaging interface.
+		opSparkMessagingAPIKey string
 		// Twilio Conversation (Chat) Service ID.
 		// Ex: "IS00000000000000000000000000000000"
 		conversationsSid string
 		// Twilio Conversations Service User identity name.
 		// Ex: "services@operationspark.org"
 		conversationsIdentity string
+		// Throttles outbound messages per sending number. Shared with every other service sending SMS messages.
+		limiter *sms.Limiter
+		// Window in the recipient's time zone when non-urgent messages are queued instead of sent.
+		quietHours sms.QuietHours
+		// Holds messages deferred until after quiet hours. If nil, messages are always sent immediately.
+		queue sms.Queue
+		// SMS message templates. If nil, the embedded default templates are used.
+		templates *templates.Registry
+		// Confirmation templates by Greenlight program ID.
+		programs programs
 	}
 
 	// ErrInvalidNumber is an error type for invalid phone numbers.
@@ -49,19 +69,38 @@ type (
 		// This URL is used for sending webhooks on SMS events from this service.
 		// Default: https://messenger.operationspark.org
 		opSparkMessagingSvcBaseURL string
+		// API key for Operation Spark's SMS Messaging interface.
+		opSparkMessagingAPIKey string
 		// Twilio API base.
 		apiBase          string
 		conversationsSid string
 		// Twilio Conversations Service User identity name.
 		// Ex: "services@operationspark.org"
 		conversationsIdentity string
+		// Throttles outbound messages per sending number.
+		limiter *sms.Limiter
+		// Window in the recipient's time zone when non-urgent messages are queued instead of sent.
+		quietHours sms.QuietHours
+		// Holds messages deferred until after quiet hours.
+		queue sms.Queue
+		// SMS message templates. Defaults to the embedded templates.
+		templates *templates.Registry
+		// Confirmation templates by Greenlight program ID. Defaults to the Info Session templates.
+		programs programs
 	}
 )
 
+// Twilio error code for a phone number that can't be added to a conversation (invalid messaging binding address).
+const twilioInvalidPhoneCode = "50407"
+
 func (e ErrInvalidNumber) Error() string {
 	return e.err.Error()
 }
 
+func (e ErrInvalidNumber) Unwrap() error {
+	return e.err
+}
+
 func NewTwilioService(o twilioServiceOptions) *smsService {
 	messengerBaseURL := "https://messenger.operationspark.org"
 	if len(o.opSparkMessagingSvcBaseURL) > 0 {
@@ -70,8 +109,12 @@ func NewTwilioService(o twilioServiceOptions) *smsService {
 
 	// Override for testing
 	apiBase := "https://api.twilio.com"
+	restClient := o.client
 	if len(o.apiBase) > 0 {
 		apiBase = o.apiBase
+		if restClient == nil {
+			restClient = newAPIBaseClient(o.apiBase, o.accountSID, o.authToken)
+		}
 	}
 
 	conversationsIdentity := "services@operationspark.org"
@@ -84,13 +127,64 @@ func NewTwilioService(o twilioServiceOptions) *smsService {
 		client: twilio.NewRestClientWithParams(twilio.ClientParams{
 			Username: o.accountSID,
 			Password: o.authToken,
-			Client:   o.client,
+			Client:   restClient,
 		}),
+		accountSID:                 o.accountSID,
 		fromPhoneNum:               o.fromPhoneNum,
 		opSparkMessagingSvcBaseURL: messengerBaseURL,
+		opSparkMessagingAPIKey:     o.opSparkMessagingAPIKey,
 		conversationsSid:           o.conversationsSid,
 		conversationsIdentity:      conversationsIdentity,
+		limiter:                    o.limiter,
+		quietHours:                 o.quietHours,
+		queue:                      o.queue,
+		templates:                  o.templates,
+		programs:                   o.programs,
+	}
+}
+
+// NewAPIBaseClient creates a Twilio client that sends every request to the apiBase server instead of Twilio.
+func newAPIBaseClient(apiBase, accountSID, authToken string) *client.Client {
+	base, err := url.Parse(apiBase)
+	if err != nil {
+		// Requests fail with the parse error instead of reaching Twilio.
+		base = &url.URL{Scheme: "invalid", Host: apiBase}
+	}
+	c := &client.Client{
+		Credentials: client.NewCredentials(accountSID, authToken),
+		HTTPClient:  &http.Client{Transport: apiBaseTransport{base: base}},
+	}
+	c.SetAccountSid(accountSID)
+	return c
+}
+
+// APIBaseTransport rewrites Twilio API URLs to the base URL, keeping the Twilio product as the first path segment.
+// Ex: "https://conversations.twilio.com/v1/Services" -> "[base]/conversations/v1/Services".
+type apiBaseTransport struct {
+	base *url.URL
+}
+
+func (t apiBaseTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	product := strings.TrimSuffix(r.URL.Hostname(), ".twilio.com")
+	// RoundTrippers must not modify the original request.
+	r = r.Clone(r.Context())
+	r.URL.Scheme = t.base.Scheme
+	r.URL.Host = t.base.Host
+	r.URL.Path = path.Join(t.base.Path, product, r.URL.Path)
+	r.Host = ""
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+// CheckAccount returns an error if the Twilio account cannot be fetched with the service's credentials or is not active.
+func (t *smsService) checkAccount(ctx context.Context) error {
+	acct, err := t.client.Api.FetchAccount(t.accountSID)
+	if err != nil {
+		return fmt.Errorf("fetchAccount: %w", twilioError(err))
+	}
+	if acct.Status != nil && *acct.Status != "active" {
+		return fmt.Errorf("account is %s", *acct.Status)
 	}
+	return nil
 }
 
 // IsRequired returns false because we're now going to send the information URL back to the client in the response body. So if the SMS message fails to send, the user will still have the information URL.
@@ -124,10 +218,10 @@ func (t *smsService) run(ctx context.Context, su *Signup, logger *slog.Logger) e
 	if len(existing) == 0 {
 		convoID, err = t.addNumberToConversation(toNum, convoName)
 		if err != nil {
-			twilioInvalidPhoneCode := "50407"
 			// check if error is due to number being invalid, if so use the errInvalidNumber type
-			if strings.Contains(err.Error(), twilioInvalidPhoneCode) {
-				return ErrInvalidNumber{err: fmt.Errorf("invalid number: %s", toNum)}
+			var httpErr *HTTPError
+			if errors.As(err, &httpErr) && httpErr.Code == twilioInvalidPhoneCode {
+				return ErrInvalidNumber{err: fmt.Errorf("invalid number: %s: %w", toNum, err)}
 			}
 
 			return fmt.Errorf("addNumberToConversation: %w", err)
@@ -137,8 +231,17 @@ func (t *smsService) run(ctx context.Context, su *Signup, logger *slog.Logger) e
 		convoID = *existing[0].ConversationSid
 	}
 
+	// Confirmations for a session starting before quiet hours end can't wait until morning.
+	urgent, err := t.startsBeforeQuietHoursEnd(ctx, su.StartDateTime)
+	if err != nil {
+		return fmt.Errorf("startsBeforeQuietHoursEnd: %w", err)
+	}
+	if urgent {
+		ctx = sms.WithUrgent(ctx)
+	}
+
 	// Send Opt-in confirmation
-	if err := t.optInConfirmation(ctx, toNum); err != nil {
+	if err := t.optInConfirmation(ctx, toNum, su.lang()); err != nil {
 		return fmt.Errorf("optInConfirmation: %w", err)
 	}
 
@@ -147,14 +250,20 @@ func (t *smsService) run(ctx context.Context, su *Signup, logger *slog.Logger) e
 		return fmt.Errorf("shortLink is empty")
 	}
 	// Create the SMS message body
-	msg, err := su.shortMessage(su.ShortLink)
+	msg, err := su.shortMessage(t.templates, t.programs.get(su.ProgramID).confirmationTemplate, su.ShortLink)
 	if err != nil {
 		return fmt.Errorf("shortMessage: %w", err)
 	}
 
-	err = t.sendSMSInConversation(msg, convoID)
+	deferred, err := t.deferIfQuiet(ctx, toNum, msg)
 	if err != nil {
-		return fmt.Errorf("sendSMS: %w", err)
+		return fmt.Errorf("deferIfQuiet: %w", err)
+	}
+	if !deferred {
+		err = t.sendSMSInConversation(ctx, msg, convoID)
+		if err != nil {
+			return fmt.Errorf("sendSMS: %w", err)
+		}
 	}
 
 	err = t.sendConvoWebhook(ctx, convoID)
@@ -173,8 +282,23 @@ func (t *smsService) name() string {
 	return "twilio service"
 }
 
+// The confirmation text has the short link. The conversation is linked to the signup afterwards.
+func (t *smsService) spec() taskSpec {
+	return taskSpec{
+		key:         taskSMS,
+		consumes:    []signupField{fieldShortLink},
+		produces:    []signupField{fieldConversationID},
+		integration: "twilio",
+	}
+}
+
 // SendSMSInConversation uses the Twilio Conversations API to send a message to a specific Conversation. Twilio will then broadcast the message to the Conversation participants. In our case, this is two SMS-capable phone numbers.
-func (t *smsService) sendSMSInConversation(body string, convoID string) error {
+// The call blocks until the sending number's rate limit allows another message.
+func (t *smsService) sendSMSInConversation(ctx context.Context, body string, convoID string) error {
+	if err := t.limiter.Wait(ctx, t.fromPhoneNum); err != nil {
+		return fmt.Errorf("limiter.Wait: %w", err)
+	}
+
 	params := &conversations.CreateServiceConversationMessageParams{
 		Body:   &body,
 		Author: &t.conversationsIdentity,
@@ -182,7 +306,7 @@ func (t *smsService) sendSMSInConversation(body string, convoID string) error {
 
 	_, err := t.client.ConversationsV1.CreateServiceConversationMessage(t.conversationsSid, convoID, params)
 	if err != nil {
-		return fmt.Errorf("createServiceConversationMessage: %w", err)
+		return fmt.Errorf("createServiceConversationMessage: %w", twilioError(err))
 	}
 
 	return nil
@@ -196,7 +320,7 @@ func (t *smsService) findConversationsByNumber(phNum string) ([]conversations.Co
 
 	resp, err := t.client.ConversationsV1.ListServiceParticipantConversation(t.conversationsSid, params)
 	if err != nil {
-		return resp, fmt.Errorf("listServiceParticipantConversation: %w", err)
+		return resp, fmt.Errorf("listServiceParticipantConversation: %w", twilioError(err))
 	}
 	return resp, nil
 }
@@ -209,7 +333,7 @@ func (t *smsService) addNumberToConversation(phNum, friendlyName string) (string
 	// Create new Conversation
 	cResp, err := t.client.ConversationsV1.CreateServiceConversation(t.conversationsSid, cp)
 	if err != nil {
-		return "", fmt.Errorf("createServiceConversation: %w", err)
+		return "", fmt.Errorf("createServiceConversation: %w", twilioError(err))
 	}
 
 	// Add Operation Spark Conversation Identity
@@ -217,7 +341,7 @@ func (t *smsService) addNumberToConversation(phNum, friendlyName string) (string
 	ppp.SetIdentity(t.conversationsIdentity)
 	_, err = t.client.ConversationsV1.CreateServiceConversationParticipant(t.conversationsSid, *cResp.Sid, ppp)
 	if err != nil {
-		return "", fmt.Errorf("createServiceConversationParticipant with Service Identity: %w: ", err)
+		return "", fmt.Errorf("createServiceConversationParticipant with Service Identity: %w: ", twilioError(err))
 	}
 
 	// Add SMS Recipient to conversation
@@ -229,7 +353,7 @@ func (t *smsService) addNumberToConversation(phNum, friendlyName string) (string
 
 	_, err = t.client.ConversationsV1.CreateServiceConversationParticipant(t.conversationsSid, *cResp.Sid, pp)
 	if err != nil {
-		return "", fmt.Errorf("createServiceConversationParticipant: %w\nidentity: %q", err, phNum)
+		return "", fmt.Errorf("createServiceConversationParticipant: %w\nidentity: %q", twilioError(err), phNum)
 	}
 
 	return *cResp.Sid, nil
@@ -248,25 +372,37 @@ func (t *smsService) sendConvoWebhook(ctx context.Context, convoID string) error
 	if err != nil {
 		return fmt.Errorf("newRequest: %w", err)
 	}
-	req.Header.Add("key", os.Getenv("URL_SHORTENER_API_KEY"))
+	req.Header.Add("key", t.opSparkMessagingAPIKey)
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("do: %w", err)
 	}
 
 	if resp.StatusCode >= 300 {
-		return handleHTTPError(resp)
+		return newHTTPError("messenger", resp)
 	}
 	return nil
 }
 
-func (t *smsService) optInConfirmation(ctx context.Context, toNum string) error {
-	msg := "You've opted in for texts from Operation Spark for upcoming sessions. You can text us here if you have further questions. Message and data rates may apply. Reply STOP to unsubscribe."
+func (t *smsService) optInConfirmation(ctx context.Context, toNum string, lang i18n.Language) error {
+	msg, err := t.templates.Render(templates.OptInConfirmation, lang, time.UTC, nil)
+	if err != nil {
+		return fmt.Errorf("render: %w", err)
+	}
 	return t.Send(ctx, toNum, msg)
 }
 
 // Send sends an SMS message to the given toNum and returns an error.
+// Unless the context is marked urgent (sms.WithUrgent), messages sent during quiet hours (Central Time) are queued and sent by SendQueued.
 func (t *smsService) Send(ctx context.Context, toNum string, msg string) error {
+	deferred, err := t.deferIfQuiet(ctx, toNum, msg)
+	if err != nil {
+		return fmt.Errorf("deferIfQuiet: %w", err)
+	}
+	if deferred {
+		return nil
+	}
+
 	// TODO: Maybe consolidate this code with some of the run() code
 	convoID := ""
 	existing, err := t.findConversationsByNumber(toNum)
@@ -288,9 +424,82 @@ func (t *smsService) Send(ctx context.Context, toNum string, msg string) error {
 		// There can only be one..
 		convoID = *existing[0].ConversationSid
 	}
-	err = t.sendSMSInConversation(msg, convoID)
+	err = t.sendSMSInConversation(ctx, msg, convoID)
 	if err != nil {
 		return fmt.Errorf("sendSMS: %w", err)
 	}
 	return nil
 }
+
+// SendQueued sends every queued message that is due and returns the number of messages sent.
+// Each message is removed from the queue before it is sent, so a message is never sent twice. A message that fails to send is not retried. Failures don't stop the other messages; they are returned together.
+func (t *smsService) SendQueued(ctx context.Context) (int, error) {
+	if t.queue == nil {
+		return 0, nil
+	}
+
+	msgs, err := t.queue.DueSMS(ctx, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("dueSMS: %w", err)
+	}
+
+	// Quiet hours are over for these messages.
+	ctx = sms.WithUrgent(ctx)
+	sent := 0
+	var errs []error
+	for _, m := range msgs {
+		if err := t.queue.DeleteSMS(ctx, m.ID); err != nil {
+			// Skip it. The next run will try again.
+			errs = append(errs, fmt.Errorf("deleteSMS %q: %w", m.ID, err))
+			continue
+		}
+		if err := t.Send(ctx, m.To, m.Body); err != nil {
+			errs = append(errs, fmt.Errorf("send %q: %w", m.ID, err))
+			continue
+		}
+		sent++
+	}
+	return sent, errors.Join(errs...)
+}
+
+// DeferIfQuiet queues the message if it is within quiet hours and the context is not marked urgent. It returns true if the message was queued.
+// Quiet hours are checked in sms.DefaultTZName (Central Time) because signups don't record the person's time zone.
+func (t *smsService) deferIfQuiet(ctx context.Context, toNum, msg string) (bool, error) {
+	if t.queue == nil || sms.IsUrgent(ctx) {
+		return false, nil
+	}
+
+	tz, err := sms.RecipientTZ(ctx)
+	if err != nil {
+		return false, fmt.Errorf("recipientTZ: %w", err)
+	}
+
+	now := time.Now().In(tz)
+	if !t.quietHours.IsQuiet(now) {
+		return false, nil
+	}
+
+	err = t.queue.EnqueueSMS(ctx, sms.Message{
+		To:        toNum,
+		Body:      msg,
+		SendAfter: t.quietHours.NextSendTime(now),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return false, fmt.Errorf("enqueueSMS: %w", err)
+	}
+	return true, nil
+}
+
+// StartsBeforeQuietHoursEnd checks if a session starts before the current quiet hours window, in sms.DefaultTZName, ends.
+func (t *smsService) startsBeforeQuietHoursEnd(ctx context.Context, start time.Time) (bool, error) {
+	if start.IsZero() {
+		return false, nil
+	}
+
+	tz, err := sms.RecipientTZ(ctx)
+	if err != nil {
+		return false, fmt.Errorf("recipientTZ: %w", err)
+	}
+	return t.quietHours.EndsAfter(start, time.Now().In(tz)), nil
+}
@@ -9,12 +9,45 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/operationspark/service-signup/fakes"
+	"github.com/operationspark/service-signup/sms"
 	"github.com/stretchr/testify/require"
 )
 
+type mockSMSQueue struct {
+	queued []sms.Message
+}
+
+func (m *mockSMSQueue) EnqueueSMS(ctx context.Context, msg sms.Message) error {
+	msg.ID = fmt.Sprint(len(m.queued))
+	m.queued = append(m.queued, msg)
+	return nil
+}
+
+func (m *mockSMSQueue) DueSMS(ctx context.Context, now time.Time) ([]sms.Message, error) {
+	var due []sms.Message
+	for _, msg := range m.queued {
+		if !msg.SendAfter.After(now) {
+			due = append(due, msg)
+		}
+	}
+	return due, nil
+}
+
+func (m *mockSMSQueue) DeleteSMS(ctx context.Context, id string) error {
+	for i, msg := range m.queued {
+		if msg.ID == id {
+			m.queued = append(m.queued[:i], m.queued[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("sms %q is not queued", id)
+}
+
 // Magic Test Numbers
 // https://www.twilio.com/docs/iam/test-credentials#magic-input
 
@@ -71,7 +104,7 @@ func TestSendSMSInConversation(t *testing.T) {
 
 		messageBody := "Welcome to Op Spark! Click this link for more info: https://opsk.org/bh213v34fa"
 
-		err := tSvc.sendSMSInConversation(messageBody, conversationSid)
+		err := tSvc.sendSMSInConversation(context.Background(), messageBody, conversationSid)
 		require.NoErrorf(t, err, "twilio service: sendSMS: %v")
 	})
 
@@ -164,3 +197,125 @@ func TestInvalidNumErr(t *testing.T) {
 		require.Equal(t, want, res.Body.String())
 	})
 }
+
+func TestQuietHoursDeferral(t *testing.T) {
+	// Quiet hours from the start of the current UTC hour, lasting at least another hour
+	nowHour := time.Now().UTC().Hour()
+	quietNow := sms.QuietHours{Start: nowHour, End: (nowHour + 2) % 24}
+	ctx := sms.WithRecipientTZ(context.Background(), time.UTC)
+
+	t.Run("queues non-urgent messages during quiet hours", func(t *testing.T) {
+		queue := &mockSMSQueue{}
+		tSvc := NewTwilioService(twilioServiceOptions{
+			quietHours: quietNow,
+			queue:      queue,
+		})
+
+		// The Twilio client has no credentials, so this would fail if it tried to send.
+		err := tSvc.Send(ctx, "+15005550006", "A friendly reminder")
+		require.NoError(t, err)
+
+		require.Len(t, queue.queued, 1)
+		require.Equal(t, "+15005550006", queue.queued[0].To)
+		require.Equal(t, "A friendly reminder", queue.queued[0].Body)
+		require.True(t, queue.queued[0].SendAfter.After(time.Now()), "should be sent after quiet hours end")
+	})
+
+	t.Run("does not queue urgent messages", func(t *testing.T) {
+		queue := &mockSMSQueue{}
+		tSvc := NewTwilioService(twilioServiceOptions{
+			quietHours: quietNow,
+			queue:      queue,
+		})
+
+		deferred, err := tSvc.deferIfQuiet(sms.WithUrgent(ctx), "+15005550006", "Your session starts soon")
+		require.NoError(t, err)
+		require.False(t, deferred)
+		require.Empty(t, queue.queued)
+	})
+
+	t.Run("sessions starting before quiet hours end are urgent", func(t *testing.T) {
+		tSvc := NewTwilioService(twilioServiceOptions{quietHours: quietNow})
+
+		urgent, err := tSvc.startsBeforeQuietHoursEnd(ctx, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		require.True(t, urgent)
+
+		urgent, err = tSvc.startsBeforeQuietHoursEnd(ctx, time.Now().Add(time.Hour*48))
+		require.NoError(t, err)
+		require.False(t, urgent)
+	})
+}
+
+func TestSendQueued(t *testing.T) {
+	t.Run("sends the other messages when one fails, and never resends", func(t *testing.T) {
+		fakeAPIs := fakes.New(nil)
+		// Twilio is down for one of the numbers.
+		mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.Contains(r.URL.RawQuery, "5045550000") {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			fakeAPIs.ServeHTTP(w, r)
+		}))
+		defer mockServer.Close()
+
+		queue := &mockSMSQueue{}
+		tSvc := NewTwilioService(twilioServiceOptions{
+			accountSID:       "ACtest",
+			authToken:        "testAuthToken",
+			fromPhoneNum:     "+15005550006",
+			conversationsSid: "IStest",
+			apiBase:          mockServer.URL + "/twilio",
+			queue:            queue,
+		})
+		for _, to := range []string{"+15045550000", "+15045551234"} {
+			require.NoError(t, queue.EnqueueSMS(context.Background(), sms.Message{To: to, Body: "Good morning!"}))
+		}
+
+		sent, err := tSvc.SendQueued(context.Background())
+		require.ErrorContains(t, err, `send "0"`)
+		require.Equal(t, 1, sent)
+		require.Empty(t, queue.queued)
+
+		sent, err = tSvc.SendQueued(context.Background())
+		require.NoError(t, err)
+		require.Zero(t, sent)
+	})
+}
+
+func TestTwilioAPIBase(t *testing.T) {
+	t.Run("sends Conversations API requests to the API base", func(t *testing.T) {
+		fakeAPIs := fakes.New(nil)
+		mockServer := httptest.NewServer(fakeAPIs)
+		defer mockServer.Close()
+
+		tSvc := NewTwilioService(twilioServiceOptions{
+			accountSID:       "ACtest",
+			authToken:        "testAuthToken",
+			fromPhoneNum:     "+15005550006",
+			conversationsSid: "IStest",
+			apiBase:          mockServer.URL + "/twilio",
+		})
+
+		err := tSvc.Send(context.Background(), "+15045551234", "Hi Henri!")
+		require.NoError(t, err)
+		// The second message reuses the conversation created by the first.
+		err = tSvc.Send(context.Background(), "+15045551234", "See you soon!")
+		require.NoError(t, err)
+
+		var sent []string
+		for _, req := range fakeAPIs.Requests() {
+			require.Equal(t, fakes.ServiceTwilio, req.Service, req.Path)
+			if strings.HasSuffix(req.Path, "/Messages") {
+				sent = append(sent, req.Summary)
+			}
+		}
+		require.Equal(t, []string{
+			"SMS to +15045551234: Hi Henri!",
+			"SMS to +15045551234: See you soon!",
+		}, sent)
+
+		require.NoError(t, tSvc.checkAccount(context.Background()))
+	})
+}
@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/operationspark/service-signup/zoom/meeting"
@@ -132,7 +134,7 @@ func (z *zoomService) registerUser(ctx context.Context, su *Signup) error {
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode >= 300 {
-		return handleHTTPError(resp)
+		return newHTTPError("zoom", resp)
 	}
 
 	var respBody meeting.RegistrationResponse
@@ -145,6 +147,87 @@ func (z *zoomService) registerUser(ctx context.Context, su *Signup) error {
 	return nil
 }
 
+// MeetingAttendees returns the lowercased emails of the people who joined the instance of the recurring meeting that started within 3 hours of the session start. It returns nil if the meeting has no such instance (Ex: the session hasn't happened yet).
+func (z *zoomService) meetingAttendees(ctx context.Context, meetingID int64, sessionStart time.Time) (map[string]bool, error) {
+	token, err := z.authenticate(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("authenticate: %w", err)
+	}
+
+	var instances meeting.PastInstancesResponse
+	err = z.getJSON(ctx, token, fmt.Sprintf("%s/past_meetings/%d/instances", z.baseURL, meetingID), &instances)
+	if err != nil {
+		return nil, fmt.Errorf("getJSON instances: %w", err)
+	}
+
+	var instanceUUID string
+	for _, inst := range instances.Meetings {
+		diff := inst.StartTime.Sub(sessionStart)
+		if diff > -3*time.Hour && diff < 3*time.Hour {
+			instanceUUID = inst.UUID
+			break
+		}
+	}
+	if instanceUUID == "" {
+		return nil, nil
+	}
+
+	attendees := map[string]bool{}
+	pageToken := ""
+	for {
+		params := url.Values{"page_size": {"300"}}
+		if pageToken != "" {
+			params.Set("next_page_token", pageToken)
+		}
+		var page meeting.PastParticipantsResponse
+		u := fmt.Sprintf("%s/past_meetings/%s/participants?%s", z.baseURL, escapeMeetingUUID(instanceUUID), params.Encode())
+		if err := z.getJSON(ctx, token, u, &page); err != nil {
+			return nil, fmt.Errorf("getJSON participants: %w", err)
+		}
+		for _, p := range page.Participants {
+			if p.UserEmail != "" {
+				attendees[strings.ToLower(p.UserEmail)] = true
+			}
+		}
+		if page.NextPageToken == "" {
+			return attendees, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// EscapeMeetingUUID path-escapes a meeting instance UUID. Zoom requires UUIDs that begin with "/" or contain "//" to be escaped twice.
+func escapeMeetingUUID(uuid string) string {
+	escaped := url.PathEscape(uuid)
+	if strings.HasPrefix(uuid, "/") || strings.Contains(uuid, "//") {
+		return url.PathEscape(escaped)
+	}
+	return escaped
+}
+
+// GetJSON sends an authenticated GET request to the Zoom API and decodes the JSON response into dst.
+func (z *zoomService) getJSON(ctx context.Context, token tokenResponse, url string, dst any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("newRequestWithContext: %w", err)
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token.AccessToken))
+
+	resp, err := z.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("client.Do: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 300 {
+		return newHTTPError("zoom", resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(dst); err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+	return nil
+}
+
 // Authenticate requests an access token.
 func (z *zoomService) authenticate(ctx context.Context) (tokenResponse, error) {
 	url := fmt.Sprintf("%s/token?grant_type=account_credentials&account_id=%s", z.oauthURL, z.accountID)
@@ -167,7 +250,7 @@ func (z *zoomService) authenticate(ctx context.Context) (tokenResponse, error) {
 	}
 	defer func() { _ = resp.Body.Close() }()
 	if resp.StatusCode >= 300 {
-		return tokenResponse{}, handleHTTPError(resp)
+		return tokenResponse{}, newHTTPError("zoom", resp)
 	}
 
 	var body tokenResponse
@@ -186,6 +269,14 @@ func (z *zoomService) authenticate(ctx context.Context) (tokenResponse, error) {
 	return creds, nil
 }
 
+// CheckHealth returns an error if the service cannot get an OAuth access token.
+func (z *zoomService) checkHealth(ctx context.Context) error {
+	if _, err := z.authenticate(ctx); err != nil {
+		return fmt.Errorf("authenticate: %w", err)
+	}
+	return nil
+}
+
 func (z *zoomService) isAuthenticated(creds tokenResponse) bool {
 	// Shave 5 min off expiration date as a buffer to request a new token
 	expirationDate := creds.ExpiresAt.Add(time.Minute * -5)
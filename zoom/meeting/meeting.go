@@ -1,6 +1,8 @@
 // Package meeting provides a service for interacting with Zoom meetings.
 package meeting
 
+import "time"
+
 type (
 	// RegistrantRequest represents a request to create a registrant for a meeting on the Zoom API.
 	// See: https://marketplace.zoom.us/docs/api-reference/zoom-api/methods/#operation/meetingRegistrantCreate
@@ -32,4 +34,29 @@ type (
 		Topic        string       `json:"topic"`
 		Occurrences  []Occurrence `json:"occurrences"`
 	}
+
+	// PastInstancesResponse lists the ended instances of a recurring meeting.
+	// See: https://developers.zoom.us/docs/api/meetings/#tag/meetings/GET/past_meetings/{meetingId}/instances
+	PastInstancesResponse struct {
+		Meetings []PastInstance `json:"meetings"`
+	}
+
+	PastInstance struct {
+		// Meeting instance UUID. Used to look up the instance's participants.
+		UUID      string    `json:"uuid"`
+		StartTime time.Time `json:"start_time"`
+	}
+
+	// PastParticipantsResponse is a page of the people who joined an ended meeting instance.
+	// See: https://developers.zoom.us/docs/api/meetings/#tag/meetings/GET/past_meetings/{meetingId}/participants
+	PastParticipantsResponse struct {
+		NextPageToken string            `json:"next_page_token"`
+		Participants  []PastParticipant `json:"participants"`
+	}
+
+	PastParticipant struct {
+		ID        string `json:"id"`
+		Name      string `json:"name"`
+		UserEmail string `json:"user_email"`
+	}
 )
@@ -209,6 +209,56 @@ func TestRegisterForMeeting(t *testing.T) {
 	require.Equal(t, mockJoinURL, su.ZoomMeetingURL())
 }
 
+func TestMeetingAttendees(t *testing.T) {
+	sessionStart := mustMakeTime(t, time.RFC822, "16 Nov 22 18:00 UTC")
+	// Starts with "/" so must be escaped twice in the participants URL.
+	instanceUUID := "/abc+def=="
+
+	mockZoomServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/token") {
+			require.NoError(t, json.NewEncoder(w).Encode(tokenResponse{AccessToken: "fake_access_token", ExpiresIn: 3600}))
+			return
+		}
+		require.Equal(t, "Bearer fake_access_token", r.Header.Get("Authorization"))
+
+		switch r.URL.EscapedPath() {
+		case "/past_meetings/89012345678/instances":
+			require.NoError(t, json.NewEncoder(w).Encode(meeting.PastInstancesResponse{Meetings: []meeting.PastInstance{
+				{UUID: "last-week", StartTime: sessionStart.AddDate(0, 0, -7)},
+				{UUID: instanceUUID, StartTime: sessionStart.Add(-5 * time.Minute)},
+			}}))
+		case "/past_meetings/%252Fabc+def==/participants":
+			page := meeting.PastParticipantsResponse{
+				NextPageToken: "page-2",
+				Participants:  []meeting.PastParticipant{{UserEmail: "Halle@Email.com"}, {Name: "Phone caller"}},
+			}
+			if r.URL.Query().Get("next_page_token") == "page-2" {
+				page = meeting.PastParticipantsResponse{Participants: []meeting.PastParticipant{{UserEmail: "henri@email.com"}}}
+			}
+			require.NoError(t, json.NewEncoder(w).Encode(page))
+		default:
+			http.Error(w, fmt.Sprintf("invalid URL:\n%q", r.URL.EscapedPath()), http.StatusNotFound)
+		}
+	}))
+
+	zsvc := NewZoomService(ZoomOptions{
+		baseAPIOverride:   mockZoomServer.URL,
+		baseOAuthOverride: mockZoomServer.URL,
+	})
+
+	t.Run("returns the emails of the session's meeting participants", func(t *testing.T) {
+		got, err := zsvc.meetingAttendees(context.Background(), 89012345678, sessionStart)
+		require.NoError(t, err)
+		require.Equal(t, map[string]bool{"halle@email.com": true, "henri@email.com": true}, got)
+	})
+
+	t.Run("returns nil when the session's meeting instance hasn't happened", func(t *testing.T) {
+		got, err := zsvc.meetingAttendees(context.Background(), 89012345678, sessionStart.AddDate(0, 0, 7))
+		require.NoError(t, err)
+		require.Nil(t, got)
+	})
+}
+
 func TestAuthRefresh(t *testing.T) {
 	t.Skip("This test is for the auto refresh token implementation. Currently we're just fetching a new token on every Zoom request.")
 